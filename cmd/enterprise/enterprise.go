@@ -12,4 +12,5 @@ var EnterpriseCmd = &cobra.Command{
 
 func init() {
 	EnterpriseCmd.AddCommand(ListCmd)
+	EnterpriseCmd.AddCommand(ListInstallationsCmd)
 }
@@ -0,0 +1,64 @@
+package enterprise
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var ListInstallationsCmd = &cobra.Command{
+	Use:   "list-installations",
+	Short: "List the GitHub App's installations",
+	Long: `List every installation of the GitHub App with its ID, account login and target type.
+Use it to diagnose "no suitable installation found" errors: tokens are only issued for installations shown here.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		ctx := cmd.Context()
+		if ctx.Value(config.TokenKey) != nil {
+			return fmt.Errorf("list-installations requires GitHub App authentication (--app-id and --private-key)")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok {
+			logger = slog.Default()
+		}
+
+		installations, err := api.ListAppInstallations(ctx, logger)
+		if err != nil {
+			return err
+		}
+
+		if len(installations) == 0 {
+			fmt.Println("The GitHub App has no installations")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tACCOUNT\tTARGET TYPE")
+		for _, installation := range installations {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", installation.ID, installation.Account.Login, installation.TargetType)
+		}
+		return w.Flush()
+	},
+}
@@ -6,35 +6,167 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/s-samadi/ghas-lab-builder/cmd/enterprise"
 	"github.com/s-samadi/ghas-lab-builder/cmd/lab"
 	"github.com/s-samadi/ghas-lab-builder/cmd/orgs"
 	"github.com/s-samadi/ghas-lab-builder/cmd/repo"
+	"github.com/s-samadi/ghas-lab-builder/cmd/reports"
+	"github.com/s-samadi/ghas-lab-builder/cmd/sample"
+	"github.com/s-samadi/ghas-lab-builder/internal/auth"
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/services"
 	"github.com/s-samadi/ghas-lab-builder/internal/util"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	appId      string
-	privateKey string
-	token      string
-	baseURL    string
+	appId            string
+	privateKey       string
+	privateKeyFile   string
+	privateKeyFormat string
+	token            string
+	baseURL          string
+
+	minConcurrency        int
+	maxConcurrency        int
+	validationConcurrency int
+	maxConns              int
+	httpTrace             bool
+	printQuery            bool
+
+	maxBodyLogBytes int64
+
+	noEnterpriseCache bool
+	orgCreateTimeout  time.Duration
+	orgDeleteTimeout  time.Duration
+	orgCreateInterval time.Duration
+
+	logFormat string
+	colorMode string
+
+	// runLogger is kept so the API call summary can be logged after a failed command too
+	runLogger *slog.Logger
+
+	noTimestamp          bool
+	strictReports        bool
+	reportInvalidDetails bool
+	commentOn            string
+	actionsMatrixOutput  bool
+
+	reportSinks           []string
+	reportUpload          string
+	reportWebhookURL      string
+	reportSlackWebhookURL string
+
+	exportMetricsJSON string
+	runID             string
+	runIDHeader       string
+	orgAllowlist      []string
+	// runStartedAt and runCommand describe the run in the --export-metrics-json file
+	runStartedAt time.Time
+	runCommand   string
 )
 
+// flagEnvFallbacks maps flags to the environment variables used when the flag isn't set.
+// --private-key-file is listed after --private-key so the inline key wins if both are set.
+var flagEnvFallbacks = []struct {
+	flag string
+	env  string
+}{
+	{"token", config.EnvToken},
+	{"app-id", config.EnvAppID},
+	{"private-key", config.EnvPrivateKey},
+	{"private-key-file", config.EnvPrivateKeyFile},
+	{"base-url", config.EnvBaseURL},
+	{"enterprise-slug", config.EnvEnterpriseSlug},
+	{"report-webhook-url", config.EnvReportWebhook},
+	{"report-slack-webhook-url", config.EnvSlackWebhook},
+	{"org-allowlist", config.EnvOrgAllowlist},
+}
+
+// applyEnvFallbacks fills unset flags from their environment variables. Flags passed on
+// the command line always take precedence. Only flags defined for cmd are considered.
+func applyEnvFallbacks(flags *pflag.FlagSet) error {
+	for _, fallback := range flagEnvFallbacks {
+		f := flags.Lookup(fallback.flag)
+		if f == nil || f.Changed {
+			continue
+		}
+		// An inline key from the environment takes precedence over a key file
+		if fallback.flag == "private-key-file" && privateKey != "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(fallback.env); ok && value != "" {
+			if err := f.Value.Set(value); err != nil {
+				return fmt.Errorf("invalid value in %s for --%s: %w", fallback.env, fallback.flag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// flagValue returns the value of a flag defined by a subcommand, or "" when cmd doesn't
+// define it
+func flagValue(cmd *cobra.Command, name string) string {
+	if f := cmd.Flags().Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "ghas-lab-builder",
 	Short: "Builds GitHub Advanced Security Lab environments(orgs, repos, users)",
 	Long: `ghas-lab-builder is a CLI tool that helps you set up GitHub Advanced Security Lab environments by 
           automating the creation of organizations, repositories, and addings  users required for hands-on labs.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Resolve environment variable fallbacks before validating
+		if err := applyEnvFallbacks(cmd.Flags()); err != nil {
+			return err
+		}
+
+		// keySourceFile is the file the key was read from, so it can be reloaded if rotated
+		keySourceFile := ""
+		if privateKeyFile != "" {
+			if cmd.Flags().Changed("private-key") && cmd.Flags().Changed("private-key-file") {
+				return fmt.Errorf("--private-key and --private-key-file are mutually exclusive")
+			}
+			if privateKey == "" {
+				keyData, err := os.ReadFile(privateKeyFile)
+				if err != nil {
+					return fmt.Errorf("failed to read private key file: %w", err)
+				}
+				privateKey = strings.TrimSpace(string(keyData))
+				keySourceFile = privateKeyFile
+			}
+		}
+
+		// --enterprise-id with --billing-email stands in for looking the enterprise up by slug
+		enterpriseID, billingEmail := flagValue(cmd, "enterprise-id"), flagValue(cmd, "billing-email")
+		if enterpriseID != "" && billingEmail == "" {
+			return fmt.Errorf("--enterprise-id requires --billing-email")
+		}
+		if billingEmail != "" && enterpriseID == "" {
+			return fmt.Errorf("--billing-email is only used with --enterprise-id")
+		}
+
+		// enterprise-slug can come from the environment, so cobra can't enforce it as required
+		if f := cmd.Flags().Lookup("enterprise-slug"); f != nil && f.Value.String() == "" && enterpriseID == "" {
+			return fmt.Errorf("required flag(s) \"enterprise-slug\" not set (or set %s, or pass --enterprise-id with --billing-email)", config.EnvEnterpriseSlug)
+		}
+
 		// Validate that either token OR (app-id + private-key) is provided, but not both
 		hasToken := token != ""
 		hasAppCreds := appId != "" || privateKey != ""
 
 		if !hasToken && !hasAppCreds {
-			return fmt.Errorf("authentication required: provide either --token OR both --app-id and --private-key")
+			return fmt.Errorf("authentication required: provide either --token OR both --app-id and --private-key (or set %s, or %s and %s)", config.EnvToken, config.EnvAppID, config.EnvPrivateKey)
 		}
 
 		if hasToken && hasAppCreds {
@@ -51,18 +183,98 @@ var rootCmd = &cobra.Command{
 			}
 		}
 
+		switch privateKeyFormat {
+		case auth.KeyFormatAuto, auth.KeyFormatPKCS1, auth.KeyFormatPKCS8:
+		default:
+			return fmt.Errorf("invalid --private-key-format %q: must be one of %s, %s, %s", privateKeyFormat, auth.KeyFormatAuto, auth.KeyFormatPKCS1, auth.KeyFormatPKCS8)
+		}
+
+		if minConcurrency < 1 {
+			return fmt.Errorf("--min-concurrency must be at least 1")
+		}
+		if maxConns < 0 {
+			return fmt.Errorf("--max-conns cannot be negative")
+		}
+		if validationConcurrency < 1 {
+			return fmt.Errorf("--validation-concurrency must be at least 1")
+		}
+		if orgCreateTimeout <= 0 || orgDeleteTimeout <= 0 {
+			return fmt.Errorf("--org-create-timeout and --org-delete-timeout must be positive")
+		}
+		if maxBodyLogBytes < 0 {
+			return fmt.Errorf("--max-body-log-bytes cannot be negative")
+		}
+		if orgCreateInterval < 0 {
+			return fmt.Errorf("--org-create-interval cannot be negative")
+		}
+		switch logFormat {
+		case util.LogFormatJSON, util.LogFormatText:
+		default:
+			return fmt.Errorf("invalid --log-format %q: must be %s or %s", logFormat, util.LogFormatJSON, util.LogFormatText)
+		}
+		switch colorMode {
+		case util.ColorAuto, util.ColorAlways, util.ColorNever:
+		default:
+			return fmt.Errorf("invalid --color %q: must be one of %s, %s, %s", colorMode, util.ColorAuto, util.ColorAlways, util.ColorNever)
+		}
+		if err := util.ValidateOrgAllowlist(orgAllowlist); err != nil {
+			return fmt.Errorf("invalid --org-allowlist: %w", err)
+		}
+		if runID == "" {
+			runID = util.NewRunID()
+		} else if err := util.ValidateRunID(runID); err != nil {
+			return fmt.Errorf("invalid --run-id: %w", err)
+		}
+		if err := util.ValidateHeaderName(runIDHeader); err != nil {
+			return fmt.Errorf("invalid --run-id-header: %w", err)
+		}
+		if maxConcurrency < minConcurrency {
+			return fmt.Errorf("--max-concurrency (%d) must be greater than or equal to --min-concurrency (%d)", maxConcurrency, minConcurrency)
+		}
+
+		var commentTarget util.IssueRef
+		if commentOn != "" {
+			ref, err := util.ParseIssueRef(commentOn)
+			if err != nil {
+				return fmt.Errorf("invalid --comment-on: %w", err)
+			}
+			commentTarget = ref
+		}
+
+		sinks, err := resolveReportSinks(reportSinks)
+		if err != nil {
+			return err
+		}
+
+		var uploadTarget util.UploadTarget
+		if reportUpload != "" {
+			uploadTarget, err = util.ParseUploadTarget(reportUpload)
+			if err != nil {
+				return fmt.Errorf("invalid --report-upload: %w", err)
+			}
+			if !slices.Contains(sinks, config.ReportSinkFile) {
+				return fmt.Errorf("--report-upload uploads the report files, so --report-sink must include file")
+			}
+		}
+
 		// Set default base URL if not provided
 		if baseURL == "" {
 			baseURL = config.DefaultBaseURL
 		}
+		baseURL, err = util.NormalizeBaseURL(baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid --base-url: %w", err)
+		}
 
 		// Generate log file path automatically
 		logFilePath := util.GenerateLogFileName("ghas-lab-builder")
 
 		// Initialize logger with automatic log file
 		loggerConfig := util.LoggerConfig{
-			LogFilePath: logFilePath,
-			LogLevel:    slog.LevelInfo,
+			LogFilePath:   logFilePath,
+			LogLevel:      slog.LevelInfo,
+			ConsoleFormat: logFormat,
+			Color:         colorMode,
 		}
 		logger, closer, err := util.NewLogger(loggerConfig)
 		if err != nil {
@@ -85,16 +297,56 @@ var rootCmd = &cobra.Command{
 			// Using GitHub App authentication
 			ctx = context.WithValue(ctx, config.AppIDKey, appId)
 			ctx = context.WithValue(ctx, config.PrivateKeyKey, privateKey)
+			ctx = context.WithValue(ctx, config.PrivateKeyFormatKey, privateKeyFormat)
+			ctx = context.WithValue(ctx, config.PrivateKeyFileKey, keySourceFile)
 		}
 
 		ctx = context.WithValue(ctx, config.BaseURLKey, baseURL)
+		ctx = context.WithValue(ctx, config.MinConcurrencyKey, minConcurrency)
+		ctx = context.WithValue(ctx, config.MaxConcurrencyKey, maxConcurrency)
+		ctx = context.WithValue(ctx, config.ValidationConcurrencyKey, validationConcurrency)
+		ctx = context.WithValue(ctx, config.MaxConnsKey, maxConns)
+		ctx = context.WithValue(ctx, config.HTTPTraceKey, httpTrace)
+		ctx = context.WithValue(ctx, config.PrintQueryKey, printQuery)
+		ctx = context.WithValue(ctx, config.MaxBodyLogBytesKey, maxBodyLogBytes)
+		ctx = context.WithValue(ctx, config.NoEnterpriseCacheKey, noEnterpriseCache)
+		ctx = context.WithValue(ctx, config.EnterpriseIDKey, enterpriseID)
+		ctx = context.WithValue(ctx, config.BillingEmailKey, billingEmail)
+		ctx = context.WithValue(ctx, config.OrgAllowlistKey, orgAllowlist)
+		ctx = context.WithValue(ctx, config.RunIDKey, runID)
+		ctx = context.WithValue(ctx, config.RunIDHeaderKey, runIDHeader)
+		ctx = context.WithValue(ctx, config.OrgCreateTimeoutKey, orgCreateTimeout)
+		ctx = context.WithValue(ctx, config.OrgDeleteTimeoutKey, orgDeleteTimeout)
+		ctx = context.WithValue(ctx, config.OrgCreateIntervalKey, orgCreateInterval)
+		ctx = context.WithValue(ctx, config.ReportNoTimestampKey, noTimestamp)
+		ctx = context.WithValue(ctx, config.StrictReportsKey, strictReports)
+		ctx = context.WithValue(ctx, config.ReportInvalidDetailsKey, reportInvalidDetails)
+		ctx = context.WithValue(ctx, config.ActionsMatrixOutputKey, actionsMatrixOutput)
+		if commentOn != "" {
+			ctx = context.WithValue(ctx, config.CommentOnKey, commentTarget)
+		}
+		ctx = context.WithValue(ctx, config.ReportSinksKey, sinks)
+		if reportUpload != "" {
+			ctx = context.WithValue(ctx, config.ReportUploadKey, uploadTarget)
+		}
+		ctx = context.WithValue(ctx, config.ReportWebhookURLKey, reportWebhookURL)
+		ctx = context.WithValue(ctx, config.ReportSlackWebhookURLKey, reportSlackWebhookURL)
 
-		logger.Info("Logging initialized", slog.String("log_file", logFilePath))
+		logger.Info("Logging initialized",
+			slog.String("log_file", logFilePath),
+			slog.String("request_run_id", runID),
+			slog.String("request_run_id_header", runIDHeader))
+		runLogger = logger
+		runStartedAt = time.Now()
+		runCommand = cmd.CommandPath()
 
 		cmd.SetContext(ctx)
 		return nil
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		exportRunMetrics(false)
+		logAPICallSummary()
+		api.ClearTokenCache()
 		if closer, ok := cmd.Context().Value("logCloser").(io.Closer); ok && closer != nil {
 			return closer.Close()
 		}
@@ -102,8 +354,75 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// resolveReportSinks validates the --report-sink values and checks each selected sink is
+// configured. --comment-on on its own keeps posting the report, as it did before sinks.
+func resolveReportSinks(names []string) ([]string, error) {
+	sinks := make([]string, 0, len(names)+1)
+	seen := make(map[string]bool, len(names)+1)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		switch name {
+		case config.ReportSinkFile, config.ReportSinkStdout:
+		case config.ReportSinkWebhook:
+			if reportWebhookURL == "" {
+				return nil, fmt.Errorf("--report-sink webhook requires --report-webhook-url")
+			}
+		case config.ReportSinkSlack:
+			if reportSlackWebhookURL == "" {
+				return nil, fmt.Errorf("--report-sink slack requires --report-slack-webhook-url")
+			}
+		case config.ReportSinkComment:
+			if commentOn == "" {
+				return nil, fmt.Errorf("--report-sink comment requires --comment-on")
+			}
+		default:
+			return nil, fmt.Errorf("unknown --report-sink %q: must be file, stdout, webhook, slack or comment", name)
+		}
+		seen[name] = true
+		sinks = append(sinks, name)
+	}
+
+	if commentOn != "" && !seen[config.ReportSinkComment] {
+		sinks = append(sinks, config.ReportSinkComment)
+	}
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("--report-sink must select at least one sink")
+	}
+	return sinks, nil
+}
+
+// logAPICallSummary logs the run's API usage once a command has finished
+func logAPICallSummary() {
+	if runLogger != nil {
+		api.LogAPICallSummary(runLogger)
+		api.LogTokenCacheSummary(runLogger)
+		runLogger = nil
+	}
+}
+
+// exportRunMetrics writes the --export-metrics-json file once a command has finished.
+// A failure to write it is only logged, as the metrics file is never the point of the run.
+func exportRunMetrics(failed bool) {
+	if exportMetricsJSON == "" || runLogger == nil {
+		return
+	}
+	if err := services.WriteRunMetricsFile(exportMetricsJSON, runCommand, runStartedAt, failed); err != nil {
+		runLogger.Warn("Failed to export run metrics", slog.String("file", exportMetricsJSON), slog.Any("error", err))
+		return
+	}
+	runLogger.Info("Exported run metrics", slog.String("file", exportMetricsJSON))
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		// PersistentPostRunE is skipped when a command fails, which is when the summary matters most
+		exportRunMetrics(true)
+		logAPICallSummary()
+		api.ClearTokenCache()
+		util.ActionsAnnotation(util.AnnotationError, "ghas-lab-builder failed", err.Error())
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -111,14 +430,46 @@ func Execute() {
 
 func init() {
 	// GitHub App authentication flags
-	rootCmd.PersistentFlags().StringVar(&appId, "app-id", "", "GitHub App ID (required if not using --token)")
-	rootCmd.PersistentFlags().StringVar(&privateKey, "private-key", "", "GitHub App private key PEM content (required if not using --token)")
+	rootCmd.PersistentFlags().StringVar(&appId, "app-id", "", "GitHub App ID (required if not using --token) [env: GHAS_LAB_APP_ID]")
+	rootCmd.PersistentFlags().StringVar(&privateKey, "private-key", "", "GitHub App private key PEM content (required if not using --token) [env: GHAS_LAB_PRIVATE_KEY]")
+	rootCmd.PersistentFlags().StringVar(&privateKeyFile, "private-key-file", "", "Path to the GitHub App private key PEM file, alternative to --private-key [env: GHAS_LAB_PRIVATE_KEY_FILE]")
+	rootCmd.PersistentFlags().StringVar(&privateKeyFormat, "private-key-format", auth.KeyFormatAuto, "Expected private key encoding: auto, pkcs1, or pkcs8")
 
 	// PAT authentication flag
-	rootCmd.PersistentFlags().StringVar(&token, "token", "", "GitHub Personal Access Token (required if not using GitHub App authentication)")
+	rootCmd.PersistentFlags().StringVar(&token, "token", "", "GitHub Personal Access Token (required if not using GitHub App authentication) [env: GHAS_LAB_TOKEN]")
 
 	// Common flags
-	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "GitHub API base URL")
+	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "GitHub API base URL [env: GHAS_LAB_BASE_URL]")
+	rootCmd.PersistentFlags().IntVar(&minConcurrency, "min-concurrency", config.DefaultMinConcurrency, "Minimum number of concurrent API requests when throttled by secondary rate limits")
+	rootCmd.PersistentFlags().IntVar(&maxConcurrency, "max-concurrency", config.DefaultMaxConcurrency, "Maximum number of concurrent API requests")
+	rootCmd.PersistentFlags().IntVar(&maxConns, "max-conns", 0, "Maximum TCP connections per host, including idle ones; 0 means unlimited")
+	rootCmd.PersistentFlags().IntVar(&validationConcurrency, "validation-concurrency", config.DefaultValidationConcurrency, "Number of users validated concurrently before provisioning")
+	rootCmd.PersistentFlags().BoolVar(&httpTrace, "http-trace", false, "Log connection-level timings (DNS, connect, TLS handshake, first byte) for every API request")
+	rootCmd.PersistentFlags().BoolVar(&printQuery, "print-query", false, "Log each GraphQL query and its variables (credentials redacted) before sending it")
+	rootCmd.PersistentFlags().Int64Var(&maxBodyLogBytes, "max-body-log-bytes", 0, "Log up to this many bytes of each request and response body, with credential fields redacted; 0 disables body logging")
+	rootCmd.PersistentFlags().BoolVar(&noEnterpriseCache, "no-enterprise-cache", false, "Always resolve the enterprise from the API instead of using the cached enterprise ID")
+	rootCmd.PersistentFlags().DurationVar(&orgCreateTimeout, "org-create-timeout", config.DefaultOrgCreateTimeout, "Timeout for each organization creation request (increase on slow GHES instances)")
+	rootCmd.PersistentFlags().DurationVar(&orgDeleteTimeout, "org-delete-timeout", config.DefaultOrgDeleteTimeout, "Timeout for each organization deletion request")
+	rootCmd.PersistentFlags().DurationVar(&orgCreateInterval, "org-create-interval", 0, "Minimum interval between organization creations across all workers (e.g. 2s); 0 disables pacing")
+	rootCmd.PersistentFlags().StringVar(&runID, "run-id", "", "ID sent in the --run-id-header header of every API request, to find the run in GHES audit logs (default: random per run)")
+	rootCmd.PersistentFlags().StringVar(&runIDHeader, "run-id-header", config.DefaultRunIDHeader, "Request header carrying --run-id")
+
+	// Logging flags
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", util.LogFormatJSON, "Console log format: json or text (the log file is always JSON)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", util.ColorAuto, "Color text console logs: auto, always or never (auto honors NO_COLOR)")
+
+	// Report flags
+	rootCmd.PersistentFlags().BoolVar(&noTimestamp, "no-timestamp", false, "Write report files with a stable name (e.g. lab-report-<lab-date>.md) instead of appending a timestamp")
+	rootCmd.PersistentFlags().BoolVar(&strictReports, "strict-reports", false, "Fail the run if report files cannot be written (by default report failures are only logged)")
+	rootCmd.PersistentFlags().BoolVar(&reportInvalidDetails, "report-include-invalid-details", false, "Show why each invalid user was skipped (not found, rate limited, illegal name) in reports")
+	rootCmd.PersistentFlags().BoolVar(&actionsMatrixOutput, "actions-matrix-output", false, "In GitHub Actions, write the successfully created organizations as a JSON matrix to the \"matrix\" step output")
+	rootCmd.PersistentFlags().StringSliceVar(&reportSinks, "report-sink", []string{config.ReportSinkFile}, "Where to deliver reports: file, stdout, webhook, slack, comment (repeatable or comma-separated)")
+	rootCmd.PersistentFlags().StringVar(&reportWebhookURL, "report-webhook-url", "", "URL the webhook report sink POSTs the report to as JSON [env: GHAS_LAB_REPORT_WEBHOOK_URL]")
+	rootCmd.PersistentFlags().StringVar(&reportSlackWebhookURL, "report-slack-webhook-url", "", "Slack incoming webhook URL used by the slack report sink [env: GHAS_LAB_SLACK_WEBHOOK_URL]")
+	rootCmd.PersistentFlags().StringVar(&reportUpload, "report-upload", "", "Upload report files to object storage after writing them: s3://bucket/prefix, gs://bucket/prefix or az://account/container/prefix (uses the aws, gcloud or az CLI)")
+	rootCmd.PersistentFlags().StringSliceVar(&orgAllowlist, "org-allowlist", nil, "Only delete organizations, and repositories in organizations, whose login matches one of these patterns (e.g. ghas-labs-*), regardless of subcommand (repeatable or comma-separated) [env: GHAS_LAB_ORG_ALLOWLIST]")
+	rootCmd.PersistentFlags().StringVar(&exportMetricsJSON, "export-metrics-json", "", "After the run, write run metrics (org and repo counts, durations, API requests per endpoint, retries, rate limit usage) to this JSON file")
+	rootCmd.PersistentFlags().StringVar(&commentOn, "comment-on", "", "Post the Markdown report as a comment on this issue or PR (owner/repo#number)")
 
 	if baseURL == "" {
 		baseURL = config.DefaultBaseURL
@@ -128,4 +479,6 @@ func init() {
 	rootCmd.AddCommand(repo.RepoCmd)
 	rootCmd.AddCommand(orgs.OrgsCmd)
 	rootCmd.AddCommand(enterprise.EnterpriseCmd)
+	rootCmd.AddCommand(sample.InitCmd)
+	rootCmd.AddCommand(reports.ReportsCmd)
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestOrgsDeleteWiring runs `orgs delete` through the root command against a fake GitHub
+// API, so a command calling an API function that doesn't exist or no longer matches fails
+// the build, and one that is wired to the wrong requests fails the test
+func TestOrgsDeleteWiring(t *testing.T) {
+	// The root pre-run writes its log file under the working directory
+	t.Chdir(t.TempDir())
+
+	const orgPath = "/orgs/ghas-labs-2025-11-07-student1"
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("%s %s: Authorization = %q, want the --token value", r.Method, r.URL.Path, got)
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET " + orgPath:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":1,"login":"ghas-labs-2025-11-07-student1"}`))
+		case "DELETE " + orgPath:
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+
+	rootCmd.SetArgs([]string{
+		"orgs", "delete",
+		"--lab-date", "2025-11-07",
+		"--user", "student1",
+		"--token", "test-token",
+		"--base-url", server.URL,
+	})
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("orgs delete failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"GET " + orgPath, "DELETE " + orgPath}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %s, want %s", i, requests[i], want[i])
+		}
+	}
+}
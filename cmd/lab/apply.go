@@ -0,0 +1,247 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	labservice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planFile   string
+	revalidate bool
+)
+
+// planConflictFlags are the inputs and settings a plan file fixes; they can't be passed with --plan
+var planConflictFlags = []string{
+	"users-file", "facilitators", "only-users", "exclude-users", "facilitators-as-admins-only",
+	"template-repos", "facilitator-templates", "exclude-templates", "facilitator-role",
+	"no-description", "require-all-valid", "wait-between-orgs", "org-retries", "shared-repo",
+	"shared-repo-org", "enable-dependabot", "org-policy", "verify-install", "wait-repo-ready",
+	"no-preflight", "enterprise-org-limit", "template-base-url", "org-profile-template",
+	"branch-protection-template",
+}
+
+func init() {
+	ApplyCmd.PersistentFlags().StringVar(&planFile, "plan", "", "Apply a plan file written by 'lab plan' instead of reading the users and template repos files")
+	ApplyCmd.PersistentFlags().BoolVar(&revalidate, "revalidate", false, "With --plan, check that every planned user and facilitator is still valid before applying")
+	ApplyCmd.PersistentFlags().StringVar(&templateReposFile, "template-repos", "", "Path to template repositories file (JSON) (required)")
+	ApplyCmd.MarkPersistentFlagRequired("template-repos")
+	ApplyCmd.PersistentFlags().StringVar(&facilitatorRole, "facilitator-role", "admin", "Organization role for facilitators on each lab organization: admin or member")
+	ApplyCmd.PersistentFlags().BoolVar(&noDescription, "no-description", false, "Create repositories with an empty description unless the template repos file sets one")
+	ApplyCmd.PersistentFlags().StringVar(&facilitatorTemplatesFile, "facilitator-templates", "", "Path to a template repositories file (JSON) used for facilitators' own organizations instead of --template-repos")
+	ApplyCmd.PersistentFlags().StringVar(&excludeTemplates, "exclude-templates", "", "Comma-separated template repositories (owner/repo) from the template repos file to skip for this run")
+	ApplyCmd.PersistentFlags().BoolVar(&requireAllValid, "require-all-valid", false, "Fail the run, listing the invalid users, if any user or facilitator is invalid instead of skipping them")
+	ApplyCmd.PersistentFlags().DurationVar(&waitBetweenOrgs, "wait-between-orgs", 0, "Pause each worker for this long (e.g. 5s) before starting its next organization, for instances that throttle bursts")
+	ApplyCmd.PersistentFlags().IntVar(&enterpriseOrgLimit, "enterprise-org-limit", 0, "Warn before provisioning if the enterprise would exceed this many organizations (0 = no known limit)")
+	ApplyCmd.PersistentFlags().BoolVar(&noPreflight, "no-preflight", false, "Skip the organization creation estimate (planned, existing and enterprise org counts) made before provisioning")
+	ApplyCmd.PersistentFlags().IntVar(&orgRetries, "org-retries", 0, "Retry a failed organization creation up to this many times with backoff before recording it as failed")
+	ApplyCmd.PersistentFlags().StringVar(&sharedRepo, "shared-repo", "", "Template repository (owner/repo) to create once for the whole lab and share read-only with every student org")
+	ApplyCmd.PersistentFlags().StringVar(&sharedRepoOrg, "shared-repo-org", "", "Organization to create --shared-repo in (defaults to the first facilitator's lab organization)")
+	ApplyCmd.PersistentFlags().StringVar(&orgPolicyFile, "org-policy", "", "Path to an organization policy file (JSON) with IP allow list entries and settings to apply to every lab organization")
+	ApplyCmd.PersistentFlags().StringVar(&branchProtectionFile, "branch-protection-template", "", "Path to a branch protection file (JSON) applied to the default branch of every lab repository")
+	ApplyCmd.PersistentFlags().BoolVar(&enableDependabot, "enable-dependabot", false, "Enable Dependabot alerts and security updates on each organization (for new repositories) and on every lab repository")
+	ApplyCmd.PersistentFlags().BoolVar(&verifyInstall, "verify-install", false, "After installing the GitHub App on each organization, verify the installation is active with the expected repository selection and record it in the report")
+	ApplyCmd.PersistentFlags().StringVar(&preHook, "pre-hook", "", "Local command run for each user before their organization is provisioned, e.g. \"./lms-enroll.sh {{.User}} {{.Org}}\" (split on spaces, run without a shell)")
+	ApplyCmd.PersistentFlags().StringVar(&postHook, "post-hook", "", "Local command run for each successfully provisioned organization, e.g. \"./roster-add.sh {{.Org}} {{.URL}}\" (split on spaces, run without a shell)")
+	ApplyCmd.PersistentFlags().DurationVar(&hookTimeout, "hook-timeout", config.DefaultHookTimeout, "Timeout for each hook command run")
+	ApplyCmd.PersistentFlags().StringVar(&hookFailureMode, "hook-failure-mode", config.HookFailureSkip, "What a failed hook does: skip (a failed pre-hook skips that user's organization, a failed post-hook is only reported) or fail (fail the organization, stop starting organizations and fail the run)")
+	ApplyCmd.PersistentFlags().StringVar(&orgProfileTemplate, "org-profile-template", "", "Template for each organization's profile (display) name, e.g. \"GHAS Lab - {{.User}} ({{.Date}})\"; variables {{.User}}, {{.Date}} and {{.Org}}. The login is unchanged; by default the profile name is the login")
+	ApplyCmd.PersistentFlags().StringVar(&templateBaseURL, "template-base-url", "", "API base URL of the host the template repositories live on, when it differs from --base-url (e.g. https://api.github.com while provisioning on GHES); such templates are imported rather than generated")
+	ApplyCmd.PersistentFlags().BoolVar(&waitRepoReady, "wait-repo-ready", false, "Wait for each repository created from a template to have its first commit before configuring it (up to 2m)")
+}
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Bring a lab environment to the desired state, creating only what's missing",
+	Long: `Idempotently reconcile a lab date with the users and template repos files. Missing
+organizations, app installations, admin memberships and repositories are created; resources
+that already exist are left unchanged and reported as already present. Safe to re-run.
+
+With --plan, apply exactly the organizations, templates and settings recorded by 'lab plan'
+without re-reading the input files; pass --revalidate to re-check the planned users first.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if planFile != "" {
+			return preRunApplyPlan(cmd, args)
+		}
+		if revalidate {
+			return fmt.Errorf("--revalidate can only be used with --plan")
+		}
+
+		// Check the input files before authentication or any API call
+		if err := util.CheckUsersFile(usersFile); err != nil {
+			return err
+		}
+		if err := util.CheckTemplateReposFile(templateReposFile); err != nil {
+			return err
+		}
+		if facilitatorTemplatesFile != "" {
+			if err := util.CheckTemplateReposFile(facilitatorTemplatesFile); err != nil {
+				return err
+			}
+		}
+
+		if orgPolicyFile != "" {
+			if err := util.CheckOrgPolicyFile(orgPolicyFile); err != nil {
+				return err
+			}
+		}
+		if branchProtectionFile != "" {
+			if err := util.CheckBranchProtectionFile(branchProtectionFile); err != nil {
+				return err
+			}
+		}
+
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		if labDate == "" {
+			return fmt.Errorf("required flag(s) \"lab-date\" not set")
+		}
+		if facilitatorRole != "admin" && facilitatorRole != "member" {
+			return fmt.Errorf("invalid --facilitator-role %q: must be admin or member", facilitatorRole)
+		}
+		if orgRetries < 0 {
+			return fmt.Errorf("--org-retries cannot be negative")
+		}
+		if waitBetweenOrgs < 0 {
+			return fmt.Errorf("--wait-between-orgs cannot be negative")
+		}
+		if enterpriseOrgLimit < 0 {
+			return fmt.Errorf("--enterprise-org-limit cannot be negative")
+		}
+		if sharedRepo != "" {
+			if parts := strings.Split(sharedRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("invalid --shared-repo %q: expected owner/repo", sharedRepo)
+			}
+			if sharedRepoOrg == "" && facilitatorsAdminsOnly {
+				return fmt.Errorf("--shared-repo requires --shared-repo-org with --facilitators-as-admins-only, since no facilitator organization is created")
+			}
+		}
+
+		if err := normalizeTemplateBaseURL(); err != nil {
+			return err
+		}
+		if err := checkOrgProfileTemplate(); err != nil {
+			return err
+		}
+		if err := checkMaxWorkers(); err != nil {
+			return err
+		}
+		preHookCommand, postHookCommand, err := parseHookFlags()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.FacilitatorsKey, strings.Split(facilitators, ","))
+		ctx = context.WithValue(ctx, config.MaxWorkersKey, maxWorkers)
+		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
+		ctx = context.WithValue(ctx, config.EnterpriseSlugKey, enterpriseSlug)
+		ctx = context.WithValue(ctx, config.OnlyUsersKey, util.SplitCommaList(onlyUsers))
+		ctx = context.WithValue(ctx, config.ExcludeUsersKey, util.SplitCommaList(excludeUsers))
+		ctx = context.WithValue(ctx, config.FacilitatorsAdminsOnlyKey, facilitatorsAdminsOnly)
+		ctx = context.WithValue(ctx, config.NoDescriptionKey, noDescription)
+		ctx = context.WithValue(ctx, config.WaitRepoReadyKey, waitRepoReady)
+		ctx = context.WithValue(ctx, config.TemplateBaseURLKey, templateBaseURL)
+		ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, orgProfileTemplate)
+		ctx = context.WithValue(ctx, config.PreHookKey, preHookCommand)
+		ctx = context.WithValue(ctx, config.PostHookKey, postHookCommand)
+		ctx = context.WithValue(ctx, config.HookTimeoutKey, hookTimeout)
+		ctx = context.WithValue(ctx, config.HookFailureModeKey, hookFailureMode)
+		ctx = context.WithValue(ctx, config.OrgRetriesKey, orgRetries)
+		ctx = context.WithValue(ctx, config.WaitBetweenOrgsKey, waitBetweenOrgs)
+		ctx = context.WithValue(ctx, config.RequireAllValidKey, requireAllValid)
+		ctx = context.WithValue(ctx, config.EnableDependabotKey, enableDependabot)
+		ctx = context.WithValue(ctx, config.OrgPolicyKey, orgPolicyFile)
+		ctx = context.WithValue(ctx, config.BranchProtectionKey, branchProtectionFile)
+		ctx = context.WithValue(ctx, config.EnterpriseOrgLimitKey, enterpriseOrgLimit)
+		ctx = context.WithValue(ctx, config.NoPreflightKey, noPreflight)
+		ctx = context.WithValue(ctx, config.ExcludeTemplatesKey, util.SplitCommaList(excludeTemplates))
+		ctx = context.WithValue(ctx, config.FacilitatorRoleKey, facilitatorRole)
+		ctx = context.WithValue(ctx, config.FacilitatorTemplatesKey, facilitatorTemplatesFile)
+		ctx = context.WithValue(ctx, config.VerifyInstallKey, verifyInstall)
+		ctx = context.WithValue(ctx, config.SharedRepoKey, sharedRepo)
+		ctx = context.WithValue(ctx, config.SharedRepoOrgKey, sharedRepoOrg)
+
+		cmd.SetContext(ctx)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok || logger == nil {
+			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+
+		if planFile != "" {
+			return labservice.ApplyLabPlan(ctx, logger, planFile, revalidate)
+		}
+		return labservice.ApplyLabEnvironment(ctx, logger, usersFile, templateReposFile)
+	},
+}
+
+// preRunApplyPlan prepares lab apply --plan, where the plan file replaces the input files and settings
+func preRunApplyPlan(cmd *cobra.Command, args []string) error {
+	for _, name := range planConflictFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s can't be combined with --plan; the plan file already records it", name)
+		}
+	}
+	if cmd.Flags().Changed("lab-date") {
+		return fmt.Errorf("--lab-date can't be combined with --plan; the plan file already records it")
+	}
+	if _, err := os.Stat(planFile); err != nil {
+		return fmt.Errorf("cannot read plan file %s: %w", planFile, err)
+	}
+
+	// The plan supplies the users, facilitators and templates, so the flags aren't required
+	for _, name := range []string{"users-file", "facilitators", "template-repos"} {
+		if err := cmd.Flags().SetAnnotation(name, cobra.BashCompOneRequiredFlag, []string{"false"}); err != nil {
+			return err
+		}
+	}
+
+	// Traverse up to find and call the root command's PersistentPreRunE
+	root := cmd
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	if root.PersistentPreRunE != nil {
+		if err := root.PersistentPreRunE(cmd, args); err != nil {
+			return err
+		}
+	}
+
+	// Hooks run local commands, so they are taken from the flags rather than the plan
+	preHookCommand, postHookCommand, err := parseHookFlags()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	ctx = context.WithValue(ctx, config.EnterpriseSlugKey, enterpriseSlug)
+	ctx = context.WithValue(ctx, config.PreHookKey, preHookCommand)
+	ctx = context.WithValue(ctx, config.PostHookKey, postHookCommand)
+	ctx = context.WithValue(ctx, config.HookTimeoutKey, hookTimeout)
+	ctx = context.WithValue(ctx, config.HookFailureModeKey, hookFailureMode)
+	cmd.SetContext(ctx)
+	return nil
+}
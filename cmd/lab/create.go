@@ -2,25 +2,80 @@ package lab
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	labservice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 	"github.com/spf13/cobra"
 )
 
 var (
-	repos             string
-	templateReposFile string
-	facilitators      string
+	repos              string
+	templateReposFile  string
+	facilitators       string
+	labDates           string
+	inviteToEnterprise bool
+	noDescription      bool
+	waitRepoReady      bool
+	facilitatorRole    string
+	excludeTemplates   string
+	orgRetries         int
+	orgsOnly           bool
+
+	facilitatorTemplatesFile string
+	verifyInstall            bool
+	sharedRepo               string
+	sharedRepoOrg            string
+	waitBetweenOrgs          time.Duration
+	requireAllValid          bool
+	enableDependabot         bool
+	orgPolicyFile            string
+	branchProtectionFile     string
+	reposOutput              string
+	noPreflight              bool
+	enterpriseOrgLimit       int
+	templateBaseURL          string
+	orgProfileTemplate       string
+	preHook                  string
+	postHook                 string
+	hookTimeout              time.Duration
+	hookFailureMode          string
 )
 
 func init() {
 
-	CreateCmd.PersistentFlags().StringVar(&templateReposFile, "template-repos", "", "Path to template repositories file (JSON) (required)")
-	CreateCmd.MarkPersistentFlagRequired("template-repos")
+	CreateCmd.PersistentFlags().StringVar(&templateReposFile, "template-repos", "", "Path to template repositories file (JSON) (required unless --orgs-only)")
+	CreateCmd.PersistentFlags().BoolVar(&orgsOnly, "orgs-only", false, "Create and configure organizations only, without repositories; --template-repos is not needed")
+	CreateCmd.PersistentFlags().StringVar(&labDates, "lab-dates", "", "Comma-separated lab dates to provision in one run (e.g., '2024-06-15,2024-06-22'). Mutually exclusive with --lab-date")
+	CreateCmd.PersistentFlags().BoolVar(&inviteToEnterprise, "invite-to-enterprise", false, "Invite users who aren't enterprise members to the enterprise before creating organizations")
+	CreateCmd.PersistentFlags().StringVar(&facilitatorRole, "facilitator-role", "admin", "Organization role for facilitators on each lab organization: admin or member")
+	CreateCmd.PersistentFlags().BoolVar(&noDescription, "no-description", false, "Create repositories with an empty description unless the template repos file sets one")
+	CreateCmd.PersistentFlags().StringVar(&facilitatorTemplatesFile, "facilitator-templates", "", "Path to a template repositories file (JSON) used for facilitators' own organizations instead of --template-repos")
+	CreateCmd.PersistentFlags().StringVar(&excludeTemplates, "exclude-templates", "", "Comma-separated template repositories (owner/repo) from the template repos file to skip for this run")
+	CreateCmd.PersistentFlags().BoolVar(&requireAllValid, "require-all-valid", false, "Fail the run, listing the invalid users, if any user or facilitator is invalid instead of skipping them")
+	CreateCmd.PersistentFlags().DurationVar(&waitBetweenOrgs, "wait-between-orgs", 0, "Pause each worker for this long (e.g. 5s) before starting its next organization, for instances that throttle bursts")
+	CreateCmd.PersistentFlags().IntVar(&enterpriseOrgLimit, "enterprise-org-limit", 0, "Warn before provisioning if the enterprise would exceed this many organizations (0 = no known limit)")
+	CreateCmd.PersistentFlags().BoolVar(&noPreflight, "no-preflight", false, "Skip the organization creation estimate (planned, existing and enterprise org counts) made before provisioning")
+	CreateCmd.PersistentFlags().IntVar(&orgRetries, "org-retries", 0, "Retry a failed organization creation up to this many times with backoff before recording it as failed")
+	CreateCmd.PersistentFlags().StringVar(&sharedRepo, "shared-repo", "", "Template repository (owner/repo) to create once for the whole lab and share read-only with every student org")
+	CreateCmd.PersistentFlags().StringVar(&sharedRepoOrg, "shared-repo-org", "", "Organization to create --shared-repo in (defaults to the first facilitator's lab organization)")
+	CreateCmd.PersistentFlags().StringVar(&reposOutput, "repos-output", "", "Write the repositories this run created (org, repo and template) to this JSON file, for lab delete --created-repos")
+	CreateCmd.PersistentFlags().StringVar(&orgPolicyFile, "org-policy", "", "Path to an organization policy file (JSON) with IP allow list entries and settings to apply to every lab organization")
+	CreateCmd.PersistentFlags().StringVar(&branchProtectionFile, "branch-protection-template", "", "Path to a branch protection file (JSON) applied to the default branch of every lab repository")
+	CreateCmd.PersistentFlags().BoolVar(&enableDependabot, "enable-dependabot", false, "Enable Dependabot alerts and security updates on each organization (for new repositories) and on every lab repository")
+	CreateCmd.PersistentFlags().BoolVar(&verifyInstall, "verify-install", false, "After installing the GitHub App on each organization, verify the installation is active with the expected repository selection and record it in the report")
+	CreateCmd.PersistentFlags().StringVar(&preHook, "pre-hook", "", "Local command run for each user before their organization is provisioned, e.g. \"./lms-enroll.sh {{.User}} {{.Org}}\" (split on spaces, run without a shell)")
+	CreateCmd.PersistentFlags().StringVar(&postHook, "post-hook", "", "Local command run for each successfully provisioned organization, e.g. \"./roster-add.sh {{.Org}} {{.URL}}\" (split on spaces, run without a shell)")
+	CreateCmd.PersistentFlags().DurationVar(&hookTimeout, "hook-timeout", config.DefaultHookTimeout, "Timeout for each hook command run")
+	CreateCmd.PersistentFlags().StringVar(&hookFailureMode, "hook-failure-mode", config.HookFailureSkip, "What a failed hook does: skip (a failed pre-hook skips that user's organization, a failed post-hook is only reported) or fail (fail the organization, stop starting organizations and fail the run)")
+	CreateCmd.PersistentFlags().StringVar(&orgProfileTemplate, "org-profile-template", "", "Template for each organization's profile (display) name, e.g. \"GHAS Lab - {{.User}} ({{.Date}})\"; variables {{.User}}, {{.Date}} and {{.Org}}. The login is unchanged; by default the profile name is the login")
+	CreateCmd.PersistentFlags().StringVar(&templateBaseURL, "template-base-url", "", "API base URL of the host the template repositories live on, when it differs from --base-url (e.g. https://api.github.com while provisioning on GHES); such templates are imported rather than generated")
+	CreateCmd.PersistentFlags().BoolVar(&waitRepoReady, "wait-repo-ready", false, "Wait for each repository created from a template to have its first commit before configuring it (up to 2m)")
 
 }
 
@@ -28,6 +83,39 @@ var CreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a full lab environment (org, repos, users)",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Check the input files before authentication or any API call
+		if err := util.CheckUsersFile(usersFile); err != nil {
+			return err
+		}
+		if orgsOnly {
+			if templateReposFile != "" || facilitatorTemplatesFile != "" {
+				return fmt.Errorf("--orgs-only can't be combined with --template-repos or --facilitator-templates")
+			}
+		} else {
+			if templateReposFile == "" {
+				return fmt.Errorf("required flag(s) \"template-repos\" not set (or pass --orgs-only)")
+			}
+			if err := util.CheckTemplateReposFile(templateReposFile); err != nil {
+				return err
+			}
+			if facilitatorTemplatesFile != "" {
+				if err := util.CheckTemplateReposFile(facilitatorTemplatesFile); err != nil {
+					return err
+				}
+			}
+		}
+
+		if orgPolicyFile != "" {
+			if err := util.CheckOrgPolicyFile(orgPolicyFile); err != nil {
+				return err
+			}
+		}
+		if branchProtectionFile != "" {
+			if err := util.CheckBranchProtectionFile(branchProtectionFile); err != nil {
+				return err
+			}
+		}
+
 		// Traverse up to find and call the root command's PersistentPreRunE
 		root := cmd
 		for root.Parent() != nil {
@@ -41,10 +129,80 @@ var CreateCmd = &cobra.Command{
 			}
 		}
 
+		if labDate == "" && labDates == "" {
+			return fmt.Errorf("either --lab-date or --lab-dates is required")
+		}
+		if labDate != "" && labDates != "" {
+			return fmt.Errorf("--lab-date and --lab-dates are mutually exclusive")
+		}
+		if facilitatorRole != "admin" && facilitatorRole != "member" {
+			return fmt.Errorf("invalid --facilitator-role %q: must be admin or member", facilitatorRole)
+		}
+		if orgRetries < 0 {
+			return fmt.Errorf("--org-retries cannot be negative")
+		}
+		if waitBetweenOrgs < 0 {
+			return fmt.Errorf("--wait-between-orgs cannot be negative")
+		}
+		if enterpriseOrgLimit < 0 {
+			return fmt.Errorf("--enterprise-org-limit cannot be negative")
+		}
+		if sharedRepo != "" {
+			if parts := strings.Split(sharedRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("invalid --shared-repo %q: expected owner/repo", sharedRepo)
+			}
+			if sharedRepoOrg == "" && facilitatorsAdminsOnly {
+				return fmt.Errorf("--shared-repo requires --shared-repo-org with --facilitators-as-admins-only, since no facilitator organization is created")
+			}
+		}
+
+		if err := normalizeTemplateBaseURL(); err != nil {
+			return err
+		}
+		if err := checkOrgProfileTemplate(); err != nil {
+			return err
+		}
+		if err := checkMaxWorkers(); err != nil {
+			return err
+		}
+		preHookCommand, postHookCommand, err := parseHookFlags()
+		if err != nil {
+			return err
+		}
+
 		ctx := cmd.Context()
 		ctx = context.WithValue(ctx, config.FacilitatorsKey, strings.Split(facilitators, ","))
+		ctx = context.WithValue(ctx, config.MaxWorkersKey, maxWorkers)
 		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
 		ctx = context.WithValue(ctx, config.EnterpriseSlugKey, enterpriseSlug)
+		ctx = context.WithValue(ctx, config.OnlyUsersKey, util.SplitCommaList(onlyUsers))
+		ctx = context.WithValue(ctx, config.ExcludeUsersKey, util.SplitCommaList(excludeUsers))
+		ctx = context.WithValue(ctx, config.FacilitatorsAdminsOnlyKey, facilitatorsAdminsOnly)
+		ctx = context.WithValue(ctx, config.InviteToEnterpriseKey, inviteToEnterprise)
+		ctx = context.WithValue(ctx, config.NoDescriptionKey, noDescription)
+		ctx = context.WithValue(ctx, config.WaitRepoReadyKey, waitRepoReady)
+		ctx = context.WithValue(ctx, config.TemplateBaseURLKey, templateBaseURL)
+		ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, orgProfileTemplate)
+		ctx = context.WithValue(ctx, config.PreHookKey, preHookCommand)
+		ctx = context.WithValue(ctx, config.PostHookKey, postHookCommand)
+		ctx = context.WithValue(ctx, config.HookTimeoutKey, hookTimeout)
+		ctx = context.WithValue(ctx, config.HookFailureModeKey, hookFailureMode)
+		ctx = context.WithValue(ctx, config.OrgRetriesKey, orgRetries)
+		ctx = context.WithValue(ctx, config.WaitBetweenOrgsKey, waitBetweenOrgs)
+		ctx = context.WithValue(ctx, config.RequireAllValidKey, requireAllValid)
+		ctx = context.WithValue(ctx, config.EnableDependabotKey, enableDependabot)
+		ctx = context.WithValue(ctx, config.OrgPolicyKey, orgPolicyFile)
+		ctx = context.WithValue(ctx, config.BranchProtectionKey, branchProtectionFile)
+		ctx = context.WithValue(ctx, config.EnterpriseOrgLimitKey, enterpriseOrgLimit)
+		ctx = context.WithValue(ctx, config.NoPreflightKey, noPreflight)
+		ctx = context.WithValue(ctx, config.ReposOutputKey, reposOutput)
+		ctx = context.WithValue(ctx, config.ExcludeTemplatesKey, util.SplitCommaList(excludeTemplates))
+		ctx = context.WithValue(ctx, config.FacilitatorRoleKey, facilitatorRole)
+		ctx = context.WithValue(ctx, config.OrgsOnlyKey, orgsOnly)
+		ctx = context.WithValue(ctx, config.FacilitatorTemplatesKey, facilitatorTemplatesFile)
+		ctx = context.WithValue(ctx, config.VerifyInstallKey, verifyInstall)
+		ctx = context.WithValue(ctx, config.SharedRepoKey, sharedRepo)
+		ctx = context.WithValue(ctx, config.SharedRepoOrgKey, sharedRepoOrg)
 
 		cmd.SetContext(ctx)
 		return nil
@@ -59,6 +217,60 @@ var CreateCmd = &cobra.Command{
 			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
 		}
 
+		if labDates != "" {
+			dates := util.SplitCommaList(labDates)
+			if len(dates) == 0 {
+				return fmt.Errorf("--lab-dates did not contain any dates")
+			}
+			return labservice.CreateLabEnvironments(ctx, logger, dates, usersFile, templateReposFile)
+		}
+
 		return labservice.CreateLabEnvironment(ctx, logger, usersFile, templateReposFile)
 	},
 }
+
+// checkOrgProfileTemplate validates --org-profile-template, which may be unset
+func checkOrgProfileTemplate() error {
+	if orgProfileTemplate == "" {
+		return nil
+	}
+	if err := util.ValidateOrgProfileTemplate(orgProfileTemplate); err != nil {
+		return fmt.Errorf("invalid --org-profile-template: %w", err)
+	}
+	return nil
+}
+
+// normalizeTemplateBaseURL validates and normalizes --template-base-url like --base-url
+func normalizeTemplateBaseURL() error {
+	if templateBaseURL == "" {
+		return nil
+	}
+	normalized, err := util.NormalizeBaseURL(templateBaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid --template-base-url: %w", err)
+	}
+	templateBaseURL = normalized
+	return nil
+}
+
+// parseHookFlags validates the hook flags and parses --pre-hook and --post-hook, either
+// of which may be unset
+func parseHookFlags() (pre util.HookCommand, post util.HookCommand, err error) {
+	if hookTimeout <= 0 {
+		return pre, post, fmt.Errorf("--hook-timeout must be positive")
+	}
+	if hookFailureMode != config.HookFailureSkip && hookFailureMode != config.HookFailureFail {
+		return pre, post, fmt.Errorf("invalid --hook-failure-mode %q: must be %s or %s", hookFailureMode, config.HookFailureSkip, config.HookFailureFail)
+	}
+	if preHook != "" {
+		if pre, err = util.ParseHookCommand(preHook); err != nil {
+			return pre, post, fmt.Errorf("invalid --pre-hook: %w", err)
+		}
+	}
+	if postHook != "" {
+		if post, err = util.ParseHookCommand(postHook); err != nil {
+			return pre, post, fmt.Errorf("invalid --post-hook: %w", err)
+		}
+	}
+	return pre, post, nil
+}
@@ -2,19 +2,58 @@ package lab
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	labservice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 	"github.com/spf13/cobra"
 )
 
+var (
+	requirePrefix string
+	allowAnyName  bool
+	preserveUsers string
+	discoverOrgs  bool
+	createdRepos  string
+
+	deleteReposFirst      bool
+	repoDeleteConcurrency int
+	deleteStateFile       string
+)
+
+func init() {
+	DeleteCmd.Flags().StringVar(&requirePrefix, "require-prefix", util.OrgLoginPrefix, "Refuse to delete any organization whose login doesn't start with this prefix")
+	DeleteCmd.Flags().BoolVar(&allowAnyName, "allow-any-name", false, "Disable the --require-prefix guard and delete organizations with any name")
+	DeleteCmd.Flags().BoolVar(&discoverOrgs, "discover", false, "Also delete enterprise organizations named for this lab date that the users file doesn't list")
+	DeleteCmd.Flags().StringVar(&createdRepos, "created-repos", "", "Path to a file written by lab create --repos-output; delete exactly the repositories it lists and keep the organizations")
+	DeleteCmd.Flags().BoolVar(&deleteReposFirst, "delete-repos-first", false, "Delete every repository in each organization before deleting the organization, for GHES instances where some repositories block organization deletion")
+	DeleteCmd.Flags().IntVar(&repoDeleteConcurrency, "repo-delete-concurrency", config.DefaultRepoDeleteConcurrency, "Repositories deleted at a time within an organization with --delete-repos-first")
+	DeleteCmd.Flags().StringVar(&deleteStateFile, "state-file", "", "Record each organization's deletion in this file and skip the ones it records as deleted, to resume an interrupted deletion")
+	DeleteCmd.Flags().StringVar(&preserveUsers, "preserve-users", "", "Comma-separated users whose lab organizations are kept instead of deleted")
+}
+
 var DeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a full lab environment (org, repos, users)",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if createdRepos != "" {
+			if discoverOrgs || preserveUsers != "" || deleteReposFirst || deleteStateFile != "" {
+				return fmt.Errorf("--created-repos can't be combined with --discover, --preserve-users, --delete-repos-first or --state-file")
+			}
+			if err := util.CheckCreatedReposFile(createdRepos); err != nil {
+				return err
+			}
+			// The file lists the repositories, so the users and facilitators aren't needed
+			for _, name := range []string{"users-file", "facilitators"} {
+				if err := cmd.Flags().SetAnnotation(name, cobra.BashCompOneRequiredFlag, []string{"false"}); err != nil {
+					return err
+				}
+			}
+		}
 
 		// Traverse up to find and call the root command's PersistentPreRunE
 		root := cmd
@@ -29,10 +68,31 @@ var DeleteCmd = &cobra.Command{
 			}
 		}
 
+		if labDate == "" {
+			return fmt.Errorf("required flag(s) \"lab-date\" not set")
+		}
+		if repoDeleteConcurrency < 1 {
+			return fmt.Errorf("--repo-delete-concurrency must be at least 1")
+		}
+		if err := checkMaxWorkers(); err != nil {
+			return err
+		}
+
 		ctx := cmd.Context()
 		ctx = context.WithValue(ctx, config.FacilitatorsKey, strings.Split(facilitators, ","))
 		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
 		ctx = context.WithValue(ctx, config.EnterpriseSlugKey, enterpriseSlug)
+		ctx = context.WithValue(ctx, config.OnlyUsersKey, util.SplitCommaList(onlyUsers))
+		ctx = context.WithValue(ctx, config.ExcludeUsersKey, util.SplitCommaList(excludeUsers))
+		ctx = context.WithValue(ctx, config.FacilitatorsAdminsOnlyKey, facilitatorsAdminsOnly)
+		ctx = context.WithValue(ctx, config.RequirePrefixKey, requirePrefix)
+		ctx = context.WithValue(ctx, config.AllowAnyNameKey, allowAnyName)
+		ctx = context.WithValue(ctx, config.PreserveUsersKey, util.SplitCommaList(preserveUsers))
+		ctx = context.WithValue(ctx, config.DiscoverOrgsKey, discoverOrgs)
+		ctx = context.WithValue(ctx, config.DeleteReposFirstKey, deleteReposFirst)
+		ctx = context.WithValue(ctx, config.RepoDeleteConcurrencyKey, repoDeleteConcurrency)
+		ctx = context.WithValue(ctx, config.MaxWorkersKey, maxWorkers)
+		ctx = context.WithValue(ctx, config.DeleteStateFileKey, deleteStateFile)
 
 		cmd.SetContext(ctx)
 		return nil
@@ -44,6 +104,45 @@ var DeleteCmd = &cobra.Command{
 			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
 		}
 
-		return labservice.DestroyLabEnvironment(ctx, logger, labDate, usersFile)
+		if createdRepos != "" {
+			repos, err := util.LoadCreatedReposFile(createdRepos)
+			if err != nil {
+				return err
+			}
+			results, err := labservice.DeleteCreatedRepos(ctx, logger, labDate, repos)
+			printCreatedRepoDeleteResults(results)
+			return err
+		}
+
+		_, err := labservice.DestroyLabEnvironment(ctx, logger, labDate, usersFile)
+		return err
 	},
 }
+
+// printCreatedRepoDeleteResults prints the outcome of each repository deletion by organization
+func printCreatedRepoDeleteResults(results []labservice.OrgRepoDeleteResult) {
+	counts := map[string]int{}
+	for _, org := range results {
+		fmt.Printf("\nRepository deletion in %s:\n", org.Org)
+		for _, result := range org.Results {
+			counts[result.Status]++
+			switch result.Status {
+			case labservice.RepoDeleteStatusDeleted:
+				fmt.Printf("  ✅ %s deleted\n", result.Name)
+			case labservice.RepoDeleteStatusNotFound:
+				fmt.Printf("  ⏭️ %s not found (already deleted)\n", result.Name)
+			default:
+				fmt.Printf("  ❌ %s failed: %s\n", result.Name, result.Error)
+			}
+		}
+		if len(org.Results) == 0 && org.Error != "" {
+			fmt.Printf("  ❌ %s\n", org.Error)
+		}
+	}
+	if len(results) > 0 {
+		fmt.Printf("%d deleted, %d not found, %d failed\n",
+			counts[labservice.RepoDeleteStatusDeleted],
+			counts[labservice.RepoDeleteStatusNotFound],
+			counts[labservice.RepoDeleteStatusFailed])
+	}
+}
@@ -1,6 +1,9 @@
 package lab
 
 import (
+	"fmt"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -8,6 +11,13 @@ var (
 	usersFile      string
 	labDate        string
 	enterpriseSlug string
+	enterpriseID   string
+	billingEmail   string
+	onlyUsers      string
+	excludeUsers   string
+	maxWorkers     int
+
+	facilitatorsAdminsOnly bool
 )
 
 var LabCmd = &cobra.Command{
@@ -18,14 +28,29 @@ var LabCmd = &cobra.Command{
 
 func init() {
 	LabCmd.PersistentFlags().StringVar(&labDate, "lab-date", "", "Date string to identify date of the lab (e.g., '2024-06-15')")
-	LabCmd.MarkPersistentFlagRequired("lab-date")
 	LabCmd.PersistentFlags().StringVar(&usersFile, "users-file", "", "Path to user file (txt) (required)")
 	LabCmd.MarkPersistentFlagRequired("users-file")
 	LabCmd.PersistentFlags().StringVar(&facilitators, "facilitators", "", "lab facilitators usernames, comma-separated")
 	LabCmd.MarkPersistentFlagRequired("facilitators")
-	LabCmd.PersistentFlags().StringVar(&enterpriseSlug, "enterprise-slug", "", "GitHub Enterprise slug")
-	LabCmd.MarkPersistentFlagRequired("enterprise-slug")
+	LabCmd.PersistentFlags().StringVar(&enterpriseSlug, "enterprise-slug", "", "GitHub Enterprise slug (required) [env: GHAS_LAB_ENTERPRISE_SLUG]")
+	LabCmd.PersistentFlags().StringVar(&enterpriseID, "enterprise-id", "", "GraphQL node ID of the enterprise; with --billing-email, skips looking the enterprise up by slug (the slug isn't validated)")
+	LabCmd.PersistentFlags().StringVar(&billingEmail, "billing-email", "", "Enterprise billing email used for new organizations with --enterprise-id")
+	LabCmd.PersistentFlags().StringVar(&onlyUsers, "only-users", "", "Only process these usernames from the users file, comma-separated")
+	LabCmd.PersistentFlags().StringVar(&excludeUsers, "exclude-users", "", "Skip these usernames from the users file, comma-separated")
+	LabCmd.PersistentFlags().IntVar(&maxWorkers, "max-workers", config.DefaultMaxWorkers, fmt.Sprintf("Number of organizations created or deleted concurrently (1-%d); lower it on small GHES instances that hit secondary rate limits", config.MaxWorkersLimit))
+	LabCmd.PersistentFlags().BoolVar(&facilitatorsAdminsOnly, "facilitators-as-admins-only", false, "Don't create (or delete) personal organizations for facilitators; they are only added as admins on student organizations")
 
 	LabCmd.AddCommand(CreateCmd)
 	LabCmd.AddCommand(DeleteCmd)
+	LabCmd.AddCommand(ApplyCmd)
+	LabCmd.AddCommand(PlanCmd)
+	LabCmd.AddCommand(RetryFailedCmd)
+}
+
+// checkMaxWorkers validates --max-workers
+func checkMaxWorkers() error {
+	if maxWorkers < 1 || maxWorkers > config.MaxWorkersLimit {
+		return fmt.Errorf("--max-workers must be between 1 and %d", config.MaxWorkersLimit)
+	}
+	return nil
 }
@@ -0,0 +1,196 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	labservice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var planOut string
+
+func init() {
+	PlanCmd.PersistentFlags().StringVar(&planOut, "out", "", "Path to write the plan file to (defaults to lab-plan-<lab-date>.json)")
+	PlanCmd.PersistentFlags().StringVar(&templateReposFile, "template-repos", "", "Path to template repositories file (JSON) (required unless --orgs-only)")
+	PlanCmd.PersistentFlags().BoolVar(&orgsOnly, "orgs-only", false, "Plan organizations only, without repositories; --template-repos is not needed")
+	PlanCmd.PersistentFlags().BoolVar(&inviteToEnterprise, "invite-to-enterprise", false, "Invite users who aren't enterprise members to the enterprise before creating organizations")
+	PlanCmd.PersistentFlags().StringVar(&facilitatorRole, "facilitator-role", "admin", "Organization role for facilitators on each lab organization: admin or member")
+	PlanCmd.PersistentFlags().BoolVar(&noDescription, "no-description", false, "Create repositories with an empty description unless the template repos file sets one")
+	PlanCmd.PersistentFlags().StringVar(&facilitatorTemplatesFile, "facilitator-templates", "", "Path to a template repositories file (JSON) used for facilitators' own organizations instead of --template-repos")
+	PlanCmd.PersistentFlags().StringVar(&excludeTemplates, "exclude-templates", "", "Comma-separated template repositories (owner/repo) from the template repos file to skip")
+	PlanCmd.PersistentFlags().IntVar(&enterpriseOrgLimit, "enterprise-org-limit", 0, "Warn before provisioning if the enterprise would exceed this many organizations (0 = no known limit)")
+	PlanCmd.PersistentFlags().IntVar(&orgRetries, "org-retries", 0, "Retry a failed organization creation up to this many times with backoff before recording it as failed")
+	PlanCmd.PersistentFlags().BoolVar(&requireAllValid, "require-all-valid", false, "Fail, listing the invalid users, if any user or facilitator is invalid instead of leaving them out of the plan")
+	PlanCmd.PersistentFlags().DurationVar(&waitBetweenOrgs, "wait-between-orgs", 0, "Pause each worker for this long (e.g. 5s) before starting its next organization, for instances that throttle bursts")
+	PlanCmd.PersistentFlags().StringVar(&sharedRepo, "shared-repo", "", "Template repository (owner/repo) to create once for the whole lab and share read-only with every student org")
+	PlanCmd.PersistentFlags().StringVar(&sharedRepoOrg, "shared-repo-org", "", "Organization to create --shared-repo in (defaults to the first facilitator's lab organization)")
+	PlanCmd.PersistentFlags().StringVar(&orgPolicyFile, "org-policy", "", "Path to an organization policy file (JSON) with IP allow list entries and settings to apply to every lab organization")
+	PlanCmd.PersistentFlags().StringVar(&branchProtectionFile, "branch-protection-template", "", "Path to a branch protection file (JSON) applied to the default branch of every lab repository")
+	PlanCmd.PersistentFlags().BoolVar(&enableDependabot, "enable-dependabot", false, "Enable Dependabot alerts and security updates on each organization (for new repositories) and on every lab repository")
+	PlanCmd.PersistentFlags().BoolVar(&verifyInstall, "verify-install", false, "After installing the GitHub App on each organization, verify the installation is active with the expected repository selection and record it in the report")
+	PlanCmd.PersistentFlags().StringVar(&orgProfileTemplate, "org-profile-template", "", "Template for each organization's profile (display) name, e.g. \"GHAS Lab - {{.User}} ({{.Date}})\"; variables {{.User}}, {{.Date}} and {{.Org}}. The login is unchanged; by default the profile name is the login")
+	PlanCmd.PersistentFlags().StringVar(&templateBaseURL, "template-base-url", "", "API base URL of the host the template repositories live on, when it differs from --base-url (e.g. https://api.github.com while provisioning on GHES); such templates are imported rather than generated")
+	PlanCmd.PersistentFlags().BoolVar(&waitRepoReady, "wait-repo-ready", false, "Wait for each repository created from a template to have its first commit before configuring it (up to 2m)")
+}
+
+var PlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Validate a lab and write the plan that lab apply --plan executes",
+	Long: `Load and validate the users and template repos files and write a plan file listing the
+validated users, the organizations to create, the templates and the settings in effect.
+Nothing is created. Review or archive the plan, then run 'lab apply --plan <file>' to
+provision exactly what it describes.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Check the input files before authentication or any API call
+		if err := util.CheckUsersFile(usersFile); err != nil {
+			return err
+		}
+		if orgsOnly {
+			if templateReposFile != "" || facilitatorTemplatesFile != "" {
+				return fmt.Errorf("--orgs-only can't be combined with --template-repos or --facilitator-templates")
+			}
+		} else {
+			if templateReposFile == "" {
+				return fmt.Errorf("required flag(s) \"template-repos\" not set (or pass --orgs-only)")
+			}
+			if err := util.CheckTemplateReposFile(templateReposFile); err != nil {
+				return err
+			}
+			if facilitatorTemplatesFile != "" {
+				if err := util.CheckTemplateReposFile(facilitatorTemplatesFile); err != nil {
+					return err
+				}
+			}
+		}
+
+		if orgPolicyFile != "" {
+			if err := util.CheckOrgPolicyFile(orgPolicyFile); err != nil {
+				return err
+			}
+		}
+		if branchProtectionFile != "" {
+			if err := util.CheckBranchProtectionFile(branchProtectionFile); err != nil {
+				return err
+			}
+		}
+
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		if labDate == "" {
+			return fmt.Errorf("required flag(s) \"lab-date\" not set")
+		}
+		if facilitatorRole != "admin" && facilitatorRole != "member" {
+			return fmt.Errorf("invalid --facilitator-role %q: must be admin or member", facilitatorRole)
+		}
+		if orgRetries < 0 {
+			return fmt.Errorf("--org-retries cannot be negative")
+		}
+		if waitBetweenOrgs < 0 {
+			return fmt.Errorf("--wait-between-orgs cannot be negative")
+		}
+		if enterpriseOrgLimit < 0 {
+			return fmt.Errorf("--enterprise-org-limit cannot be negative")
+		}
+		if sharedRepo != "" {
+			if parts := strings.Split(sharedRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("invalid --shared-repo %q: expected owner/repo", sharedRepo)
+			}
+			if sharedRepoOrg == "" && facilitatorsAdminsOnly {
+				return fmt.Errorf("--shared-repo requires --shared-repo-org with --facilitators-as-admins-only, since no facilitator organization is created")
+			}
+		}
+		if planOut == "" {
+			planOut = fmt.Sprintf("lab-plan-%s.json", labDate)
+		}
+
+		if err := normalizeTemplateBaseURL(); err != nil {
+			return err
+		}
+		if err := checkOrgProfileTemplate(); err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.FacilitatorsKey, strings.Split(facilitators, ","))
+		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
+		ctx = context.WithValue(ctx, config.EnterpriseSlugKey, enterpriseSlug)
+		ctx = context.WithValue(ctx, config.OnlyUsersKey, util.SplitCommaList(onlyUsers))
+		ctx = context.WithValue(ctx, config.ExcludeUsersKey, util.SplitCommaList(excludeUsers))
+		ctx = context.WithValue(ctx, config.FacilitatorsAdminsOnlyKey, facilitatorsAdminsOnly)
+		ctx = context.WithValue(ctx, config.InviteToEnterpriseKey, inviteToEnterprise)
+		ctx = context.WithValue(ctx, config.NoDescriptionKey, noDescription)
+		ctx = context.WithValue(ctx, config.WaitRepoReadyKey, waitRepoReady)
+		ctx = context.WithValue(ctx, config.TemplateBaseURLKey, templateBaseURL)
+		ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, orgProfileTemplate)
+		ctx = context.WithValue(ctx, config.OrgRetriesKey, orgRetries)
+		ctx = context.WithValue(ctx, config.WaitBetweenOrgsKey, waitBetweenOrgs)
+		ctx = context.WithValue(ctx, config.RequireAllValidKey, requireAllValid)
+		ctx = context.WithValue(ctx, config.EnableDependabotKey, enableDependabot)
+		ctx = context.WithValue(ctx, config.OrgPolicyKey, orgPolicyFile)
+		ctx = context.WithValue(ctx, config.BranchProtectionKey, branchProtectionFile)
+		ctx = context.WithValue(ctx, config.EnterpriseOrgLimitKey, enterpriseOrgLimit)
+		ctx = context.WithValue(ctx, config.ExcludeTemplatesKey, util.SplitCommaList(excludeTemplates))
+		ctx = context.WithValue(ctx, config.FacilitatorRoleKey, facilitatorRole)
+		ctx = context.WithValue(ctx, config.OrgsOnlyKey, orgsOnly)
+		ctx = context.WithValue(ctx, config.FacilitatorTemplatesKey, facilitatorTemplatesFile)
+		ctx = context.WithValue(ctx, config.VerifyInstallKey, verifyInstall)
+		ctx = context.WithValue(ctx, config.SharedRepoKey, sharedRepo)
+		ctx = context.WithValue(ctx, config.SharedRepoOrgKey, sharedRepoOrg)
+
+		cmd.SetContext(ctx)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok || logger == nil {
+			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+
+		plan, err := labservice.PlanLabEnvironment(ctx, logger, usersFile, templateReposFile, planOut)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Plan for lab date %s written to %s\n", plan.LabDate, planOut)
+		fmt.Fprintf(out, "  Organizations to provision: %d\n", len(plan.Orgs))
+		for _, org := range plan.Orgs {
+			fmt.Fprintf(out, "    + %s (@%s)\n", org.OrgName, org.User)
+		}
+		fmt.Fprintf(out, "  Repositories per organization: %d\n", len(plan.TemplateRepos))
+		if len(plan.InvalidUsers)+len(plan.InvalidFacilitators) > 0 {
+			fmt.Fprintf(out, "  Left out as invalid: %d user(s), %d facilitator(s)\n", len(plan.InvalidUsers), len(plan.InvalidFacilitators))
+		}
+		if estimate := plan.Estimate; estimate != nil {
+			fmt.Fprintf(out, "  Estimate: %d new organization(s), %d already exist; the enterprise has %d organization(s)\n",
+				estimate.NewOrgs, estimate.ExistingOrgs, estimate.EnterpriseOrgs)
+			if estimate.MinDuration != "" {
+				fmt.Fprintf(out, "  Organization creation takes at least %s with --org-create-interval\n", estimate.MinDuration)
+			}
+			for _, warning := range estimate.Warnings {
+				fmt.Fprintf(out, "  ⚠️  %s\n", warning)
+			}
+		}
+		fmt.Fprintf(out, "Review the plan, then run: ghas-lab-builder lab apply --plan %s\n", planOut)
+		return nil
+	},
+}
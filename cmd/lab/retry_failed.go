@@ -0,0 +1,176 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	labservice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var retryFrom string
+
+// retryReportFlags are read from the previous report instead
+var retryReportFlags = []string{"users-file", "facilitators", "lab-date", "enterprise-slug", "only-users", "facilitators-as-admins-only"}
+
+func init() {
+	RetryFailedCmd.PersistentFlags().StringVar(&retryFrom, "from", "", "Path to a lab-report-*.json from lab create or lab apply (required)")
+	RetryFailedCmd.MarkPersistentFlagRequired("from")
+	RetryFailedCmd.PersistentFlags().StringVar(&templateReposFile, "template-repos", "", "Path to template repositories file (JSON) (required unless --orgs-only)")
+	RetryFailedCmd.PersistentFlags().BoolVar(&orgsOnly, "orgs-only", false, "Retry organizations only, without repositories; --template-repos is not needed")
+	RetryFailedCmd.PersistentFlags().StringVar(&facilitatorRole, "facilitator-role", "admin", "Organization role for facilitators on each lab organization: admin or member")
+	RetryFailedCmd.PersistentFlags().BoolVar(&noDescription, "no-description", false, "Create repositories with an empty description unless the template repos file sets one")
+	RetryFailedCmd.PersistentFlags().StringVar(&facilitatorTemplatesFile, "facilitator-templates", "", "Path to a template repositories file (JSON) used for facilitators' own organizations instead of --template-repos")
+	RetryFailedCmd.PersistentFlags().StringVar(&excludeTemplates, "exclude-templates", "", "Comma-separated template repositories (owner/repo) from the template repos file to skip for this run")
+	RetryFailedCmd.PersistentFlags().BoolVar(&noPreflight, "no-preflight", false, "Skip the organization creation estimate (planned, existing and enterprise org counts) made before provisioning")
+	RetryFailedCmd.PersistentFlags().IntVar(&orgRetries, "org-retries", 0, "Retry a failed organization creation up to this many times with backoff before recording it as failed")
+	RetryFailedCmd.PersistentFlags().DurationVar(&waitBetweenOrgs, "wait-between-orgs", 0, "Pause each worker for this long (e.g. 5s) before starting its next organization, for instances that throttle bursts")
+	RetryFailedCmd.PersistentFlags().StringVar(&orgPolicyFile, "org-policy", "", "Path to an organization policy file (JSON) with IP allow list entries and settings to apply to every lab organization")
+	RetryFailedCmd.PersistentFlags().StringVar(&branchProtectionFile, "branch-protection-template", "", "Path to a branch protection file (JSON) applied to the default branch of every lab repository")
+	RetryFailedCmd.PersistentFlags().BoolVar(&enableDependabot, "enable-dependabot", false, "Enable Dependabot alerts and security updates on each organization (for new repositories) and on every lab repository")
+	RetryFailedCmd.PersistentFlags().BoolVar(&verifyInstall, "verify-install", false, "After installing the GitHub App on each organization, verify the installation is active with the expected repository selection and record it in the report")
+	RetryFailedCmd.PersistentFlags().StringVar(&preHook, "pre-hook", "", "Local command run for each user before their organization is provisioned, e.g. \"./lms-enroll.sh {{.User}} {{.Org}}\" (split on spaces, run without a shell)")
+	RetryFailedCmd.PersistentFlags().StringVar(&postHook, "post-hook", "", "Local command run for each successfully provisioned organization, e.g. \"./roster-add.sh {{.Org}} {{.URL}}\" (split on spaces, run without a shell)")
+	RetryFailedCmd.PersistentFlags().DurationVar(&hookTimeout, "hook-timeout", config.DefaultHookTimeout, "Timeout for each hook command run")
+	RetryFailedCmd.PersistentFlags().StringVar(&hookFailureMode, "hook-failure-mode", config.HookFailureSkip, "What a failed hook does: skip (a failed pre-hook skips that user's organization, a failed post-hook is only reported) or fail (fail the organization, stop starting organizations and fail the run)")
+	RetryFailedCmd.PersistentFlags().StringVar(&orgProfileTemplate, "org-profile-template", "", "Template for each organization's profile (display) name, e.g. \"GHAS Lab - {{.User}} ({{.Date}})\"; variables {{.User}}, {{.Date}} and {{.Org}}. The login is unchanged; by default the profile name is the login")
+	RetryFailedCmd.PersistentFlags().StringVar(&templateBaseURL, "template-base-url", "", "API base URL of the host the template repositories live on, when it differs from --base-url (e.g. https://api.github.com while provisioning on GHES); such templates are imported rather than generated")
+	RetryFailedCmd.PersistentFlags().BoolVar(&waitRepoReady, "wait-repo-ready", false, "Wait for each repository created from a template to have its first commit before configuring it (up to 2m)")
+}
+
+var RetryFailedCmd = &cobra.Command{
+	Use:   "retry-failed",
+	Short: "Re-provision only the organizations that failed in a previous lab report",
+	Long: `Read a lab-report-*.json written by lab create or lab apply and re-provision only the
+users whose organization failed, with the report's lab date, enterprise and facilitators.
+Retries run like lab apply: an organization created before its failure is reused and only
+what's missing is created. A fresh report covering just the retried users is written.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range retryReportFlags {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s can't be used with retry-failed; it is read from the report", name)
+			}
+		}
+		// The report supplies the users and facilitators, so the lab flags aren't required
+		for _, name := range []string{"users-file", "facilitators"} {
+			if err := cmd.Flags().SetAnnotation(name, cobra.BashCompOneRequiredFlag, []string{"false"}); err != nil {
+				return err
+			}
+		}
+
+		// Check the input files before authentication or any API call
+		report, err := labservice.LoadLabReport(retryFrom)
+		if err != nil {
+			return err
+		}
+		if orgsOnly {
+			if templateReposFile != "" || facilitatorTemplatesFile != "" {
+				return fmt.Errorf("--orgs-only can't be combined with --template-repos or --facilitator-templates")
+			}
+		} else {
+			if templateReposFile == "" {
+				return fmt.Errorf("required flag(s) \"template-repos\" not set (or pass --orgs-only)")
+			}
+			if err := util.CheckTemplateReposFile(templateReposFile); err != nil {
+				return err
+			}
+			if facilitatorTemplatesFile != "" {
+				if err := util.CheckTemplateReposFile(facilitatorTemplatesFile); err != nil {
+					return err
+				}
+			}
+		}
+		if orgPolicyFile != "" {
+			if err := util.CheckOrgPolicyFile(orgPolicyFile); err != nil {
+				return err
+			}
+		}
+		if branchProtectionFile != "" {
+			if err := util.CheckBranchProtectionFile(branchProtectionFile); err != nil {
+				return err
+			}
+		}
+
+		// The root command requires an enterprise slug; use the report's
+		if err := cmd.Flags().Set("enterprise-slug", report.EnterpriseSlug); err != nil {
+			return err
+		}
+
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		if facilitatorRole != "admin" && facilitatorRole != "member" {
+			return fmt.Errorf("invalid --facilitator-role %q: must be admin or member", facilitatorRole)
+		}
+		if orgRetries < 0 {
+			return fmt.Errorf("--org-retries cannot be negative")
+		}
+		if waitBetweenOrgs < 0 {
+			return fmt.Errorf("--wait-between-orgs cannot be negative")
+		}
+
+		if err := normalizeTemplateBaseURL(); err != nil {
+			return err
+		}
+		if err := checkOrgProfileTemplate(); err != nil {
+			return err
+		}
+		if err := checkMaxWorkers(); err != nil {
+			return err
+		}
+		preHookCommand, postHookCommand, err := parseHookFlags()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.ExcludeUsersKey, util.SplitCommaList(excludeUsers))
+		ctx = context.WithValue(ctx, config.MaxWorkersKey, maxWorkers)
+		ctx = context.WithValue(ctx, config.NoDescriptionKey, noDescription)
+		ctx = context.WithValue(ctx, config.WaitRepoReadyKey, waitRepoReady)
+		ctx = context.WithValue(ctx, config.TemplateBaseURLKey, templateBaseURL)
+		ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, orgProfileTemplate)
+		ctx = context.WithValue(ctx, config.PreHookKey, preHookCommand)
+		ctx = context.WithValue(ctx, config.PostHookKey, postHookCommand)
+		ctx = context.WithValue(ctx, config.HookTimeoutKey, hookTimeout)
+		ctx = context.WithValue(ctx, config.HookFailureModeKey, hookFailureMode)
+		ctx = context.WithValue(ctx, config.OrgRetriesKey, orgRetries)
+		ctx = context.WithValue(ctx, config.WaitBetweenOrgsKey, waitBetweenOrgs)
+		ctx = context.WithValue(ctx, config.EnableDependabotKey, enableDependabot)
+		ctx = context.WithValue(ctx, config.OrgPolicyKey, orgPolicyFile)
+		ctx = context.WithValue(ctx, config.BranchProtectionKey, branchProtectionFile)
+		ctx = context.WithValue(ctx, config.NoPreflightKey, noPreflight)
+		ctx = context.WithValue(ctx, config.ExcludeTemplatesKey, util.SplitCommaList(excludeTemplates))
+		ctx = context.WithValue(ctx, config.FacilitatorRoleKey, facilitatorRole)
+		ctx = context.WithValue(ctx, config.OrgsOnlyKey, orgsOnly)
+		ctx = context.WithValue(ctx, config.FacilitatorTemplatesKey, facilitatorTemplatesFile)
+		ctx = context.WithValue(ctx, config.VerifyInstallKey, verifyInstall)
+
+		cmd.SetContext(ctx)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok || logger == nil {
+			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+
+		return labservice.RetryFailedOrgs(ctx, logger, retryFrom, templateReposFile)
+	},
+}
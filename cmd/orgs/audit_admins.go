@@ -0,0 +1,142 @@
+package orgs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditUsersFile       string
+	auditFacilitatorRole string
+	auditAllowedAdmins   []string
+	auditOutput          string
+)
+
+func init() {
+	auditAdminsCmd.Flags().StringVar(&labDate, "lab-date", "", "Date string to identify date of the lab (e.g., '2024-06-15') (required)")
+	auditAdminsCmd.MarkFlagRequired("lab-date")
+	auditAdminsCmd.Flags().StringVar(&auditUsersFile, "users-file", "", "Path to users file (txt) listing the students whose organizations are audited (required)")
+	auditAdminsCmd.MarkFlagRequired("users-file")
+	auditAdminsCmd.Flags().StringVar(&facilitators, "facilitators", "", "Lab facilitators usernames, comma-separated, expected as admins of every organization")
+	auditAdminsCmd.Flags().StringVar(&auditFacilitatorRole, "facilitator-role", "admin", "Organization role the lab gave facilitators: admin or member (with member, facilitators aren't expected among the admins)")
+	auditAdminsCmd.Flags().StringSliceVar(&auditAllowedAdmins, "allowed-admins", nil, "Additional logins allowed to be admins without being flagged, e.g. an ops account (repeatable or comma-separated)")
+	auditAdminsCmd.Flags().StringVar(&auditOutput, "output", "", "Also write the audit results to this JSON file")
+}
+
+var auditAdminsCmd = &cobra.Command{
+	Use:   "audit-admins",
+	Short: "Audit the admins of a lab's organizations without changing anything",
+	Long: `List the current admins of each user's lab organization and flag organizations where the
+student or a facilitator isn't an admin, or where someone else is. Nothing is changed; use
+lab apply to fix the memberships. Pending invitations aren't admins yet, so an admin who
+hasn't accepted their invitation is reported as missing. Exits with an error when any
+organization is flagged, so the audit can gate a workshop in CI.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Check the users file before authenticating so path typos fail fast
+		if err := util.CheckUsersFile(auditUsersFile); err != nil {
+			return err
+		}
+
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		if auditFacilitatorRole != "admin" && auditFacilitatorRole != "member" {
+			return fmt.Errorf("invalid --facilitator-role %q: must be admin or member", auditFacilitatorRole)
+		}
+
+		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
+		cmd.SetContext(ctx)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok || logger == nil {
+			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+
+		users, err := util.LoadFromFile(auditUsersFile)
+		if err != nil {
+			return fmt.Errorf("failed to load users file: %w", err)
+		}
+
+		var facilitatorLogins []string
+		for _, login := range strings.Split(facilitators, ",") {
+			if login = strings.TrimSpace(login); login != "" {
+				facilitatorLogins = append(facilitatorLogins, login)
+			}
+		}
+
+		audits := services.AuditOrgAdmins(ctx, logger, labDate, users, facilitatorLogins, auditFacilitatorRole, auditAllowedAdmins)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ORG\tSTATUS\tADMINS\tMISSING\tUNEXPECTED")
+		flagged := 0
+		for _, audit := range audits {
+			status := "ok"
+			switch {
+			case audit.NotFound:
+				status = "not found"
+			case audit.Error != "":
+				status = "error: " + audit.Error
+			case !audit.Compliant():
+				status = "flagged"
+			}
+			if !audit.Compliant() {
+				flagged++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", audit.OrgName, status,
+				auditList(audit.Admins), auditList(audit.Missing), auditList(audit.Unexpected))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if auditOutput != "" {
+			data, err := json.MarshalIndent(audits, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal audit results: %w", err)
+			}
+			if err := os.WriteFile(auditOutput, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", auditOutput, err)
+			}
+			fmt.Printf("\nWrote %s\n", auditOutput)
+		}
+
+		if flagged > 0 {
+			return fmt.Errorf("%d of %d organization(s) failed the admin audit", flagged, len(audits))
+		}
+		fmt.Printf("\nAll %d organization(s) have the expected admins\n", len(audits))
+		return nil
+	},
+}
+
+// auditList formats logins for the audit table, with "-" for none
+func auditList(logins []string) string {
+	if len(logins) == 0 {
+		return "-"
+	}
+	return strings.Join(logins, ",")
+}
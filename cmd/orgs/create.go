@@ -9,6 +9,7 @@ import (
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +18,10 @@ var (
 	labDate        string
 	user           string
 	enterpriseSlug string
+	enterpriseID   string
+	billingEmail   string
+
+	orgProfileTemplate string
 )
 
 func init() {
@@ -28,8 +33,10 @@ func init() {
 
 	CreateCmd.PersistentFlags().StringVar(&facilitators, "facilitators", "", "Lab facilitators usernames, comma-separated (required)")
 	CreateCmd.MarkPersistentFlagRequired("facilitators")
-	CreateCmd.PersistentFlags().StringVar(&enterpriseSlug, "enterprise-slug", "", "GitHub Enterprise slug")
-	CreateCmd.MarkPersistentFlagRequired("enterprise-slug")
+	CreateCmd.PersistentFlags().StringVar(&enterpriseSlug, "enterprise-slug", "", "GitHub Enterprise slug (required) [env: GHAS_LAB_ENTERPRISE_SLUG]")
+	CreateCmd.PersistentFlags().StringVar(&enterpriseID, "enterprise-id", "", "GraphQL node ID of the enterprise; with --billing-email, skips looking the enterprise up by slug (the slug isn't validated)")
+	CreateCmd.PersistentFlags().StringVar(&billingEmail, "billing-email", "", "Enterprise billing email used for the new organization with --enterprise-id")
+	CreateCmd.PersistentFlags().StringVar(&orgProfileTemplate, "org-profile-template", "", "Template for the organization's profile (display) name, e.g. \"GHAS Lab - {{.User}} ({{.Date}})\"; variables {{.User}}, {{.Date}} and {{.Org}}. The login is unchanged; by default the profile name is the login")
 }
 
 var CreateCmd = &cobra.Command{
@@ -50,11 +57,18 @@ var CreateCmd = &cobra.Command{
 			}
 		}
 
+		if orgProfileTemplate != "" {
+			if err := util.ValidateOrgProfileTemplate(orgProfileTemplate); err != nil {
+				return fmt.Errorf("invalid --org-profile-template: %w", err)
+			}
+		}
+
 		// Add org-specific context values
 		ctx := cmd.Context()
 		ctx = context.WithValue(ctx, config.EnterpriseSlugKey, cmd.Flags().Lookup("enterprise-slug").Value.String())
 		ctx = context.WithValue(ctx, config.FacilitatorsKey, strings.Split(facilitators, ","))
 		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
+		ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, orgProfileTemplate)
 
 		cmd.SetContext(ctx)
 		return nil
@@ -67,7 +81,11 @@ var CreateCmd = &cobra.Command{
 			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
 		}
 
-		facilitators := ctx.Value(config.FacilitatorsKey).([]string)
+		facilitators, _ := ctx.Value(config.FacilitatorsKey).([]string)
+
+		if err := util.ValidateOrgLogin(util.BuildOrgLogin(labDate, user)); err != nil {
+			return fmt.Errorf("user '%s' cannot be provisioned: %w", user, err)
+		}
 
 		// Validate the user + facilitators
 		logger.Info("Validating user", slog.String("user", user))
@@ -90,7 +108,7 @@ var CreateCmd = &cobra.Command{
 
 			if len(facilitatorValidation.InvalidUsers) > 0 {
 				logger.Warn("Some facilitators are invalid and will be skipped",
-					slog.Any("invalid_facilitators", facilitatorValidation.InvalidUsers))
+					slog.Any("invalid_facilitators", api.InvalidUserNames(facilitatorValidation.InvalidUsers)))
 			}
 
 			facilitators = facilitatorValidation.ValidUsers
@@ -99,7 +117,7 @@ var CreateCmd = &cobra.Command{
 		}
 
 		enterpriseSlug := ctx.Value(config.EnterpriseSlugKey).(string)
-		enterprise, err := api.GetEnterprise(ctx, logger, enterpriseSlug)
+		enterprise, err := api.ResolveEnterprise(ctx, logger, enterpriseSlug)
 		if err != nil {
 			logger.Error("Failed to get enterprise info", slog.Any("error", err))
 			return fmt.Errorf("failed to get enterprise info: %w", err)
@@ -117,7 +135,13 @@ var CreateCmd = &cobra.Command{
 			slog.String("user", user),
 			slog.String("lab_date", labDate))
 
-		// Install app on the organization
+		// Install app on the organization once it's visible; a failed wait is left for the
+		// installation itself to report
+		if err := api.WaitForOrgVisible(ctx, logger, org.Login); err != nil {
+			logger.Warn("Installing the app before the organization is visible",
+				slog.String("org", org.Login),
+				slog.Any("error", err))
+		}
 		_, err = enterprise.InstallAppOnOrg(ctx, logger, org.Login)
 		if err != nil {
 			logger.Error("Failed to install app on organization",
@@ -2,21 +2,32 @@ package orgs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 	"github.com/spf13/cobra"
 )
 
+var (
+	deleteReposFirst      bool
+	repoDeleteConcurrency int
+)
+
 func init() {
 	DeleteCmd.Flags().StringVar(&labDate, "lab-date", "", "Date string to identify date of the lab (e.g., '2024-06-15') (required)")
 	DeleteCmd.MarkFlagRequired("lab-date")
 
 	DeleteCmd.Flags().StringVar(&user, "user", "", "User identifier for the organization (required)")
 	DeleteCmd.MarkFlagRequired("user")
+
+	DeleteCmd.Flags().BoolVar(&deleteReposFirst, "delete-repos-first", false, "Delete every repository in the organization before deleting the organization, for GHES instances where some repositories block organization deletion")
+	DeleteCmd.Flags().IntVar(&repoDeleteConcurrency, "repo-delete-concurrency", config.DefaultRepoDeleteConcurrency, "Repositories deleted at a time with --delete-repos-first")
 }
 
 var DeleteCmd = &cobra.Command{
@@ -37,8 +48,13 @@ var DeleteCmd = &cobra.Command{
 			}
 		}
 
+		if repoDeleteConcurrency < 1 {
+			return fmt.Errorf("--repo-delete-concurrency must be at least 1")
+		}
+
 		ctx := cmd.Context()
 		ctx = context.WithValue(ctx, config.LabDateKey, labDate)
+		ctx = context.WithValue(ctx, config.RepoDeleteConcurrencyKey, repoDeleteConcurrency)
 
 		cmd.SetContext(ctx)
 		return nil
@@ -52,7 +68,28 @@ var DeleteCmd = &cobra.Command{
 		}
 
 		// Build org name from lab date and user
-		orgName := fmt.Sprintf("ghas-labs-%s-%s", labDate, user)
+		orgName := util.BuildOrgLogin(labDate, user)
+
+		// A missing org usually means a typo'd user or date; report it plainly rather than
+		// as a failed deletion
+		if _, err := api.GetOrganization(ctx, logger, orgName); err != nil {
+			if errors.Is(err, api.ErrOrganizationNotFound) {
+				logger.Warn("Organization does not exist, nothing to delete", slog.String("org", orgName))
+				fmt.Printf("organization %s does not exist (nothing to delete)\n", orgName)
+				return nil
+			}
+			return fmt.Errorf("failed to look up organization: %w", err)
+		}
+
+		// Failed repository deletions are reported, but the organization deletion is still
+		// attempted
+		if deleteReposFirst {
+			repos, err := services.DeleteAllOrgRepos(ctx, logger, orgName)
+			printDeletedRepos(orgName, repos)
+			if err != nil {
+				fmt.Printf("  ❌ %v\n", err)
+			}
+		}
 
 		// Delete organization
 		err := api.DeleteOrg(ctx, logger, orgName)
@@ -71,3 +108,21 @@ var DeleteCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// printDeletedRepos prints the outcome of each --delete-repos-first repository deletion
+func printDeletedRepos(orgName string, repos []services.RepoDeleteResult) {
+	fmt.Printf("Repositories deleted before %s:\n", orgName)
+	if len(repos) == 0 {
+		fmt.Printf("  (none)\n")
+	}
+	for _, repo := range repos {
+		switch repo.Status {
+		case services.RepoDeleteStatusDeleted:
+			fmt.Printf("  ✅ %s deleted\n", repo.Name)
+		case services.RepoDeleteStatusNotFound:
+			fmt.Printf("  ⏭️ %s not found (already deleted)\n", repo.Name)
+		default:
+			fmt.Printf("  ❌ %s failed: %s\n", repo.Name, repo.Error)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package orgs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -16,7 +17,11 @@ import (
 )
 
 var (
-	orgsFile string
+	orgsFile      string
+	requirePrefix string
+	allowAnyName  bool
+	maxWorkers    int
+	stateFile     string
 )
 
 var deleteBatchCmd = &cobra.Command{
@@ -37,7 +42,14 @@ var deleteBatchCmd = &cobra.Command{
 			}
 		}
 
+		if maxWorkers < 1 || maxWorkers > config.MaxWorkersLimit {
+			return fmt.Errorf("--max-workers must be between 1 and %d", config.MaxWorkersLimit)
+		}
+
 		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.MaxWorkersKey, maxWorkers)
+		ctx = context.WithValue(ctx, config.RequirePrefixKey, requirePrefix)
+		ctx = context.WithValue(ctx, config.AllowAnyNameKey, allowAnyName)
 		cmd.SetContext(ctx)
 		return nil
 	},
@@ -65,6 +77,10 @@ var deleteBatchCmd = &cobra.Command{
 			return nil
 		}
 
+		if err := services.CheckDeletionPrefix(ctx, logger, orgNames); err != nil {
+			return err
+		}
+
 		// Initialize delete report
 		deleteReport := &services.DeleteLabReport{
 			GeneratedAt:   time.Now(),
@@ -75,6 +91,28 @@ var deleteBatchCmd = &cobra.Command{
 			Organizations: make([]services.DeleteOrgReport, 0),
 		}
 
+		// Organizations an interrupted earlier run deleted aren't attempted again
+		state, err := services.LoadDeleteState(stateFile)
+		if err != nil {
+			return err
+		}
+		remaining := make([]string, 0, len(orgNames))
+		for _, orgName := range orgNames {
+			if state.Deleted(orgName) {
+				deleteReport.Organizations = append(deleteReport.Organizations, services.ResumedOrgReport("", orgName))
+				deleteReport.ResumedCount++
+				continue
+			}
+			remaining = append(remaining, orgName)
+		}
+		if deleteReport.ResumedCount > 0 {
+			logger.Info("Resuming deletion from state file",
+				slog.String("file", stateFile),
+				slog.Int("already_deleted", deleteReport.ResumedCount),
+				slog.Int("remaining", len(remaining)))
+		}
+		orgNames = remaining
+
 		// Set up channels and workers
 		orgChan := make(chan string, len(orgNames))
 		resultsChan := make(chan services.DeleteOrgReport, len(orgNames))
@@ -82,11 +120,7 @@ var deleteBatchCmd = &cobra.Command{
 		// Use WaitGroup to track worker goroutines
 		var wg sync.WaitGroup
 
-		// Calculate optimal number of workers: min(9, number of orgs)
-		numWorkers := 9
-		if len(orgNames) < numWorkers {
-			numWorkers = len(orgNames)
-		}
+		numWorkers := services.WorkerCount(ctx, len(orgNames))
 
 		logger.Info("Starting delete workers",
 			slog.Int("worker_count", numWorkers),
@@ -129,6 +163,9 @@ var deleteBatchCmd = &cobra.Command{
 					slog.String("org", res.OrgName),
 					slog.String("error", res.Error))
 			}
+			if err := state.Record(res); err != nil {
+				logger.Warn("Failed to update state file", slog.String("file", stateFile), slog.Any("error", err))
+			}
 		}
 
 		duration := time.Since(startTime)
@@ -138,18 +175,19 @@ var deleteBatchCmd = &cobra.Command{
 			slog.Int("failed", deleteReport.FailureCount),
 			slog.Duration("duration", duration))
 
-		// Generate report
-		if err := services.GenerateDeleteReportFiles(deleteReport, "reports"); err != nil {
-			logger.Error("Failed to generate deletion report", slog.Any("error", err))
-		} else {
-			logger.Info("Generated deletion report in 'reports' directory")
+		var runErr error
+		if deleteReport.FailureCount > 0 {
+			runErr = fmt.Errorf("failed to delete %d organization(s)", deleteReport.FailureCount)
 		}
 
-		if deleteReport.FailureCount > 0 {
-			return fmt.Errorf("failed to delete %d organization(s)", deleteReport.FailureCount)
+		// Generate report
+		reportOpts := services.ReportOptionsFromContext(ctx)
+		reportErr := services.GenerateDeleteReportFiles(deleteReport, reportOpts)
+		if reportErr == nil {
+			logger.Info("Generated deletion report in 'reports' directory")
 		}
 
-		return nil
+		return services.ResolveRunError(logger, reportOpts, runErr, reportErr)
 	},
 }
 
@@ -178,7 +216,13 @@ func deleteOrgBatchWorker(workerId int, ctx context.Context, logger *slog.Logger
 		}
 
 		// Delete the organization
-		if err := api.DeleteOrg(ctx, logger, orgName); err != nil {
+		err := api.DeleteOrg(ctx, logger, orgName)
+		if errors.Is(err, api.ErrOrganizationNotFound) {
+			logger.Info("Organization already deleted", slog.String("org", orgName))
+			orgReport.AlreadyDeleted = true
+			err = nil
+		}
+		if err != nil {
 			logger.Error("Failed to delete organization",
 				slog.Int("workerId", workerId),
 				slog.String("org", orgName),
@@ -203,6 +247,10 @@ func deleteOrgBatchWorker(workerId int, ctx context.Context, logger *slog.Logger
 func init() {
 	deleteBatchCmd.Flags().StringVar(&orgsFile, "orgs-file", "", "Path to organizations file (txt) containing comma-separated org names (required)")
 	deleteBatchCmd.MarkFlagRequired("orgs-file")
+	deleteBatchCmd.Flags().StringVar(&requirePrefix, "require-prefix", util.OrgLoginPrefix, "Refuse to delete any organization whose login doesn't start with this prefix")
+	deleteBatchCmd.Flags().BoolVar(&allowAnyName, "allow-any-name", false, "Disable the --require-prefix guard and delete organizations with any name")
+	deleteBatchCmd.Flags().StringVar(&stateFile, "state-file", "", "Record each organization's deletion in this file and skip the ones it records as deleted, to resume an interrupted deletion")
+	deleteBatchCmd.Flags().IntVar(&maxWorkers, "max-workers", config.DefaultMaxWorkers, fmt.Sprintf("Number of organizations deleted concurrently (1-%d)", config.MaxWorkersLimit))
 
 	OrgsCmd.AddCommand(deleteBatchCmd)
 }
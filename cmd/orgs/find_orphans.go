@@ -0,0 +1,99 @@
+package orgs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportsDir       string
+	orphanPrefix     string
+	orphansOutput    string
+	orphanEnterprise string
+)
+
+func init() {
+	findOrphansCmd.Flags().StringVar(&orphanEnterprise, "enterprise-slug", "", "GitHub Enterprise slug (required) [env: GHAS_LAB_ENTERPRISE_SLUG]")
+	findOrphansCmd.Flags().StringVar(&reportsDir, "reports-dir", "reports", "Directory containing the lab reports to cross-reference")
+	findOrphansCmd.Flags().StringVar(&orphanPrefix, "prefix", util.OrgLoginPrefix, "Only consider organizations whose login starts with this prefix")
+	findOrphansCmd.Flags().StringVar(&orphansOutput, "output", "", "Also write the orphaned logins to this file (txt) in the format accepted by delete-batch --orgs-file")
+}
+
+var findOrphansCmd = &cobra.Command{
+	Use:   "find-orphans",
+	Short: "List lab organizations that don't appear in any report",
+	Long: `List enterprise organizations matching the lab prefix that aren't mentioned in any report in
+--reports-dir. These are usually left over from crashed runs and are candidates for cleanup
+with delete-batch. Review the list first: reports that were moved or deleted make their
+organizations look orphaned.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		if orphanPrefix == "" {
+			return fmt.Errorf("--prefix cannot be empty")
+		}
+
+		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.EnterpriseSlugKey, orphanEnterprise)
+		cmd.SetContext(ctx)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok || logger == nil {
+			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+
+		orphans, err := services.FindOrphanedOrgs(ctx, logger, orphanEnterprise, reportsDir, orphanPrefix)
+		if err != nil {
+			return err
+		}
+
+		if len(orphans) == 0 {
+			fmt.Printf("No orphaned organizations starting with %q\n", orphanPrefix)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LOGIN\tNAME")
+		logins := make([]string, 0, len(orphans))
+		for _, org := range orphans {
+			fmt.Fprintf(w, "%s\t%s\n", org.Login, org.Name)
+			logins = append(logins, org.Login)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d orphaned organization(s)\n", len(orphans))
+
+		if orphansOutput != "" {
+			if err := os.WriteFile(orphansOutput, []byte(strings.Join(logins, ",\n")+"\n"), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", orphansOutput, err)
+			}
+			fmt.Printf("Wrote %s (review it before running delete-batch --orgs-file %s)\n", orphansOutput, orphansOutput)
+		}
+		return nil
+	},
+}
@@ -15,4 +15,6 @@ func init() {
 	OrgsCmd.AddCommand(CreateCmd)
 	OrgsCmd.AddCommand(DeleteCmd)
 	OrgsCmd.AddCommand(deleteBatchCmd)
+	OrgsCmd.AddCommand(findOrphansCmd)
+	OrgsCmd.AddCommand(auditAdminsCmd)
 }
@@ -2,27 +2,42 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	reposervice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 	"github.com/spf13/cobra"
 )
 
 var (
-	repos string
+	repos           string
+	noDescription   bool
+	waitRepoReady   bool
+	reposOutput     string
+	templateBaseURL string
 )
 
 func init() {
 	CreateCmd.PersistentFlags().StringVar(&repos, "repos", "", "Path to template repositories file (JSON) (required)")
 	CreateCmd.MarkPersistentFlagRequired("repos")
+	CreateCmd.PersistentFlags().BoolVar(&noDescription, "no-description", false, "Create repositories with an empty description unless the template repos file sets one")
+	CreateCmd.PersistentFlags().StringVar(&reposOutput, "repos-output", "", "Write the repositories this run created (org, repo and template) to this JSON file, for repo delete --created-repos")
+	CreateCmd.PersistentFlags().StringVar(&templateBaseURL, "template-base-url", "", "API base URL of the host the template repositories live on, when it differs from --base-url (e.g. https://api.github.com while provisioning on GHES); such templates are imported rather than generated")
+	CreateCmd.PersistentFlags().BoolVar(&waitRepoReady, "wait-repo-ready", false, "Wait for each repository created from a template to have its first commit before configuring it (up to 2m)")
 }
 
 var CreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create repositories within a lab environment",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Check the input file before authentication or any API call
+		if err := util.CheckTemplateReposFile(repos); err != nil {
+			return err
+		}
+
 		// Traverse up to find and call the root command's PersistentPreRunE
 		root := cmd
 		for root.Parent() != nil {
@@ -36,9 +51,25 @@ var CreateCmd = &cobra.Command{
 			}
 		}
 
+		if err := requireOrg(); err != nil {
+			return err
+		}
+
+		if templateBaseURL != "" {
+			normalized, err := util.NormalizeBaseURL(templateBaseURL)
+			if err != nil {
+				return fmt.Errorf("invalid --template-base-url: %w", err)
+			}
+			templateBaseURL = normalized
+		}
+
 		ctx := cmd.Context()
 
 		ctx = context.WithValue(ctx, config.OrgKey, org)
+		ctx = context.WithValue(ctx, config.NoDescriptionKey, noDescription)
+		ctx = context.WithValue(ctx, config.WaitRepoReadyKey, waitRepoReady)
+		ctx = context.WithValue(ctx, config.TemplateBaseURLKey, templateBaseURL)
+		ctx = context.WithValue(ctx, config.ReposOutputKey, reposOutput)
 
 		cmd.SetContext(ctx)
 		return nil
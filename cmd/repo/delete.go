@@ -2,9 +2,9 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
-	"strings"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	reposervice "github.com/s-samadi/ghas-lab-builder/internal/services"
@@ -13,17 +13,27 @@ import (
 )
 
 var (
-	deleteRepos string
+	deleteRepos  string
+	createdRepos string
 )
 
 func init() {
 	DeleteCmd.PersistentFlags().StringVar(&deleteRepos, "repos", "", "Path to file containing repository names to delete (JSON). If empty, all repos in the org will be deleted")
+	DeleteCmd.PersistentFlags().StringVar(&createdRepos, "created-repos", "", "Path to a file written by repo create --repos-output; delete exactly the repositories it lists for --org")
 }
 
 var DeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete repositories within a lab environment",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if createdRepos != "" {
+			if deleteRepos != "" {
+				return fmt.Errorf("--repos and --created-repos are mutually exclusive")
+			}
+			if err := util.CheckCreatedReposFile(createdRepos); err != nil {
+				return err
+			}
+		}
 
 		root := cmd
 		for root.Parent() != nil {
@@ -35,6 +45,10 @@ var DeleteCmd = &cobra.Command{
 				return err
 			}
 		}
+		if err := requireOrg(); err != nil {
+			return err
+		}
+
 		ctx := cmd.Context()
 		ctx = context.WithValue(ctx, config.OrgKey, org)
 		cmd.SetContext(ctx)
@@ -50,7 +64,21 @@ var DeleteCmd = &cobra.Command{
 
 		var repoNames []string
 
-		if deleteRepos != "" {
+		if createdRepos != "" {
+			created, err := util.LoadCreatedReposFile(createdRepos)
+			if err != nil {
+				return err
+			}
+			for _, repo := range created {
+				if repo.Org == org {
+					repoNames = append(repoNames, repo.Repo)
+				}
+			}
+			// An empty list would delete every repository in the org
+			if len(repoNames) == 0 {
+				return fmt.Errorf("created repos file %s lists no repositories for org %s", createdRepos, org)
+			}
+		} else if deleteRepos != "" {
 			repoConfigs, err := util.LoadFromJsonFile(deleteRepos)
 			if err != nil {
 				logger.Error("Failed to load repository names",
@@ -60,20 +88,45 @@ var DeleteCmd = &cobra.Command{
 			}
 
 			repoNames = make([]string, len(repoConfigs))
-			for i, config := range repoConfigs {
-
-				parts := strings.Split(config.Template, "/")
-				if len(parts) == 2 {
-					repoNames[i] = parts[1]
-				} else {
-					repoNames[i] = config.Template
+			for i, repoConfig := range repoConfigs {
+				repoConfig, err := repoConfig.Expand(util.RepoTemplateVars{Org: org})
+				if err != nil {
+					return err
 				}
+				repoNames[i] = repoConfig.RepoName()
 			}
 		} else {
 			logger.Info("No repos file specified, will delete all repositories in the organization")
 			repoNames = nil
 		}
 
-		return reposervice.DeleteReposInLabOrg(ctx, logger, repoNames)
+		results, err := reposervice.DeleteReposInLabOrg(ctx, logger, repoNames)
+		printRepoDeleteResults(results)
+		return err
 	},
 }
+
+// printRepoDeleteResults prints the outcome of each repository deletion
+func printRepoDeleteResults(results []reposervice.RepoDeleteResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	counts := map[string]int{}
+	fmt.Printf("\nRepository deletion in %s:\n", org)
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case reposervice.RepoDeleteStatusDeleted:
+			fmt.Printf("  ✅ %s deleted\n", result.Name)
+		case reposervice.RepoDeleteStatusNotFound:
+			fmt.Printf("  ⏭️ %s not found (already deleted)\n", result.Name)
+		default:
+			fmt.Printf("  ❌ %s failed: %s\n", result.Name, result.Error)
+		}
+	}
+	fmt.Printf("%d deleted, %d not found, %d failed\n",
+		counts[reposervice.RepoDeleteStatusDeleted],
+		counts[reposervice.RepoDeleteStatusNotFound],
+		counts[reposervice.RepoDeleteStatusFailed])
+}
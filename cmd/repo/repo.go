@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +19,16 @@ var RepoCmd = &cobra.Command{
 func init() {
 	RepoCmd.AddCommand(CreateCmd)
 	RepoCmd.AddCommand(DeleteCmd)
+	RepoCmd.AddCommand(SchemaCmd)
+	RepoCmd.AddCommand(TransferCmd)
+
+	RepoCmd.PersistentFlags().StringVar(&org, "org", "", "Login of the target organization; any existing org the credentials can access (required)")
+}
 
-	RepoCmd.PersistentFlags().StringVar(&org, "org", "", "Organization name for the lab repositories (required)")
-	RepoCmd.MarkPersistentFlagRequired("org")
+// requireOrg enforces --org for subcommands that act on an organization
+func requireOrg() error {
+	if org == "" {
+		return fmt.Errorf("required flag(s) \"org\" not set")
+	}
+	return nil
 }
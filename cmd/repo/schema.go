@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"fmt"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var SchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON schema accepted for template repositories files",
+	Long:  "Print the JSON schema for the file passed to --repos/--template-repos so authors know exactly which per-repo fields are supported.",
+	// Printing the schema needs no authentication, so skip the root pre-run checks
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), util.RepoConfigJSONSchema)
+		return err
+	},
+}
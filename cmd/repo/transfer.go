@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	reposervice "github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	transferRepo string
+	transferTo   string
+)
+
+func init() {
+	TransferCmd.Flags().StringVar(&transferRepo, "repo", "", "Name of the repository in --org to transfer (required)")
+	TransferCmd.MarkFlagRequired("repo")
+	TransferCmd.Flags().StringVar(&transferTo, "to", "", "Login of the organization or user to transfer the repository to (required)")
+	TransferCmd.MarkFlagRequired("to")
+}
+
+var TransferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Transfer a repository to another organization",
+	Long:  "Transfer a repository from --org to another owner, e.g. to collect student submissions in a central organization.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Traverse up to find and call the root command's PersistentPreRunE
+		root := cmd
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+		// Call root's PersistentPreRunE if it exists
+		if root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		}
+
+		if err := requireOrg(); err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		ctx = context.WithValue(ctx, config.OrgKey, org)
+		cmd.SetContext(ctx)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		logger, ok := ctx.Value(config.LoggerKey).(*slog.Logger)
+		if !ok || logger == nil {
+			logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+
+		status, err := reposervice.TransferRepoFromLabOrg(ctx, logger, transferRepo, transferTo)
+		if err != nil {
+			return err
+		}
+
+		if status == reposervice.TransferStatusCompleted {
+			fmt.Printf("✅ Transferred %s/%s to %s\n", org, transferRepo, transferTo)
+		} else {
+			fmt.Printf("⏳ Transfer of %s/%s to %s is pending: it may still be processing, or %s may need to accept it\n", org, transferRepo, transferTo, transferTo)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,90 @@
+package reports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportsDir string
+	olderThan  string
+	dryRun     bool
+)
+
+func init() {
+	pruneCmd.Flags().StringVar(&reportsDir, "reports-dir", "reports", "Directory containing the report files")
+	pruneCmd.Flags().StringVar(&olderThan, "older-than", "", "Delete reports generated longer ago than this, in days (e.g. 30d) or as a duration (e.g. 12h) (required)")
+	pruneCmd.MarkFlagRequired("older-than")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the reports that would be deleted without deleting them")
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete report files older than a retention window",
+	Long: `Delete the lab, deletion and cohort report files in --reports-dir whose file name timestamp
+(e.g. lab-report-2025-11-07-20251107-093000.md) is older than --older-than, so the directory
+doesn't grow without bound on long-lived runners. Reports written with --no-timestamp and
+other files are left alone.`,
+	// Pruning local files needs no authentication, so skip the root pre-run checks
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		retention, err := parseRetention(olderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+
+		result, pruneErr := services.PruneReports(reportsDir, retention, time.Now(), dryRun)
+		if result == nil {
+			return pruneErr
+		}
+
+		out := cmd.OutOrStdout()
+		for _, report := range result.Pruned {
+			switch {
+			case dryRun:
+				fmt.Fprintf(out, "  would delete %s (%s)\n", report.Path, report.GeneratedAt.Format("2006-01-02 15:04"))
+			case report.Error != "":
+				fmt.Fprintf(out, "  ❌ %s: %s\n", report.Path, report.Error)
+			default:
+				fmt.Fprintf(out, "  🗑️ deleted %s (%s)\n", report.Path, report.GeneratedAt.Format("2006-01-02 15:04"))
+			}
+		}
+
+		verb := "Deleted"
+		if dryRun {
+			verb = "Would delete"
+		}
+		fmt.Fprintf(out, "%s %d report file(s) older than %s; kept %d, skipped %d other file(s)\n",
+			verb, len(result.Pruned), olderThan, result.Kept, result.Skipped)
+		return pruneErr
+	},
+}
+
+// parseRetention parses --older-than as a number of days ("30d") or a Go duration ("12h")
+func parseRetention(value string) (time.Duration, error) {
+	var retention time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number of days", value)
+		}
+		retention = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("%q is neither a number of days (e.g. 30d) nor a duration (e.g. 12h)", value)
+		}
+		retention = d
+	}
+	if retention <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return retention, nil
+}
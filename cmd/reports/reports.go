@@ -0,0 +1,15 @@
+package reports
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ReportsCmd = &cobra.Command{
+	Use:   "reports",
+	Short: "Maintain the report files written by lab runs",
+	Long:  "The 'reports' command lets you maintain the reports directory that lab runs write to.",
+}
+
+func init() {
+	ReportsCmd.AddCommand(pruneCmd)
+}
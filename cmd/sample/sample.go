@@ -0,0 +1,60 @@
+package sample
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputDir string
+	force     bool
+)
+
+var InitCmd = &cobra.Command{
+	Use:     "init",
+	Aliases: []string{"sample"},
+	Short:   "Write example users and template repositories files",
+	Long: `Write an example users.txt and repos.json to the current directory (or --dir) showing the
+exact formats accepted by --users-file and --template-repos/--repos. Existing files are left
+untouched unless --force is set.`,
+	// Writing the sample files needs no authentication, so skip the root pre-run checks
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usersPath := filepath.Join(outputDir, "users.txt")
+		reposPath := filepath.Join(outputDir, "repos.json")
+
+		if !force {
+			for _, path := range []string{usersPath, reposPath} {
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+				}
+			}
+		}
+
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := os.WriteFile(usersPath, []byte(util.SampleUsersFile), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", usersPath, err)
+		}
+		if err := os.WriteFile(reposPath, []byte(util.SampleTemplateReposFile), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", reposPath, err)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "✅ Wrote %s and %s\n\n", usersPath, reposPath)
+		fmt.Fprintf(out, util.SampleFilesGuide, usersPath, reposPath)
+		return nil
+	},
+}
+
+func init() {
+	InitCmd.Flags().StringVar(&outputDir, "dir", ".", "Directory to write the sample files to")
+	InitCmd.Flags().BoolVar(&force, "force", false, "Overwrite existing users.txt and repos.json")
+}
@@ -5,9 +5,7 @@ go 1.25.2
 require (
 	github.com/golang-jwt/jwt/v4 v4.5.2
 	github.com/spf13/cobra v1.10.1
+	github.com/spf13/pflag v1.0.10
 )
 
-require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.10 // indirect
-)
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -5,14 +5,25 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v4"
 )
 
+// ErrNoInstallationForOrg is returned when the GitHub App is not installed on the requested organization
+var ErrNoInstallationForOrg = errors.New("no installation found for organization")
+
+// errJWTRejected is returned when GitHub answers an app JWT with 401, which happens when
+// the key that signed it has been revoked
+var errJWTRejected = errors.New("GitHub rejected the app JWT")
+
 type InstallationTokenInfo struct {
 	Token     string `json:"token"`
 	ExpiresAt string `json:"expires_at"`
@@ -22,9 +33,15 @@ type InstallationTokenInfo struct {
 
 // TokenService handles GitHub App authentication
 type TokenService struct {
-	appID      string
+	appID     string
+	baseURL   string
+	keyFormat string
+	transport http.RoundTripper
+
+	// keyMu guards privateKey, which ReloadKey replaces when the key file is rotated
+	keyMu      sync.RWMutex
 	privateKey string
-	baseURL    string
+	keyFile    string
 }
 
 // Installation represents a GitHub App installation
@@ -33,8 +50,11 @@ type Installation struct {
 	Account struct {
 		Login string `json:"login"`
 	} `json:"account"`
-	TargetType string `json:"target_type"`
-	ClientID   string `json:"client_id"`
+	TargetType          string `json:"target_type"`
+	ClientID            string `json:"client_id"`
+	RepositorySelection string `json:"repository_selection"`
+	// SuspendedAt is set when the installation is suspended
+	SuspendedAt *time.Time `json:"suspended_at"`
 }
 
 // InstallationToken represents the response from the installation token endpoint
@@ -52,32 +72,69 @@ func NewTokenService(appID, privateKey, baseURL string) *TokenService {
 	}
 }
 
-// CreateJWT generates a JWT for GitHub App authentication
-func (ts *TokenService) CreateJWT() (string, error) {
+// Supported values for the private key format expectation
+const (
+	KeyFormatAuto  = "auto"
+	KeyFormatPKCS1 = "pkcs1"
+	KeyFormatPKCS8 = "pkcs8"
+)
 
-	privateKeyData := []byte(ts.privateKey)
-	block, _ := pem.Decode(privateKeyData)
-	if block == nil {
-		return "", fmt.Errorf("failed to decode PEM block from private key")
-	}
+// WithKeyFormat restricts which PKCS encoding CreateJWT accepts for the private key.
+// An empty format or KeyFormatAuto accepts either PKCS#1 or PKCS#8.
+func (ts *TokenService) WithKeyFormat(format string) *TokenService {
+	ts.keyFormat = format
+	return ts
+}
 
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+// WithKeyFile records the file the private key was read from so ReloadKey can pick up a
+// rotated key. Without a key file ReloadKey is a no-op.
+func (ts *TokenService) WithKeyFile(path string) *TokenService {
+	ts.keyFile = path
+	return ts
+}
+
+// ReloadKey re-reads the private key from the key file. It does nothing when the key
+// was passed inline. The current key is kept if the file can't be read.
+func (ts *TokenService) ReloadKey() error {
+	if ts.keyFile == "" {
+		return nil
+	}
+	keyData, err := os.ReadFile(ts.keyFile)
 	if err != nil {
-		type InstallationTokenInfo struct {
-			Token     string `json:"token"`
-			ExpiresAt string `json:"expires_at"`
-			ClientID  string `json:"client_id"`
-			AppID     string `json:"app_id"`
-		}
-		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-		if err != nil {
-			return "", fmt.Errorf("failed to parse private key: %w", err)
-		}
-		var ok bool
-		privateKey, ok = key.(*rsa.PrivateKey)
-		if !ok {
-			return "", fmt.Errorf("not an RSA private key")
+		return fmt.Errorf("failed to reload private key file: %w", err)
+	}
+
+	ts.keyMu.Lock()
+	defer ts.keyMu.Unlock()
+	ts.privateKey = strings.TrimSpace(string(keyData))
+	return nil
+}
+
+// WithTransport sets the transport used for token and installation requests. A nil
+// transport uses http.DefaultTransport.
+func (ts *TokenService) WithTransport(transport http.RoundTripper) *TokenService {
+	ts.transport = transport
+	return ts
+}
+
+// CreateJWT generates a JWT for GitHub App authentication. If the key can't be used and
+// it came from a key file, the file is reloaded once in case the key was rotated.
+func (ts *TokenService) CreateJWT() (string, error) {
+	tokenString, err := ts.signJWT()
+	if err != nil && ts.keyFile != "" {
+		if reloadErr := ts.ReloadKey(); reloadErr != nil {
+			return "", errors.Join(err, reloadErr)
 		}
+		return ts.signJWT()
+	}
+	return tokenString, err
+}
+
+// signJWT signs a JWT with the current private key
+func (ts *TokenService) signJWT() (string, error) {
+	privateKey, err := ts.parsePrivateKey()
+	if err != nil {
+		return "", err
 	}
 
 	// Create the JWT claims
@@ -100,6 +157,66 @@ func (ts *TokenService) CreateJWT() (string, error) {
 	return tokenString, nil
 }
 
+// parsePrivateKey decodes the PEM private key, honouring the configured key format
+// and returning targeted errors for key types GitHub Apps cannot use
+func (ts *TokenService) parsePrivateKey() (*rsa.PrivateKey, error) {
+	ts.keyMu.RLock()
+	pemKey := ts.privateKey
+	ts.keyMu.RUnlock()
+
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		if !strings.Contains(pemKey, "-----BEGIN") {
+			return nil, fmt.Errorf("failed to decode PEM block from private key: no PEM header found, --private-key expects the PEM content of the key")
+		}
+		return nil, fmt.Errorf("failed to decode PEM block from private key")
+	}
+
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		return nil, fmt.Errorf("found OPENSSH PRIVATE KEY; convert to PKCS#8 PEM (e.g. 'ssh-keygen -p -m PKCS8 -f <key>')")
+	case "EC PRIVATE KEY":
+		return nil, fmt.Errorf("found EC PRIVATE KEY; only RSA keys are supported by GitHub Apps")
+	case "ENCRYPTED PRIVATE KEY":
+		return nil, fmt.Errorf("found ENCRYPTED PRIVATE KEY; decrypt the key before use")
+	}
+
+	format := ts.keyFormat
+	if format == "" {
+		format = KeyFormatAuto
+	}
+
+	switch format {
+	case KeyFormatPKCS1:
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key as PKCS#1 (PEM type %q): %w", block.Type, err)
+		}
+		return key, nil
+	case KeyFormatPKCS8:
+		return parsePKCS8RSAKey(block)
+	case KeyFormatAuto:
+		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return key, nil
+		}
+		return parsePKCS8RSAKey(block)
+	default:
+		return nil, fmt.Errorf("unsupported private key format %q, expected one of %s, %s, %s", format, KeyFormatAuto, KeyFormatPKCS1, KeyFormatPKCS8)
+	}
+}
+
+func parsePKCS8RSAKey(block *pem.Block) (*rsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key as PKCS#8 (PEM type %q): %w", block.Type, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("found %T in PKCS#8 private key; only RSA keys are supported by GitHub Apps", key)
+	}
+	return rsaKey, nil
+}
+
 // GetInstallations retrieves all installations for the GitHub App
 func (ts *TokenService) GetInstallations(jwt string) ([]Installation, error) {
 	var allInstallations []Installation
@@ -118,7 +235,7 @@ func (ts *TokenService) GetInstallations(jwt string) ([]Installation, error) {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
 		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
-		client := &http.Client{Timeout: 30 * time.Second}
+		client := &http.Client{Timeout: 30 * time.Second, Transport: ts.transport}
 		resp, err := client.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get installations: %w", err)
@@ -127,6 +244,9 @@ func (ts *TokenService) GetInstallations(jwt string) ([]Installation, error) {
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
+			if resp.StatusCode == http.StatusUnauthorized {
+				return nil, fmt.Errorf("%w: %s", errJWTRejected, string(body))
+			}
 			return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
 		}
 
@@ -168,7 +288,7 @@ func (ts *TokenService) CreateInstallationToken(jwt string, installationID int64
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: ts.transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create installation token: %w", err)
@@ -188,19 +308,37 @@ func (ts *TokenService) CreateInstallationToken(jwt string, installationID int64
 	return &token, nil
 }
 
-// GetInstallationToken is a convenience method that creates a JWT and exchanges it for an installation token
-// This is what is used in the application code
-func (ts *TokenService) GetInstallationToken(tokenType string) (InstallationTokenInfo, error) {
-	// Create JWT
+// appInstallations creates a JWT and lists the app's installations with it. When GitHub
+// rejects the JWT and the key came from a key file, the key is reloaded and the listing
+// retried once, so a key rotated on disk is picked up without a restart.
+func (ts *TokenService) appInstallations() (string, []Installation, error) {
 	jwt, err := ts.CreateJWT()
 	if err != nil {
-		return InstallationTokenInfo{}, fmt.Errorf("failed to create JWT: %w", err)
+		return "", nil, fmt.Errorf("failed to create JWT: %w", err)
 	}
 
-	// Get installations
 	installations, err := ts.GetInstallations(jwt)
+	if errors.Is(err, errJWTRejected) && ts.keyFile != "" {
+		if reloadErr := ts.ReloadKey(); reloadErr != nil {
+			return "", nil, fmt.Errorf("failed to get installations: %w", errors.Join(err, reloadErr))
+		}
+		if jwt, err = ts.CreateJWT(); err != nil {
+			return "", nil, fmt.Errorf("failed to create JWT: %w", err)
+		}
+		installations, err = ts.GetInstallations(jwt)
+	}
 	if err != nil {
-		return InstallationTokenInfo{}, fmt.Errorf("failed to get installations: %w", err)
+		return "", nil, fmt.Errorf("failed to get installations: %w", err)
+	}
+	return jwt, installations, nil
+}
+
+// GetInstallationToken is a convenience method that creates a JWT and exchanges it for an installation token
+// This is what is used in the application code
+func (ts *TokenService) GetInstallationToken(tokenType string) (InstallationTokenInfo, error) {
+	jwt, installations, err := ts.appInstallations()
+	if err != nil {
+		return InstallationTokenInfo{}, err
 	}
 
 	if len(installations) == 0 {
@@ -238,23 +376,20 @@ func (ts *TokenService) GetInstallationToken(tokenType string) (InstallationToke
 
 // GetInstallationTokenForOrg gets an installation token for a specific organization
 func (ts *TokenService) GetInstallationTokenForOrg(orgLogin string) (string, error) {
-	jwt, err := ts.CreateJWT()
-	if err != nil {
-		return "", fmt.Errorf("failed to create JWT: %w", err)
-	}
-	installations, err := ts.GetInstallations(jwt)
+	jwt, installations, err := ts.appInstallations()
 	if err != nil {
-		return "", fmt.Errorf("failed to get installations: %w", err)
+		return "", err
 	}
 	var installationID int64
 	for _, installation := range installations {
-		if installation.Account.Login == orgLogin {
+		// Logins are case-insensitive, matching the preflight in CheckAppInstalledOnOrg
+		if strings.EqualFold(installation.Account.Login, orgLogin) {
 			installationID = installation.ID
 			break
 		}
 	}
 	if installationID == 0 {
-		return "", fmt.Errorf("no installation found for organization: %s", orgLogin)
+		return "", fmt.Errorf("%w: %s", ErrNoInstallationForOrg, orgLogin)
 	}
 
 	// Create installation token
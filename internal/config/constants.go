@@ -1,18 +1,84 @@
 package config
 
+import "time"
+
 type contextKey string
 
 const (
-	TokenKey          contextKey = "token"
-	AppIDKey          contextKey = "app-id"
-	PrivateKeyKey     contextKey = "private-key"
-	BaseURLKey        contextKey = "base-url"
-	EnterpriseSlugKey contextKey = "enterprise-slug"
-	LabDateKey        contextKey = "lab-date"
-	FacilitatorsKey   contextKey = "facilitators"
-	LoggerKey         contextKey = "logger"
-	OrgKey            contextKey = "org"
-	UsersFileKey      contextKey = "users-file"
+	TokenKey                  contextKey = "token"
+	AppIDKey                  contextKey = "app-id"
+	PrivateKeyKey             contextKey = "private-key"
+	BaseURLKey                contextKey = "base-url"
+	EnterpriseSlugKey         contextKey = "enterprise-slug"
+	LabDateKey                contextKey = "lab-date"
+	FacilitatorsKey           contextKey = "facilitators"
+	LoggerKey                 contextKey = "logger"
+	OrgKey                    contextKey = "org"
+	UsersFileKey              contextKey = "users-file"
+	MinConcurrencyKey         contextKey = "min-concurrency"
+	MaxConcurrencyKey         contextKey = "max-concurrency"
+	PrivateKeyFormatKey       contextKey = "private-key-format"
+	OnlyUsersKey              contextKey = "only-users"
+	ExcludeUsersKey           contextKey = "exclude-users"
+	ReportNoTimestampKey      contextKey = "no-timestamp"
+	StrictReportsKey          contextKey = "strict-reports"
+	HTTPTraceKey              contextKey = "http-trace"
+	InviteToEnterpriseKey     contextKey = "invite-to-enterprise"
+	NoEnterpriseCacheKey      contextKey = "no-enterprise-cache"
+	RequirePrefixKey          contextKey = "require-prefix"
+	AllowAnyNameKey           contextKey = "allow-any-name"
+	ReportInvalidDetailsKey   contextKey = "report-include-invalid-details"
+	OrgCreateTimeoutKey       contextKey = "org-create-timeout"
+	OrgDeleteTimeoutKey       contextKey = "org-delete-timeout"
+	CommentOnKey              contextKey = "comment-on"
+	OrgCreateIntervalKey      contextKey = "org-create-interval"
+	NoDescriptionKey          contextKey = "no-description"
+	MaxBodyLogBytesKey        contextKey = "max-body-log-bytes"
+	FacilitatorsAdminsOnlyKey contextKey = "facilitators-as-admins-only"
+	FacilitatorRoleKey        contextKey = "facilitator-role"
+	ApplyModeKey              contextKey = "apply-mode"
+	ActionsMatrixOutputKey    contextKey = "actions-matrix-output"
+	WaitRepoReadyKey          contextKey = "wait-repo-ready"
+	ReportSinksKey            contextKey = "report-sink"
+	ReportUploadKey           contextKey = "report-upload"
+	ReportWebhookURLKey       contextKey = "report-webhook-url"
+	ReportSlackWebhookURLKey  contextKey = "report-slack-webhook-url"
+	ValidationConcurrencyKey  contextKey = "validation-concurrency"
+	ExcludeTemplatesKey       contextKey = "exclude-templates"
+	PreserveUsersKey          contextKey = "preserve-users"
+	OrgRetriesKey             contextKey = "org-retries"
+	MaxConnsKey               contextKey = "max-conns"
+	DiscoverOrgsKey           contextKey = "discover"
+	PrivateKeyFileKey         contextKey = "private-key-file"
+	OrgsOnlyKey               contextKey = "orgs-only"
+	PrintQueryKey             contextKey = "print-query"
+	FacilitatorTemplatesKey   contextKey = "facilitator-templates"
+	VerifyInstallKey          contextKey = "verify-install"
+	SharedRepoKey             contextKey = "shared-repo"
+	SharedRepoOrgKey          contextKey = "shared-repo-org"
+	WaitBetweenOrgsKey        contextKey = "wait-between-orgs"
+	RequireAllValidKey        contextKey = "require-all-valid"
+	EnableDependabotKey       contextKey = "enable-dependabot"
+	OrgPolicyKey              contextKey = "org-policy"
+	ReposOutputKey            contextKey = "repos-output"
+	NoPreflightKey            contextKey = "no-preflight"
+	EnterpriseOrgLimitKey     contextKey = "enterprise-org-limit"
+	TemplateBaseURLKey        contextKey = "template-base-url"
+	OrgProfileTemplateKey     contextKey = "org-profile-template"
+	PreHookKey                contextKey = "pre-hook"
+	PostHookKey               contextKey = "post-hook"
+	HookTimeoutKey            contextKey = "hook-timeout"
+	HookFailureModeKey        contextKey = "hook-failure-mode"
+	DeleteReposFirstKey       contextKey = "delete-repos-first"
+	RepoDeleteConcurrencyKey  contextKey = "repo-delete-concurrency"
+	EnterpriseIDKey           contextKey = "enterprise-id"
+	BillingEmailKey           contextKey = "billing-email"
+	OrgAllowlistKey           contextKey = "org-allowlist"
+	RunIDKey                  contextKey = "run-id"
+	RunIDHeaderKey            contextKey = "run-id-header"
+	MaxWorkersKey             contextKey = "max-workers"
+	BranchProtectionKey       contextKey = "branch-protection-template"
+	DeleteStateFileKey        contextKey = "state-file"
 )
 
 const (
@@ -20,3 +86,51 @@ const (
 	EnterpriseType   string = "Enterprise"
 	OrganizationType string = "Organization"
 )
+
+// Report sinks selectable with --report-sink
+const (
+	ReportSinkFile    string = "file"
+	ReportSinkStdout  string = "stdout"
+	ReportSinkWebhook string = "webhook"
+	ReportSinkSlack   string = "slack"
+	ReportSinkComment string = "comment"
+)
+
+// Hook failure modes selectable with --hook-failure-mode
+const (
+	HookFailureSkip string = "skip"
+	HookFailureFail string = "fail"
+)
+
+const (
+	DefaultMinConcurrency        int = 1
+	DefaultMaxConcurrency        int = 9
+	DefaultValidationConcurrency int = 10
+	DefaultRepoDeleteConcurrency int = 4
+	DefaultMaxWorkers            int = 9
+	// MaxWorkersLimit caps --max-workers; more concurrent org creations only trip
+	// secondary rate limits
+	MaxWorkersLimit int = 50
+)
+
+const (
+	DefaultOrgCreateTimeout time.Duration = 30 * time.Second
+	DefaultOrgDeleteTimeout time.Duration = 30 * time.Second
+	DefaultHookTimeout      time.Duration = 1 * time.Minute
+)
+
+// DefaultRunIDHeader is the request header carrying --run-id
+const DefaultRunIDHeader string = "X-GHAS-Lab-Run-ID"
+
+// Environment variables used as fallbacks when the corresponding flag isn't set
+const (
+	EnvToken          string = "GHAS_LAB_TOKEN"
+	EnvAppID          string = "GHAS_LAB_APP_ID"
+	EnvPrivateKey     string = "GHAS_LAB_PRIVATE_KEY"
+	EnvPrivateKeyFile string = "GHAS_LAB_PRIVATE_KEY_FILE"
+	EnvBaseURL        string = "GHAS_LAB_BASE_URL"
+	EnvEnterpriseSlug string = "GHAS_LAB_ENTERPRISE_SLUG"
+	EnvReportWebhook  string = "GHAS_LAB_REPORT_WEBHOOK_URL"
+	EnvSlackWebhook   string = "GHAS_LAB_SLACK_WEBHOOK_URL"
+	EnvOrgAllowlist   string = "GHAS_LAB_ORG_ALLOWLIST"
+)
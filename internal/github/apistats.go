@@ -0,0 +1,276 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// rateLimitWarnRatio is the fraction of a rate limit bucket that, once used, makes the
+// run summary warn that concurrency or batch size may need lowering
+const rateLimitWarnRatio = 0.8
+
+var numericSegment = regexp.MustCompile(`^[0-9]+$`)
+
+// rateLimitObservation tracks the remaining quota seen for one rate limit resource of one
+// token. Each token, such as each organization's installation token, has its own buckets.
+type rateLimitObservation struct {
+	resource       string
+	token          string
+	limit          int
+	minRemaining   int
+	finalRemaining int
+}
+
+func (o *rateLimitObservation) usedRatio() float64 {
+	if o.limit <= 0 {
+		return 0
+	}
+	return float64(o.limit-o.minRemaining) / float64(o.limit)
+}
+
+// APICallStats counts requests by method and path template and records the rate limit
+// headers seen. It is shared by every transport so counts cover the whole run.
+type APICallStats struct {
+	mu     sync.Mutex
+	total  int
+	counts map[string]int
+	// rateLimits is keyed by resource and token
+	rateLimits map[string]*rateLimitObservation
+	// retries counts transient failures that were retried
+	retries int
+	// secondaryRateLimits counts responses that hit a secondary rate limit
+	secondaryRateLimits int
+}
+
+func newAPICallStats() *APICallStats {
+	return &APICallStats{
+		counts:     make(map[string]int),
+		rateLimits: make(map[string]*rateLimitObservation),
+	}
+}
+
+var globalAPICallStats = newAPICallStats()
+
+// record counts the request and, if a response was received, its rate limit headers for
+// token, which identifies the credentials the request was sent with
+func (s *APICallStats) record(req *http.Request, resp *http.Response, token string) {
+	key := req.Method + " " + pathTemplate(req.URL.Path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total++
+	s.counts[key]++
+
+	if resp == nil {
+		return
+	}
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+
+	obsKey := resource + "|" + token
+	obs, ok := s.rateLimits[obsKey]
+	if !ok {
+		s.rateLimits[obsKey] = &rateLimitObservation{resource: resource, token: token, limit: limit, minRemaining: remaining, finalRemaining: remaining}
+		return
+	}
+	obs.limit = limit
+	obs.finalRemaining = remaining
+	if remaining < obs.minRemaining {
+		obs.minRemaining = remaining
+	}
+}
+
+// recordRetry counts a transient failure that is about to be retried
+func (s *APICallStats) recordRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+// recordSecondaryRateLimit counts a response that hit a secondary rate limit
+func (s *APICallStats) recordSecondaryRateLimit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secondaryRateLimits++
+}
+
+// pathTemplate replaces owner, repo, user and ID segments of a GitHub API path with
+// placeholders so requests to different orgs and repos are counted together
+func pathTemplate(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments); i++ {
+		switch {
+		case segments[i] == "repos":
+			for j := i + 1; j <= i+2 && j < len(segments); j++ {
+				segments[j] = "{}"
+			}
+			i += 2
+		case segments[i] == "orgs" || segments[i] == "users" || segments[i] == "enterprises" ||
+			segments[i] == "memberships" || segments[i] == "branches":
+			if i+1 < len(segments) {
+				segments[i+1] = "{}"
+			}
+			i++
+		case numericSegment.MatchString(segments[i]):
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// busiestRateLimits returns, for each rate limit resource, the observation of the token
+// that came closest to exhausting it, and how many tokens were seen for the resource.
+// Values from different tokens describe different buckets, so they are never combined.
+func (s *APICallStats) busiestRateLimits() ([]*rateLimitObservation, map[string]int) {
+	busiest := make(map[string]*rateLimitObservation)
+	tokens := make(map[string]int)
+	for _, obs := range s.rateLimits {
+		tokens[obs.resource]++
+		if current, ok := busiest[obs.resource]; !ok || obs.usedRatio() > current.usedRatio() ||
+			(obs.usedRatio() == current.usedRatio() && obs.token < current.token) {
+			busiest[obs.resource] = obs
+		}
+	}
+
+	observations := make([]*rateLimitObservation, 0, len(busiest))
+	for _, obs := range busiest {
+		observations = append(observations, obs)
+	}
+	sort.Slice(observations, func(i, j int) bool {
+		return observations[i].resource < observations[j].resource
+	})
+	return observations, tokens
+}
+
+// LogAPICallSummary logs the per-endpoint request counts for the run and, per rate limit
+// resource, how close the busiest token's bucket came to being exhausted
+func LogAPICallSummary(logger *slog.Logger) {
+	s := globalAPICallStats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.total == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(s.counts))
+	for key := range s.counts {
+		keys = append(keys, key)
+	}
+	// Busiest endpoints first
+	sort.Slice(keys, func(i, j int) bool {
+		if s.counts[keys[i]] != s.counts[keys[j]] {
+			return s.counts[keys[i]] > s.counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	breakdown := make([]string, 0, len(keys))
+	for _, key := range keys {
+		breakdown = append(breakdown, fmt.Sprintf("%s: %d", key, s.counts[key]))
+	}
+
+	logger.Info("API call summary",
+		slog.Int("total_requests", s.total),
+		slog.String("breakdown", strings.Join(breakdown, ", ")))
+
+	observations, tokens := s.busiestRateLimits()
+	for _, obs := range observations {
+		if obs.limit <= 0 {
+			continue
+		}
+		usedRatio := obs.usedRatio()
+
+		attrs := []any{
+			slog.String("resource", obs.resource),
+			slog.String("token", obs.token),
+			slog.Int("tokens_seen", tokens[obs.resource]),
+			slog.Int("limit", obs.limit),
+			slog.Int("min_remaining", obs.minRemaining),
+			slog.Int("final_remaining", obs.finalRemaining),
+			slog.String("peak_usage", fmt.Sprintf("%.1f%%", usedRatio*100)),
+		}
+		if usedRatio >= rateLimitWarnRatio {
+			logger.Warn("Run came close to the rate limit; consider lowering --max-concurrency or splitting the batch", attrs...)
+		} else {
+			logger.Info("Rate limit usage", attrs...)
+		}
+	}
+}
+
+// RateLimitUsage is the remaining quota seen during the run for one rate limit resource of
+// the token that came closest to exhausting it
+type RateLimitUsage struct {
+	Resource string `json:"resource"`
+	// Token identifies the credentials whose bucket is described, e.g. "enterprise" or
+	// "organization:ghas-labs-2025-11-07-student1"
+	Token string `json:"token"`
+	// TokensSeen is the number of tokens whose rate limit headers were seen for the resource
+	TokensSeen     int `json:"tokens_seen"`
+	Limit          int `json:"limit"`
+	MinRemaining   int `json:"min_remaining"`
+	FinalRemaining int `json:"final_remaining"`
+}
+
+// APICallMetrics is a snapshot of the run's API usage, built from the same counters as
+// the API call and token cache summaries
+type APICallMetrics struct {
+	TotalRequests       int              `json:"total_requests"`
+	Endpoints           map[string]int   `json:"endpoints"`
+	Retries             int              `json:"retries"`
+	SecondaryRateLimits int              `json:"secondary_rate_limits"`
+	RateLimits          []RateLimitUsage `json:"rate_limits"`
+	TokenCacheHits      int64            `json:"token_cache_hits"`
+	TokenCacheMisses    int64            `json:"token_cache_misses"`
+	TokenCacheRefreshes int64            `json:"token_cache_refreshes"`
+}
+
+// SnapshotAPICallMetrics returns the API usage recorded so far in the run
+func SnapshotAPICallMetrics() APICallMetrics {
+	s := globalAPICallStats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := APICallMetrics{
+		TotalRequests:       s.total,
+		Endpoints:           make(map[string]int, len(s.counts)),
+		Retries:             s.retries,
+		SecondaryRateLimits: s.secondaryRateLimits,
+		RateLimits:          []RateLimitUsage{},
+		TokenCacheHits:      globalTokenCache.hits.Load(),
+		TokenCacheMisses:    globalTokenCache.misses.Load(),
+		TokenCacheRefreshes: globalTokenCache.refreshes.Load(),
+	}
+	for key, count := range s.counts {
+		metrics.Endpoints[key] = count
+	}
+	observations, tokens := s.busiestRateLimits()
+	for _, obs := range observations {
+		metrics.RateLimits = append(metrics.RateLimits, RateLimitUsage{
+			Resource:       obs.resource,
+			Token:          obs.token,
+			TokensSeen:     tokens[obs.resource],
+			Limit:          obs.limit,
+			MinRemaining:   obs.minRemaining,
+			FinalRemaining: obs.finalRemaining,
+		})
+	}
+	return metrics
+}
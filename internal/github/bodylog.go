@@ -0,0 +1,54 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// sensitiveJSONField matches string values of JSON fields that may carry credentials. The
+// closing quote is optional so a value cut off by truncation is still redacted.
+var sensitiveJSONField = regexp.MustCompile(`(?i)("[a-z_]*(?:token|secret|password|private_key|key)"\s*:\s*)"(?:[^"\\]|\\.)*("|$)`)
+
+// redactBody masks credential-like JSON fields in a request or response body
+func redactBody(body []byte) string {
+	return sensitiveJSONField.ReplaceAllString(string(body), `$1"[REDACTED]"`)
+}
+
+// truncateBody limits a body to max bytes for logging, marking when it was cut
+func truncateBody(body []byte, max int64) ([]byte, bool) {
+	if int64(len(body)) <= max {
+		return body, false
+	}
+	return body[:max], true
+}
+
+// captureRequestBody reads the request body so it can be logged and replaces it with an
+// identical reader so the request is sent unchanged
+func captureRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// captureResponseBody reads at most max+1 bytes of the response body for logging and
+// stitches them back in front of the remaining stream so the caller still sees the full body
+func captureResponseBody(resp *http.Response, max int64) ([]byte, error) {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil, nil
+	}
+	head, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	return head, err
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// ErrBranchProtectionUnsupported is returned when the repository can't have branch
+// protection, e.g. a private repository on a plan without protected branches
+var ErrBranchProtectionUnsupported = errors.New("branch protection is not supported for this repository")
+
+// ErrBranchNotFound is returned when the branch to protect doesn't exist (yet), as in a
+// repository whose template contents are still being copied
+var ErrBranchNotFound = errors.New("branch not found")
+
+// branchProtectionUnavailable reports whether a 403 response body says the plan doesn't
+// include branch protection, as opposed to e.g. a secondary rate limit whose retries ran
+// out or missing permissions
+func branchProtectionUnavailable(body []byte) bool {
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "upgrade to github") || strings.Contains(message, "enable this feature")
+}
+
+// ProtectBranch replaces the protection of one of the organization's repository branches
+// with protection
+func (org *Organization) ProtectBranch(ctx context.Context, logger *slog.Logger, repoName string, branch string, protection util.BranchProtection) error {
+	logger.Info("Protecting branch",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.String("branch", branch))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/branches/%s/protection", baseURL, org.Login, repoName, url.PathEscape(branch))
+
+	// Restrictions on who can push aren't supported by the template, but the API requires the field
+	payload := struct {
+		util.BranchProtection
+		Restrictions interface{} `json:"restrictions"`
+	}{BranchProtection: protection}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPut, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to protect branch", slog.String("repo", repoName), slog.Any("error", err))
+		return err
+	}
+
+	if status == http.StatusOK {
+		logger.Info("Successfully protected branch",
+			slog.String("org", org.Login),
+			slog.String("repo", repoName),
+			slog.String("branch", branch))
+		return nil
+	}
+	if status == http.StatusNotFound && strings.Contains(string(body), "Branch not found") {
+		return fmt.Errorf("%w: %s", ErrBranchNotFound, branch)
+	}
+	if status == http.StatusNotFound || (status == http.StatusForbidden && branchProtectionUnavailable(body)) {
+		logger.Warn("Branch protection not supported for repository",
+			slog.String("repo", repoName),
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("%w: %s", ErrBranchProtectionUnsupported, string(body))
+	}
+
+	logger.Error("Failed to protect branch",
+		slog.Int("status_code", status),
+		slog.String("response", string(body)))
+	return fmt.Errorf("failed to protect branch %s with status %d: %s", branch, status, string(body))
+}
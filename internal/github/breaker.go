@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrEnterpriseUnavailable is returned without calling the API while the enterprise
+// circuit breaker is open
+var ErrEnterpriseUnavailable = errors.New("enterprise endpoint unavailable")
+
+// enterpriseBreakerCooldown is how long GetEnterprise fails fast after its retries were
+// exhausted by transient failures
+const enterpriseBreakerCooldown = time.Minute
+
+// circuitBreaker fails calls fast for a cooldown after an endpoint was found hard-down, so
+// each later caller in the same process doesn't wait through the full retry backoff again
+type circuitBreaker struct {
+	mu        sync.Mutex
+	openUntil time.Time
+	lastErr   error
+}
+
+var enterpriseBreaker circuitBreaker
+
+// check returns an error wrapping ErrEnterpriseUnavailable while the breaker is open
+func (b *circuitBreaker) check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return fmt.Errorf("%w: failing fast until %s after an earlier failure in this run: %v",
+			ErrEnterpriseUnavailable, b.openUntil.Format(time.TimeOnly), b.lastErr)
+	}
+	return nil
+}
+
+// trip opens the breaker for the cooldown
+func (b *circuitBreaker) trip(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openUntil = time.Now().Add(enterpriseBreakerCooldown)
+	b.lastErr = err
+}
+
+// reset closes the breaker after a successful call
+func (b *circuitBreaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openUntil = time.Time{}
+	b.lastErr = nil
+}
@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/auth"
@@ -34,12 +36,32 @@ type Options struct {
 	// Maximum number of bytes to log for request and response bodies.
 	// Set to 0 to disable body logging.
 	MaxBodyLogBytes int64
+
+	// HTTPTrace logs connection-level timings (DNS, connect, TLS, first byte) for each request.
+	HTTPTrace bool
+
+	// Optional limiter bounding in-flight requests. It is signalled on
+	// secondary rate limits and successful responses.
+	Limiter *AdaptiveLimiter
+
+	// Optional counters recording every request and the rate limit headers returned.
+	Stats *APICallStats
+
+	// RateLimitToken identifies the credentials requests are sent with, so Stats keeps the
+	// rate limit headers of different tokens apart. It must not be the token itself.
+	RateLimitToken string
 }
 
 // tokenCache holds cached tokens by target type
 type tokenCache struct {
 	sync.RWMutex
 	tokens map[string]cachedToken
+
+	// hits counts requests served from the cache, misses those for a key never cached
+	// and refreshes those whose cached token had expired. The last two each request a new token.
+	hits      atomic.Int64
+	misses    atomic.Int64
+	refreshes atomic.Int64
 }
 
 type cachedToken struct {
@@ -51,6 +73,34 @@ var globalTokenCache = &tokenCache{
 	tokens: make(map[string]cachedToken),
 }
 
+// ClearTokenCache drops every cached installation token so they don't stay in memory
+// after a command finishes. Later requests acquire fresh tokens.
+func ClearTokenCache() {
+	globalTokenCache.Lock()
+	defer globalTokenCache.Unlock()
+	globalTokenCache.tokens = make(map[string]cachedToken)
+}
+
+// LogTokenCacheSummary logs how often installation tokens were served from the cache
+// rather than minted, to show whether per-org token caching helps a run of this size.
+// Nothing is logged when no app token was requested, e.g. with a PAT.
+func LogTokenCacheSummary(logger *slog.Logger) {
+	hits := globalTokenCache.hits.Load()
+	misses := globalTokenCache.misses.Load()
+	refreshes := globalTokenCache.refreshes.Load()
+	total := hits + misses + refreshes
+	if total == 0 {
+		return
+	}
+
+	logger.Info("Token cache summary",
+		slog.Int64("hits", hits),
+		slog.Int64("misses", misses),
+		slog.Int64("refreshes", refreshes),
+		slog.Int64("tokens_requested", misses+refreshes),
+		slog.String("hit_rate", fmt.Sprintf("%.1f%%", float64(hits)/float64(total)*100)))
+}
+
 // CustomRoundTripper implements http.RoundTripper
 type CustomRoundTripper struct {
 	base            http.RoundTripper
@@ -58,6 +108,10 @@ type CustomRoundTripper struct {
 	authProvider    AuthProvider
 	logger          *slog.Logger
 	maxBodyLogBytes int64
+	httpTrace       bool
+	limiter         *AdaptiveLimiter
+	stats           *APICallStats
+	rateLimitToken  string
 }
 
 // NewCustomRoundTripper constructs a CustomRoundTripper with sane defaults.
@@ -84,6 +138,10 @@ func NewCustomRoundTripper(opts Options) *CustomRoundTripper {
 		authProvider:    opts.AuthProvider,
 		logger:          logger,
 		maxBodyLogBytes: opts.MaxBodyLogBytes,
+		httpTrace:       opts.HTTPTrace,
+		limiter:         opts.Limiter,
+		stats:           opts.Stats,
+		rateLimitToken:  opts.RateLimitToken,
 	}
 }
 
@@ -111,15 +169,38 @@ func (c *CustomRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 		}
 	}
 
+	if c.httpTrace {
+		req2 = withHTTPTrace(req2, c.logger, newTraceTimings())
+	}
+
 	c.logger.Info("HTTP Request",
 		slog.String("method", req2.Method),
 		slog.String("url", req2.URL.String()),
 	)
 
+	if c.maxBodyLogBytes > 0 {
+		body, err := captureRequestBody(req2)
+		if err != nil {
+			return nil, err
+		}
+		c.logBody("HTTP Request body", req2, body)
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Acquire(req2.Context()); err != nil {
+			return nil, err
+		}
+		defer c.limiter.Release()
+	}
+
 	// Perform the actual request
 	resp, err := c.base.RoundTrip(req2)
 	duration := time.Since(start)
 
+	if c.stats != nil {
+		c.stats.record(req2, resp, c.rateLimitToken)
+	}
+
 	if err != nil {
 		c.logger.Error("HTTP Error",
 			slog.String("method", req2.Method),
@@ -137,9 +218,44 @@ func (c *CustomRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 		slog.Duration("took", duration),
 	)
 
+	if c.maxBodyLogBytes > 0 {
+		body, err := captureResponseBody(resp, c.maxBodyLogBytes)
+		if err != nil {
+			c.logger.Warn("Failed to read response body for logging", slog.String("url", req2.URL.String()), slog.Any("error", err))
+		}
+		c.logBody("HTTP Response body", req2, body)
+	}
+
+	if c.stats != nil && isSecondaryRateLimit(resp) {
+		c.stats.recordSecondaryRateLimit()
+	}
+
+	if c.limiter != nil {
+		if isSecondaryRateLimit(resp) {
+			c.limiter.OnRateLimited(c.logger)
+		} else if resp.StatusCode < http.StatusBadRequest {
+			c.limiter.OnSuccess()
+		}
+	}
+
 	return resp, nil
 }
 
+// logBody logs a request or response body, truncated to maxBodyLogBytes and with
+// credential-like fields redacted. Empty bodies are skipped.
+func (c *CustomRoundTripper) logBody(msg string, req *http.Request, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	logged, truncated := truncateBody(body, c.maxBodyLogBytes)
+	c.logger.Info(msg,
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.String("body", redactBody(logged)),
+		slog.Bool("truncated", truncated),
+	)
+}
+
 // Helper for simple API: create a transport that injects GitHub headers and acquires token automatically
 // Accepts a context with app credentials or PAT token, logger, and installation target type.
 // This is what is used in the application code.
@@ -148,6 +264,28 @@ func NewGithubStyleTransport(ctx context.Context, logger *slog.Logger, targetTyp
 		"Accept":               "application/vnd.github+json",
 		"X-GitHub-Api-Version": "2022-11-28",
 	}
+	// Tag every request with the run ID so the run can be found in GHES audit logs
+	if runID, ok := ctx.Value(config.RunIDKey).(string); ok && runID != "" {
+		header, _ := ctx.Value(config.RunIDHeaderKey).(string)
+		if header == "" {
+			header = config.DefaultRunIDHeader
+		}
+		static[header] = runID
+	}
+
+	// Build cache key based on target type and organization
+	cacheKey := targetType
+	if targetType == config.OrganizationType {
+		if orgName, ok := ctx.Value(config.OrgKey).(string); ok && orgName != "" {
+			cacheKey = targetType + ":" + orgName
+		}
+	}
+
+	// A PAT has one set of rate limit buckets; each installation token has its own
+	rateLimitToken := cacheKey
+	if token, ok := ctx.Value(config.TokenKey).(string); ok && token != "" {
+		rateLimitToken = "pat"
+	}
 
 	authProv := func(req *http.Request) (string, error) {
 		// Check if using PAT token
@@ -156,17 +294,11 @@ func NewGithubStyleTransport(ctx context.Context, logger *slog.Logger, targetTyp
 		}
 
 		// Using GitHub App authentication
-		// Build cache key based on target type and organization
-		cacheKey := targetType
-		if targetType == config.OrganizationType {
-			if orgName, ok := ctx.Value(config.OrgKey).(string); ok && orgName != "" {
-				cacheKey = targetType + ":" + orgName
-			}
-		}
 		globalTokenCache.RLock()
 		if cached, ok := globalTokenCache.tokens[cacheKey]; ok && time.Now().Before(cached.expires) {
 			token := cached.token
 			globalTokenCache.RUnlock()
+			globalTokenCache.hits.Add(1)
 			return "Bearer " + token, nil
 		}
 		globalTokenCache.RUnlock()
@@ -175,15 +307,18 @@ func NewGithubStyleTransport(ctx context.Context, logger *slog.Logger, targetTyp
 		defer globalTokenCache.Unlock()
 
 		// Double-check after acquiring write lock to deal with race condition
-		if cached, ok := globalTokenCache.tokens[cacheKey]; ok && time.Now().Before(cached.expires) {
+		cached, ok := globalTokenCache.tokens[cacheKey]
+		if ok && time.Now().Before(cached.expires) {
+			globalTokenCache.hits.Add(1)
 			return "Bearer " + cached.token, nil
 		}
+		if ok {
+			globalTokenCache.refreshes.Add(1)
+		} else {
+			globalTokenCache.misses.Add(1)
+		}
 
-		ts := auth.NewTokenService(
-			ctx.Value(config.AppIDKey).(string),
-			ctx.Value(config.PrivateKeyKey).(string),
-			ctx.Value(config.BaseURLKey).(string),
-		)
+		ts := newTokenServiceFromContext(ctx)
 
 		var tokenStr string
 		var err error
@@ -218,10 +353,31 @@ func NewGithubStyleTransport(ctx context.Context, logger *slog.Logger, targetTyp
 		return "Bearer " + tokenStr, nil
 	}
 
+	httpTrace, _ := ctx.Value(config.HTTPTraceKey).(bool)
+	maxBodyLogBytes, _ := ctx.Value(config.MaxBodyLogBytesKey).(int64)
+
 	return NewCustomRoundTripper(Options{
-		Base:          http.DefaultTransport,
-		StaticHeaders: static,
-		AuthProvider:  authProv,
-		Logger:        logger,
+		Base:            getSharedTransport(ctx),
+		StaticHeaders:   static,
+		AuthProvider:    authProv,
+		Logger:          logger,
+		HTTPTrace:       httpTrace,
+		MaxBodyLogBytes: maxBodyLogBytes,
+		Limiter:         getSharedLimiter(ctx),
+		Stats:           globalAPICallStats,
+		RateLimitToken:  rateLimitToken,
 	})
 }
+
+// newTokenServiceFromContext builds a TokenService from the app credentials stored in the
+// context. When the key was read from --private-key-file, the service can reload it from
+// there if it has been rotated.
+func newTokenServiceFromContext(ctx context.Context) *auth.TokenService {
+	keyFormat, _ := ctx.Value(config.PrivateKeyFormatKey).(string)
+	keyFile, _ := ctx.Value(config.PrivateKeyFileKey).(string)
+	return auth.NewTokenService(
+		ctx.Value(config.AppIDKey).(string),
+		ctx.Value(config.PrivateKeyKey).(string),
+		ctx.Value(config.BaseURLKey).(string),
+	).WithKeyFormat(keyFormat).WithKeyFile(keyFile).WithTransport(getSharedTransport(ctx))
+}
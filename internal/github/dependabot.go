@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// ErrDependabotUnsupported is returned when GitHub refuses to enable Dependabot on a
+// repository that can't have it, e.g. an empty or archived repository or an instance
+// without Dependabot
+var ErrDependabotUnsupported = errors.New("dependabot is not supported for this repository")
+
+// EnableOrgDependabot turns on the dependency graph, Dependabot alerts and Dependabot
+// security updates for new repositories in the organization
+func EnableOrgDependabot(ctx context.Context, logger *slog.Logger, orgName string) error {
+	logger.Info("Enabling Dependabot for new repositories", slog.String("org", orgName))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/orgs/%s", baseURL, orgName)
+
+	payload := map[string]interface{}{
+		"dependency_graph_enabled_for_new_repositories":            true,
+		"dependabot_alerts_enabled_for_new_repositories":           true,
+		"dependabot_security_updates_enabled_for_new_repositories": true,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPatch, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to enable Dependabot for organization", slog.String("org", orgName), slog.Any("error", err))
+		return err
+	}
+
+	if status != http.StatusOK {
+		logger.Error("Failed to enable Dependabot for organization",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to enable Dependabot for organization %s with status %d: %s", orgName, status, string(body))
+	}
+
+	logger.Info("Successfully enabled Dependabot for new repositories", slog.String("org", orgName))
+	return nil
+}
+
+// EnableVulnerabilityAlerts turns on Dependabot alerts for one of the organization's
+// repositories
+func (org *Organization) EnableVulnerabilityAlerts(ctx context.Context, logger *slog.Logger, repoName string) error {
+	return org.enableRepoDependabotFeature(ctx, logger, repoName, "vulnerability-alerts", "Dependabot alerts")
+}
+
+// EnableAutomatedSecurityFixes turns on Dependabot security updates for one of the
+// organization's repositories. Dependabot alerts must be enabled first.
+func (org *Organization) EnableAutomatedSecurityFixes(ctx context.Context, logger *slog.Logger, repoName string) error {
+	return org.enableRepoDependabotFeature(ctx, logger, repoName, "automated-security-fixes", "Dependabot security updates")
+}
+
+// enableRepoDependabotFeature PUTs one of the repository's Dependabot toggles. A 404 or
+// 422 means the repository can't have the feature and returns ErrDependabotUnsupported.
+func (org *Organization) enableRepoDependabotFeature(ctx context.Context, logger *slog.Logger, repoName string, endpoint string, feature string) error {
+	logger.Info("Enabling "+feature,
+		slog.String("org", org.Login),
+		slog.String("repo", repoName))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/%s", baseURL, org.Login, repoName, endpoint)
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPut, apiURL, nil)
+	})
+	if err != nil {
+		logger.Error("Failed to enable "+feature, slog.String("repo", repoName), slog.Any("error", err))
+		return err
+	}
+
+	switch status {
+	case http.StatusNoContent:
+		logger.Info("Successfully enabled "+feature,
+			slog.String("org", org.Login),
+			slog.String("repo", repoName))
+		return nil
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		logger.Warn(feature+" not supported for repository",
+			slog.String("repo", repoName),
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("%w: %s", ErrDependabotUnsupported, string(body))
+	}
+
+	logger.Error("Failed to enable "+feature,
+		slog.Int("status_code", status),
+		slog.String("response", string(body)))
+	return fmt.Errorf("failed to enable %s with status %d: %s", feature, status, string(body))
+}
@@ -13,19 +13,47 @@ import (
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 )
 
+// ResolveEnterprise returns the enterprise to work in. With --enterprise-id it is built
+// from the ID and --billing-email without calling GetEnterprise, so the slug, which may be
+// empty, isn't validated; otherwise it is looked up by slug.
+func ResolveEnterprise(ctx context.Context, logger *slog.Logger, enterpriseSlug string) (*Enterprise, error) {
+	enterpriseID, _ := ctx.Value(config.EnterpriseIDKey).(string)
+	if enterpriseID == "" {
+		return GetEnterprise(ctx, logger, enterpriseSlug)
+	}
+	billingEmail, _ := ctx.Value(config.BillingEmailKey).(string)
+	logger.Info("Using enterprise ID without lookup",
+		slog.String("enterprise_id", enterpriseID),
+		slog.String("slug", enterpriseSlug))
+	return &Enterprise{ID: enterpriseID, BillingEmail: billingEmail, Slug: enterpriseSlug}, nil
+}
+
 // GetEnterprise retrieves enterprise information using the enterprise slug via GraphQL
 func GetEnterprise(ctx context.Context, logger *slog.Logger, enterpriseSlug string) (*Enterprise, error) {
-	logger.Info("Fetching enterprise", slog.String("slug", enterpriseSlug))
+	baseURL := ctx.Value(config.BaseURLKey).(string)
 
-	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
+	useCache := true
+	if noCache, _ := ctx.Value(config.NoEnterpriseCacheKey).(bool); noCache {
+		useCache = false
+	}
+	if useCache {
+		if cached := loadCachedEnterprise(logger, baseURL, enterpriseSlug); cached != nil {
+			return cached, nil
+		}
+	}
+
+	if err := enterpriseBreaker.check(); err != nil {
+		logger.Error("Enterprise endpoint unavailable, failing fast", slog.String("slug", enterpriseSlug), slog.Any("error", err))
+		return nil, err
+	}
+
+	logger.Info("Fetching enterprise", slog.String("slug", enterpriseSlug))
 
 	rt := NewGithubStyleTransport(ctx, logger, config.EnterpriseType)
 	client := &http.Client{
 		Transport: rt,
 	}
 
-	baseURL := ctx.Value(config.BaseURLKey).(string)
 	graphqlURL := baseURL + "/graphql"
 
 	query := `
@@ -43,6 +71,7 @@ func GetEnterprise(ctx context.Context, logger *slog.Logger, enterpriseSlug stri
 			"slug": enterpriseSlug,
 		},
 	}
+	logGraphQLQuery(ctx, logger, payload)
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -50,40 +79,35 @@ func GetEnterprise(ctx context.Context, logger *slog.Logger, enterpriseSlug stri
 		return nil, fmt.Errorf("failed to marshal GraphQL payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		logger.Error("Failed to create request", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := client.Do(req)
+	// Every command starts here, so a blip is retried rather than aborting the run
+	status, body, err := doReadWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewReader(jsonData))
+	})
 	if err != nil {
 		logger.Error("Failed to execute request", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error("Failed to read response body", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		if ctx.Err() == nil {
+			enterpriseBreaker.trip(err)
+		}
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if status != http.StatusOK {
 		logger.Error("GraphQL request failed",
-			slog.Int("status_code", resp.StatusCode),
+			slog.Int("status_code", status),
 			slog.String("response", string(body)))
-		return nil, fmt.Errorf("GraphQL request failed with status %d: %s", resp.StatusCode, string(body))
+		err := fmt.Errorf("GraphQL request failed with status %d: %s", status, string(body))
+		if status >= http.StatusInternalServerError {
+			enterpriseBreaker.trip(err)
+		}
+		return nil, err
 	}
+	enterpriseBreaker.reset()
 
 	var result struct {
 		Data struct {
 			Enterprise Enterprise `json:"enterprise"`
 		} `json:"data"`
-		Errors []struct {
-			Message string   `json:"message"`
-			Path    []string `json:"path"`
-		} `json:"errors"`
+		Errors []GraphQLError `json:"errors"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -94,8 +118,9 @@ func GetEnterprise(ctx context.Context, logger *slog.Logger, enterpriseSlug stri
 	if len(result.Errors) > 0 {
 		logger.Error("GraphQL errors",
 			slog.String("message", result.Errors[0].Message),
+			slog.String("type", result.Errors[0].Type),
 			slog.Any("errors", result.Errors))
-		return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+		return nil, &GraphQLResponseError{Errors: result.Errors}
 	}
 
 	if result.Data.Enterprise.ID == "" {
@@ -108,6 +133,10 @@ func GetEnterprise(ctx context.Context, logger *slog.Logger, enterpriseSlug stri
 		slog.String("slug", result.Data.Enterprise.Slug),
 		slog.String("billing email", result.Data.Enterprise.BillingEmail))
 
+	if useCache {
+		storeCachedEnterprise(logger, baseURL, enterpriseSlug, &result.Data.Enterprise)
+	}
+
 	return &result.Data.Enterprise, nil
 }
 
@@ -160,6 +189,7 @@ func GetEnterpriseOrganizations(ctx context.Context, logger *slog.Logger, enterp
 			"query":     query,
 			"variables": variables,
 		}
+		logGraphQLQuery(ctx, logger, payload)
 
 		jsonData, err := json.Marshal(payload)
 		if err != nil {
@@ -205,10 +235,7 @@ func GetEnterpriseOrganizations(ctx context.Context, logger *slog.Logger, enterp
 					} `json:"organizations"`
 				} `json:"enterprise"`
 			} `json:"data"`
-			Errors []struct {
-				Message string   `json:"message"`
-				Path    []string `json:"path"`
-			} `json:"errors"`
+			Errors []GraphQLError `json:"errors"`
 		}
 
 		if err := json.Unmarshal(body, &result); err != nil {
@@ -219,8 +246,9 @@ func GetEnterpriseOrganizations(ctx context.Context, logger *slog.Logger, enterp
 		if len(result.Errors) > 0 {
 			logger.Error("GraphQL errors",
 				slog.String("message", result.Errors[0].Message),
+				slog.String("type", result.Errors[0].Type),
 				slog.Any("errors", result.Errors))
-			return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+			return nil, &GraphQLResponseError{Errors: result.Errors}
 		}
 
 		// Append organizations from this page
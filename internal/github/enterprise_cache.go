@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// enterpriseCacheTTL is how long a resolved enterprise is reused before GetEnterprise
+// queries GitHub again
+const enterpriseCacheTTL = 24 * time.Hour
+
+// enterpriseCacheFileName is the sidecar file, under the user cache directory, holding
+// resolved enterprises keyed by base URL and slug
+const enterpriseCacheFileName = "enterprises.json"
+
+type cachedEnterprise struct {
+	Enterprise Enterprise `json:"enterprise"`
+	CachedAt   time.Time  `json:"cached_at"`
+}
+
+// enterpriseCacheMu serialises read-modify-write of the cache file within this process
+var enterpriseCacheMu sync.Mutex
+
+func enterpriseCacheKey(baseURL string, slug string) string {
+	return baseURL + "|" + slug
+}
+
+func enterpriseCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ghas-lab-builder", enterpriseCacheFileName), nil
+}
+
+func readEnterpriseCache(path string) (map[string]cachedEnterprise, error) {
+	entries := make(map[string]cachedEnterprise)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("corrupt enterprise cache %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// loadCachedEnterprise returns the cached enterprise for the base URL and slug, or nil if
+// there is no fresh entry. Cache problems are logged and treated as a miss.
+func loadCachedEnterprise(logger *slog.Logger, baseURL string, slug string) *Enterprise {
+	enterpriseCacheMu.Lock()
+	defer enterpriseCacheMu.Unlock()
+
+	path, err := enterpriseCachePath()
+	if err != nil {
+		logger.Warn("Enterprise cache unavailable", slog.Any("error", err))
+		return nil
+	}
+
+	entries, err := readEnterpriseCache(path)
+	if err != nil {
+		logger.Warn("Ignoring unreadable enterprise cache", slog.String("path", path), slog.Any("error", err))
+		return nil
+	}
+
+	entry, ok := entries[enterpriseCacheKey(baseURL, slug)]
+	if !ok || entry.Enterprise.ID == "" || time.Since(entry.CachedAt) > enterpriseCacheTTL {
+		return nil
+	}
+
+	logger.Info("Using cached enterprise",
+		slog.String("slug", slug),
+		slog.String("id", entry.Enterprise.ID),
+		slog.Time("cached_at", entry.CachedAt))
+	return &entry.Enterprise
+}
+
+// storeCachedEnterprise records the enterprise in the cache file under the slug it was
+// requested with, which loadCachedEnterprise looks up, rather than the slug GitHub returned.
+// Failures are logged only; the cache is an optimisation and must never fail a run.
+func storeCachedEnterprise(logger *slog.Logger, baseURL string, slug string, enterprise *Enterprise) {
+	enterpriseCacheMu.Lock()
+	defer enterpriseCacheMu.Unlock()
+
+	path, err := enterpriseCachePath()
+	if err != nil {
+		logger.Warn("Enterprise cache unavailable", slog.Any("error", err))
+		return
+	}
+
+	// Start over if the existing file is corrupt
+	entries, err := readEnterpriseCache(path)
+	if err != nil {
+		entries = make(map[string]cachedEnterprise)
+	}
+	entries[enterpriseCacheKey(baseURL, slug)] = cachedEnterprise{
+		Enterprise: *enterprise,
+		CachedAt:   time.Now(),
+	}
+
+	if err := writeEnterpriseCache(path, entries); err != nil {
+		logger.Warn("Failed to write enterprise cache", slog.String("path", path), slog.Any("error", err))
+	}
+}
+
+// writeEnterpriseCache writes via a temp file and rename so concurrent invocations never
+// observe a partially written file
+func writeEnterpriseCache(path string, entries map[string]cachedEnterprise) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), enterpriseCacheFileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
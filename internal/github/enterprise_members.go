@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// Enterprise membership states returned by GetMembershipState
+const (
+	EnterpriseMembershipMember  = "member"
+	EnterpriseMembershipPending = "pending"
+	EnterpriseMembershipNone    = "none"
+)
+
+// GetMembershipState reports whether the user is a member of the enterprise, has a
+// pending enterprise invitation, or neither
+func (enterprise *Enterprise) GetMembershipState(ctx context.Context, logger *slog.Logger, login string) (string, error) {
+	query := `
+		query($slug: String!, $login: String!) {
+			enterprise(slug: $slug) {
+				members(first: 10, query: $login) {
+					nodes {
+						... on User { login }
+						... on EnterpriseUserAccount { login }
+					}
+				}
+				ownerInfo {
+					pendingMemberInvitations(first: 10, query: $login) {
+						nodes {
+							invitee { login }
+						}
+					}
+				}
+			}
+		}
+	`
+
+	var data struct {
+		Enterprise struct {
+			Members struct {
+				Nodes []struct {
+					Login string `json:"login"`
+				} `json:"nodes"`
+			} `json:"members"`
+			OwnerInfo struct {
+				PendingMemberInvitations struct {
+					Nodes []struct {
+						Invitee struct {
+							Login string `json:"login"`
+						} `json:"invitee"`
+					} `json:"nodes"`
+				} `json:"pendingMemberInvitations"`
+			} `json:"ownerInfo"`
+		} `json:"enterprise"`
+	}
+
+	variables := map[string]interface{}{
+		"slug":  enterprise.Slug,
+		"login": login,
+	}
+	if err := postEnterpriseGraphQL(ctx, logger, query, variables, &data); err != nil {
+		return "", fmt.Errorf("failed to get enterprise membership for %s: %w", login, err)
+	}
+
+	// The query argument matches on prefixes, so look for the exact login
+	for _, member := range data.Enterprise.Members.Nodes {
+		if strings.EqualFold(member.Login, login) {
+			return EnterpriseMembershipMember, nil
+		}
+	}
+	for _, invitation := range data.Enterprise.OwnerInfo.PendingMemberInvitations.Nodes {
+		if strings.EqualFold(invitation.Invitee.Login, login) {
+			return EnterpriseMembershipPending, nil
+		}
+	}
+	return EnterpriseMembershipNone, nil
+}
+
+// InviteMember sends the user an invitation to join the enterprise as an unaffiliated member
+func (enterprise *Enterprise) InviteMember(ctx context.Context, logger *slog.Logger, login string) error {
+	logger.Info("Inviting user to enterprise",
+		slog.String("enterprise", enterprise.Slug),
+		slog.String("user", login))
+
+	mutation := `
+		mutation($enterpriseId: ID!, $invitee: String!) {
+			inviteEnterpriseMember(input: {
+				enterpriseId: $enterpriseId
+				invitee: $invitee
+			}) {
+				invitation {
+					id
+				}
+			}
+		}
+	`
+
+	var data struct {
+		InviteEnterpriseMember struct {
+			Invitation struct {
+				ID string `json:"id"`
+			} `json:"invitation"`
+		} `json:"inviteEnterpriseMember"`
+	}
+
+	variables := map[string]interface{}{
+		"enterpriseId": enterprise.ID,
+		"invitee":      login,
+	}
+	if err := postEnterpriseGraphQL(ctx, logger, mutation, variables, &data); err != nil {
+		return fmt.Errorf("failed to invite %s to enterprise: %w", login, err)
+	}
+
+	logger.Info("Invited user to enterprise",
+		slog.String("enterprise", enterprise.Slug),
+		slog.String("user", login),
+		slog.String("invitation_id", data.InviteEnterpriseMember.Invitation.ID))
+	return nil
+}
+
+// postEnterpriseGraphQL executes a GraphQL request with enterprise credentials and decodes
+// the data field into out
+func postEnterpriseGraphQL(ctx context.Context, logger *slog.Logger, query string, variables map[string]interface{}, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rt := NewGithubStyleTransport(ctx, logger, config.EnterpriseType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	graphqlURL := baseURL + "/graphql"
+
+	payload := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+	logGraphQLQuery(ctx, logger, payload)
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal GraphQL payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal GraphQL payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Error("Failed to create request", slog.Any("error", err))
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read response body", slog.Any("error", err))
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("GraphQL request failed",
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("response", string(body)))
+		return fmt.Errorf("GraphQL request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		logger.Error("Failed to parse response", slog.Any("error", err))
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Errors) > 0 {
+		logger.Error("GraphQL errors returned",
+			slog.String("message", result.Errors[0].Message),
+			slog.String("type", result.Errors[0].Type),
+			slog.Any("errors", result.Errors))
+		return &GraphQLResponseError{Errors: result.Errors}
+	}
+
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		logger.Error("Failed to parse response data", slog.Any("error", err))
+		return fmt.Errorf("failed to parse response data: %w", err)
+	}
+	return nil
+}
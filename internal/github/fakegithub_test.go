@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// fakeGitHub is an httptest server standing in for the GitHub API. Handlers are
+// registered per "METHOD /path" and every request is recorded so tests can check what
+// was sent. A request without a handler fails the test.
+type fakeGitHub struct {
+	*httptest.Server
+	t        *testing.T
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []recordedRequest
+}
+
+// recordedRequest is a request the fake received
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+	f := &fakeGitHub{t: t, handlers: make(map[string]http.HandlerFunc)}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// handle registers the handler for pattern, e.g. "POST /graphql"
+func (f *fakeGitHub) handle(pattern string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[pattern] = handler
+}
+
+// requestsTo returns the requests received for pattern, in arrival order
+func (f *fakeGitHub) requestsTo(pattern string) []recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []recordedRequest
+	for _, req := range f.requests {
+		if req.Method+" "+req.Path == pattern {
+			matched = append(matched, req)
+		}
+	}
+	return matched
+}
+
+func (f *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	pattern := r.Method + " " + r.URL.Path
+
+	f.mu.Lock()
+	f.requests = append(f.requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+	handler, ok := f.handlers[pattern]
+	f.mu.Unlock()
+
+	if !ok {
+		f.t.Errorf("unexpected request to fake GitHub: %s", pattern)
+		http.Error(w, `{"message":"no handler in fake"}`, http.StatusNotImplemented)
+		return
+	}
+	handler(w, r)
+}
+
+// respond returns a handler answering every request with status and a JSON body
+func respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+// sequence returns a handler answering each request with the next handler in turn; the
+// last one answers every request after that
+func sequence(handlers ...http.HandlerFunc) http.HandlerFunc {
+	var mu sync.Mutex
+	next := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		handler := handlers[next]
+		if next < len(handlers)-1 {
+			next++
+		}
+		mu.Unlock()
+		handler(w, r)
+	}
+}
+
+// testContext returns a context pointing the API functions at baseURL with a PAT, as the
+// root command's pre-run would
+func testContext(baseURL string) context.Context {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, config.BaseURLKey, baseURL)
+	ctx = context.WithValue(ctx, config.TokenKey, "test-token")
+	ctx = context.WithValue(ctx, config.LabDateKey, "2025-11-07")
+	return ctx
+}
+
+// testLogger discards the API functions' logs
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
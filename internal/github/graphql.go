@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// GraphQL error types returned by GitHub in errors[].type
+const (
+	GraphQLErrorNotFound           = "NOT_FOUND"
+	GraphQLErrorForbidden          = "FORBIDDEN"
+	GraphQLErrorInsufficientScopes = "INSUFFICIENT_SCOPES"
+)
+
+var (
+	// ErrGraphQLNotFound matches GraphQL errors of type NOT_FOUND via errors.Is
+	ErrGraphQLNotFound = errors.New("graphql: not found")
+	// ErrGraphQLForbidden matches GraphQL errors of type FORBIDDEN via errors.Is
+	ErrGraphQLForbidden = errors.New("graphql: forbidden")
+	// ErrGraphQLInsufficientScopes matches GraphQL errors of type INSUFFICIENT_SCOPES via errors.Is
+	ErrGraphQLInsufficientScopes = errors.New("graphql: insufficient scopes")
+)
+
+// GraphQLError represents a single entry in a GraphQL response's errors array
+type GraphQLError struct {
+	Type       string                 `json:"type,omitempty"`
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLResponseError wraps the errors array of a GraphQL response so callers
+// can react to specific error types
+type GraphQLResponseError struct {
+	Errors []GraphQLError
+}
+
+func (e *GraphQLResponseError) Error() string {
+	if len(e.Errors) == 0 {
+		return "GraphQL error: unknown error"
+	}
+
+	first := e.Errors[0]
+	msg := "GraphQL error: " + first.Message
+	if first.Type != "" {
+		msg += fmt.Sprintf(" (type: %s)", first.Type)
+	}
+	if len(first.Path) > 0 {
+		parts := make([]string, len(first.Path))
+		for i, p := range first.Path {
+			parts[i] = fmt.Sprintf("%v", p)
+		}
+		msg += fmt.Sprintf(" (path: %s)", strings.Join(parts, "."))
+	}
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" and %d more error(s)", len(e.Errors)-1)
+	}
+	return msg
+}
+
+// HasType reports whether any of the errors has the given GraphQL error type
+func (e *GraphQLResponseError) HasType(errorType string) bool {
+	for _, gqlErr := range e.Errors {
+		if gqlErr.Type == errorType {
+			return true
+		}
+	}
+	return false
+}
+
+// Is allows errors.Is to match the ErrGraphQL* sentinels against error types
+func (e *GraphQLResponseError) Is(target error) bool {
+	switch target {
+	case ErrGraphQLNotFound:
+		return e.HasType(GraphQLErrorNotFound)
+	case ErrGraphQLForbidden:
+		return e.HasType(GraphQLErrorForbidden)
+	case ErrGraphQLInsufficientScopes:
+		return e.HasType(GraphQLErrorInsufficientScopes)
+	}
+	return false
+}
+
+// logGraphQLQuery logs the query and variables of a GraphQL payload before it is sent when
+// --print-query is set, so a query failing on a particular GHES version can be reproduced.
+// Credential-like variables are redacted.
+func logGraphQLQuery(ctx context.Context, logger *slog.Logger, payload map[string]interface{}) {
+	if printQuery, _ := ctx.Value(config.PrintQueryKey).(bool); !printQuery {
+		return
+	}
+	query, _ := payload["query"].(string)
+	variables, err := json.Marshal(payload["variables"])
+	if err != nil {
+		variables = []byte("{}")
+	}
+	logger.Info("GraphQL query",
+		slog.String("query", query),
+		slog.String("variables", redactBody(variables)))
+}
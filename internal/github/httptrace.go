@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// traceTimings holds the start times of one request's trace phases. The transport may dial
+// several addresses at once for a request, so connect starts are kept per address, and
+// the hooks can run concurrently.
+type traceTimings struct {
+	mu            sync.Mutex
+	start         time.Time
+	dnsStart      time.Time
+	tlsStart      time.Time
+	connectStarts map[string]time.Time
+}
+
+func newTraceTimings() *traceTimings {
+	return &traceTimings{start: time.Now(), connectStarts: make(map[string]time.Time)}
+}
+
+// mark records now as the start time held by field
+func (t *traceTimings) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = time.Now()
+}
+
+// since returns the time elapsed since the start time held by field
+func (t *traceTimings) since(field *time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Since(*field)
+}
+
+func (t *traceTimings) markConnect(network, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connectStarts[network+"|"+addr] = time.Now()
+}
+
+func (t *traceTimings) sinceConnect(network, addr string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Since(t.connectStarts[network+"|"+addr])
+}
+
+// withHTTPTrace attaches an httptrace.ClientTrace to the request that logs DNS, connect,
+// TLS handshake and time-to-first-byte timings, measured from when the request starts.
+// timings must be new for every request.
+func withHTTPTrace(req *http.Request, logger *slog.Logger, timings *traceTimings) *http.Request {
+	start := timings.start
+
+	attrs := func(attrs ...slog.Attr) []any {
+		args := []any{
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+		}
+		for _, a := range attrs {
+			args = append(args, a)
+		}
+		return args
+	}
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			logger.Info("HTTP trace: get conn", attrs(slog.String("host", hostPort))...)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			logger.Info("HTTP trace: got conn", attrs(
+				slog.Bool("reused", info.Reused),
+				slog.Bool("was_idle", info.WasIdle),
+				slog.Duration("idle_time", info.IdleTime),
+				slog.Duration("elapsed", time.Since(start)))...)
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			timings.mark(&timings.dnsStart)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			logger.Info("HTTP trace: DNS done", attrs(
+				slog.Duration("dns", timings.since(&timings.dnsStart)),
+				slog.Any("error", info.Err))...)
+		},
+		ConnectStart: func(network, addr string) {
+			timings.markConnect(network, addr)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			logger.Info("HTTP trace: connect done", attrs(
+				slog.String("addr", addr),
+				slog.Duration("connect", timings.sinceConnect(network, addr)),
+				slog.Any("error", err))...)
+		},
+		TLSHandshakeStart: func() {
+			timings.mark(&timings.tlsStart)
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			logger.Info("HTTP trace: TLS handshake done", attrs(
+				slog.Duration("tls_handshake", timings.since(&timings.tlsStart)),
+				slog.String("tls_version", tls.VersionName(state.Version)),
+				slog.Any("error", err))...)
+		},
+		GotFirstResponseByte: func() {
+			logger.Info("HTTP trace: first response byte", attrs(
+				slog.Duration("time_to_first_byte", time.Since(start)))...)
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// idempotencyKeyHeader is sent on creation requests so a retried request can be
+// recognised as a duplicate by servers or proxies that honor it. GitHub doesn't currently
+// document Idempotency-Key support on createEnterpriseOrganization or
+// POST /repos/{owner}/{repo}/generate, so duplicate creation is still prevented by the
+// existing checks: GitHub rejects a second org or repo with the same name, and lab apply
+// looks the org or repo up before creating it.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// setIdempotencyKey sets a key derived from the operation and the resource it creates,
+// so every retry of the same creation carries the same key
+func setIdempotencyKey(req *http.Request, operation string, resource ...string) {
+	sum := sha256.Sum256([]byte(operation + ":" + strings.ToLower(strings.Join(resource, "/"))))
+	req.Header.Set(idempotencyKeyHeader, hex.EncodeToString(sum[:16]))
+}
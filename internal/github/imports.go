@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+const (
+	repoImportTimeout      = 10 * time.Minute
+	repoImportPollInterval = 5 * time.Second
+)
+
+// RepoImportError is returned by ImportRepository when the import ends in a failed state
+type RepoImportError struct {
+	Repo    string
+	Status  string
+	Message string
+}
+
+func (e *RepoImportError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("import into %s failed with status %s: %s", e.Repo, e.Status, e.Message)
+	}
+	return fmt.Sprintf("import into %s failed with status %s", e.Repo, e.Status)
+}
+
+// repoImport is the subset of the source import status used to follow an import
+type repoImport struct {
+	Status       string `json:"status"`
+	StatusText   string `json:"status_text"`
+	FailedStep   string `json:"failed_step"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// failed reports whether the import stopped without completing
+func (i repoImport) failed() bool {
+	switch i.Status {
+	case "auth_failed", "error", "detection_needs_auth", "detection_found_nothing", "detection_found_multiple":
+		return true
+	}
+	return false
+}
+
+// ImportRepository imports the git repository at sourceURL into the organization's
+// existing, empty repository name using the source imports API, and polls until the
+// import completes, fails or times out
+func (org *Organization) ImportRepository(ctx context.Context, logger *slog.Logger, name string, sourceURL string) error {
+	logger.Info("Starting repository import",
+		slog.String("org", org.Login),
+		slog.String("repo", name),
+		slog.String("source", sourceURL))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/import", baseURL, org.Login, name)
+
+	payload := map[string]interface{}{
+		"vcs_url": sourceURL,
+		"vcs":     "git",
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPut, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to start repository import", slog.String("repo", name), slog.Any("error", err))
+		return err
+	}
+
+	if status != http.StatusCreated {
+		logger.Error("Failed to start repository import",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to start import into %s with status %d: %s", name, status, string(body))
+	}
+
+	return org.waitForImport(ctx, logger, client, apiURL, name)
+}
+
+// waitForImport polls the import status until it completes or fails. Imports run in the
+// background on GitHub's side and can take minutes for larger repositories.
+func (org *Organization) waitForImport(ctx context.Context, logger *slog.Logger, client *http.Client, apiURL string, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, repoImportTimeout)
+	defer cancel()
+
+	start := time.Now()
+	lastStatus := ""
+	for {
+		status, body, err := doReadWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+		})
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("import into %s/%s not complete after %s", org.Login, name, repoImportTimeout)
+		}
+		if err != nil {
+			logger.Error("Failed to get repository import status", slog.String("repo", name), slog.Any("error", err))
+			return err
+		}
+		if status != http.StatusOK {
+			logger.Error("Failed to get repository import status",
+				slog.Int("status_code", status),
+				slog.String("response", string(body)))
+			return fmt.Errorf("failed to get import status for %s with status %d: %s", name, status, string(body))
+		}
+
+		var progress repoImport
+		if err := json.Unmarshal(body, &progress); err != nil {
+			logger.Error("Failed to parse response", slog.Any("error", err))
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		if progress.Status != lastStatus {
+			logger.Info("Repository import status",
+				slog.String("org", org.Login),
+				slog.String("repo", name),
+				slog.String("status", progress.Status),
+				slog.String("status_text", progress.StatusText))
+			lastStatus = progress.Status
+		}
+
+		if progress.Status == "complete" {
+			logger.Info("Repository import completed",
+				slog.String("org", org.Login),
+				slog.String("repo", name),
+				slog.Duration("waited", time.Since(start)))
+			return nil
+		}
+		if progress.failed() {
+			message := progress.ErrorMessage
+			if message == "" {
+				message = progress.StatusText
+			}
+			logger.Error("Repository import failed",
+				slog.String("repo", name),
+				slog.String("status", progress.Status),
+				slog.String("failed_step", progress.FailedStep),
+				slog.String("message", message))
+			return &RepoImportError{Repo: fmt.Sprintf("%s/%s", org.Login, name), Status: progress.Status, Message: message}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("import into %s/%s not complete after %s", org.Login, name, repoImportTimeout)
+		case <-time.After(repoImportPollInterval):
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// MaxIssueCommentLength is the maximum body length GitHub accepts for an issue or PR comment
+const MaxIssueCommentLength = 65536
+
+// CreateIssueComment posts a comment on an issue or pull request
+func CreateIssueComment(ctx context.Context, logger *slog.Logger, owner string, repoName string, number int, body string) error {
+	logger.Info("Posting issue comment",
+		slog.String("repo", owner+"/"+repoName),
+		slog.Int("number", number))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, owner)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", baseURL, owner, repoName, number)
+
+	payload := map[string]interface{}{
+		"body": body,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Error("Failed to create request", slog.Any("error", err))
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		logger.Error("Failed to post issue comment",
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("response", string(respBody)))
+		return fmt.Errorf("failed to post issue comment with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	logger.Info("Successfully posted issue comment",
+		slog.String("repo", owner+"/"+repoName),
+		slog.Int("number", number))
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// IntervalLimiter enforces a minimum interval between operations across all goroutines.
+// Org creation has its own abuse thresholds on GHEC, separate from the general API rate
+// limits handled by AdaptiveLimiter.
+type IntervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewIntervalLimiter creates a limiter allowing one operation per interval. An interval
+// of zero disables waiting.
+func NewIntervalLimiter(interval time.Duration) *IntervalLimiter {
+	return &IntervalLimiter{interval: interval}
+}
+
+// Wait blocks until the next slot is available or the context is done. Slots are
+// reserved in call order, so concurrent callers are spaced out rather than released together.
+func (l *IntervalLimiter) Wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var (
+	orgCreateLimiter     *IntervalLimiter
+	orgCreateLimiterOnce sync.Once
+)
+
+// WaitForOrgCreateSlot blocks until the process-wide --org-create-interval allows another
+// CreateOrg call. Workers call it immediately before CreateOrg.
+func WaitForOrgCreateSlot(ctx context.Context, logger *slog.Logger) error {
+	orgCreateLimiterOnce.Do(func() {
+		interval, _ := ctx.Value(config.OrgCreateIntervalKey).(time.Duration)
+		orgCreateLimiter = NewIntervalLimiter(interval)
+	})
+
+	if orgCreateLimiter.interval <= 0 {
+		return nil
+	}
+
+	start := time.Now()
+	if err := orgCreateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	if waited := time.Since(start); waited >= time.Millisecond {
+		logger.Info("Waited for organization creation interval", slog.Duration("waited", waited))
+	}
+	return nil
+}
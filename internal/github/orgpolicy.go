@@ -0,0 +1,340 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// ErrOrgPolicyUnsupported is returned when an organization setting from --org-policy isn't
+// available on the instance or the enterprise's plan, or the credentials can't manage it
+var ErrOrgPolicyUnsupported = errors.New("organization setting is not supported")
+
+// IPAllowList is the current state of an organization's IP allow list
+type IPAllowList struct {
+	// OwnerID is the organization's GraphQL node ID, used by the allow list mutations
+	OwnerID string
+	Enabled bool
+	// Values are the allow list entries' IP addresses and CIDR ranges
+	Values []string
+}
+
+// UpdateOrgSetting sets a single organization setting. GitHub silently ignores settings
+// the instance or plan doesn't have, so the response is checked for the new value.
+func UpdateOrgSetting(ctx context.Context, logger *slog.Logger, orgName string, name string, value interface{}) error {
+	logger.Info("Updating organization setting",
+		slog.String("org", orgName),
+		slog.String("setting", name),
+		slog.Any("value", value))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/orgs/%s", baseURL, orgName)
+
+	jsonData, err := json.Marshal(map[string]interface{}{name: value})
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPatch, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to update organization setting", slog.String("setting", name), slog.Any("error", err))
+		return err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusUnprocessableEntity:
+		logger.Warn("Organization setting not supported",
+			slog.String("org", orgName),
+			slog.String("setting", name),
+			slog.String("response", string(body)))
+		return fmt.Errorf("%w: %s", ErrOrgPolicyUnsupported, string(body))
+	default:
+		logger.Error("Failed to update organization setting",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to update organization setting %s with status %d: %s", name, status, string(body))
+	}
+
+	var updated map[string]interface{}
+	if err := json.Unmarshal(body, &updated); err != nil {
+		logger.Error("Failed to parse response", slog.Any("error", err))
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if got, ok := updated[name]; !ok || fmt.Sprint(got) != fmt.Sprint(value) {
+		logger.Warn("Organization setting was not applied",
+			slog.String("org", orgName),
+			slog.String("setting", name),
+			slog.Any("value", got))
+		return fmt.Errorf("%w: %s is not available on this instance or plan", ErrOrgPolicyUnsupported, name)
+	}
+
+	logger.Info("Successfully updated organization setting",
+		slog.String("org", orgName),
+		slog.String("setting", name))
+	return nil
+}
+
+// AssignCopilotSeat assigns a Copilot Business seat in the organization to user, who must
+// be a member. An organization without a Copilot subscription, or an instance without
+// Copilot, returns ErrOrgPolicyUnsupported. A user who already has a seat counts as assigned.
+func AssignCopilotSeat(ctx context.Context, logger *slog.Logger, orgName string, user string) error {
+	logger.Info("Assigning Copilot seat",
+		slog.String("org", orgName),
+		slog.String("user", user))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/orgs/%s/copilot/billing/selected_users", baseURL, orgName)
+
+	jsonData, err := json.Marshal(map[string]interface{}{"selected_usernames": []string{user}})
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to assign Copilot seat", slog.String("user", user), slog.Any("error", err))
+		return err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden, http.StatusUnprocessableEntity:
+		logger.Warn("Copilot seats not supported for organization",
+			slog.String("org", orgName),
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("%w: Copilot seats (status %d): %s", ErrOrgPolicyUnsupported, status, string(body))
+	default:
+		logger.Error("Failed to assign Copilot seat",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to assign Copilot seat to %s with status %d: %s", user, status, string(body))
+	}
+
+	logger.Info("Successfully assigned Copilot seat",
+		slog.String("org", orgName),
+		slog.String("user", user))
+	return nil
+}
+
+// GetOrgIPAllowList returns the organization's IP allow list entries and whether the
+// allow list is enabled
+func GetOrgIPAllowList(ctx context.Context, logger *slog.Logger, orgName string) (*IPAllowList, error) {
+	logger.Info("Getting organization IP allow list", slog.String("org", orgName))
+
+	query := `
+		query($login: String!) {
+			organization(login: $login) {
+				id
+				ipAllowListEnabledSetting
+				ipAllowListEntries(first: 100) {
+					nodes {
+						allowListValue
+					}
+				}
+			}
+		}
+	`
+	var data struct {
+		Organization *struct {
+			ID                        string `json:"id"`
+			IPAllowListEnabledSetting string `json:"ipAllowListEnabledSetting"`
+			IPAllowListEntries        struct {
+				Nodes []struct {
+					AllowListValue string `json:"allowListValue"`
+				} `json:"nodes"`
+			} `json:"ipAllowListEntries"`
+		} `json:"organization"`
+	}
+	if err := doOrgPolicyGraphQL(ctx, logger, orgName, query, map[string]interface{}{"login": orgName}, &data); err != nil {
+		return nil, err
+	}
+	if data.Organization == nil {
+		return nil, fmt.Errorf("%w: %s", ErrOrganizationNotFound, orgName)
+	}
+
+	allowList := &IPAllowList{
+		OwnerID: data.Organization.ID,
+		Enabled: data.Organization.IPAllowListEnabledSetting == "ENABLED",
+	}
+	for _, node := range data.Organization.IPAllowListEntries.Nodes {
+		allowList.Values = append(allowList.Values, node.AllowListValue)
+	}
+	return allowList, nil
+}
+
+// AddIPAllowListEntry adds an active entry to the organization's IP allow list
+func AddIPAllowListEntry(ctx context.Context, logger *slog.Logger, orgName string, ownerID string, value string, name string) error {
+	logger.Info("Adding IP allow list entry",
+		slog.String("org", orgName),
+		slog.String("value", value),
+		slog.String("name", name))
+
+	mutation := `
+		mutation($ownerId: ID!, $value: String!, $name: String) {
+			createIpAllowListEntry(input: {
+				ownerId: $ownerId
+				allowListValue: $value
+				name: $name
+				isActive: true
+			}) {
+				ipAllowListEntry {
+					id
+				}
+			}
+		}
+	`
+	variables := map[string]interface{}{
+		"ownerId": ownerID,
+		"value":   value,
+		"name":    name,
+	}
+	if err := doOrgPolicyGraphQL(ctx, logger, orgName, mutation, variables, nil); err != nil {
+		return err
+	}
+
+	logger.Info("Successfully added IP allow list entry",
+		slog.String("org", orgName),
+		slog.String("value", value))
+	return nil
+}
+
+// EnableIPAllowList turns on the organization's IP allow list. Requests from addresses
+// that aren't on the list are rejected from then on, including this tool's.
+func EnableIPAllowList(ctx context.Context, logger *slog.Logger, orgName string, ownerID string) error {
+	logger.Info("Enabling IP allow list", slog.String("org", orgName))
+
+	mutation := `
+		mutation($ownerId: ID!) {
+			updateIpAllowListEnabledSetting(input: {
+				ownerId: $ownerId
+				settingValue: ENABLED
+			}) {
+				clientMutationId
+			}
+		}
+	`
+	if err := doOrgPolicyGraphQL(ctx, logger, orgName, mutation, map[string]interface{}{"ownerId": ownerID}, nil); err != nil {
+		return err
+	}
+
+	logger.Info("Successfully enabled IP allow list", slog.String("org", orgName))
+	return nil
+}
+
+// doOrgPolicyGraphQL runs an organization-scoped GraphQL request and decodes its data
+// into out. Schema errors (the field doesn't exist on this instance) and NOT_FOUND or
+// FORBIDDEN errors return ErrOrgPolicyUnsupported.
+func doOrgPolicyGraphQL(ctx context.Context, logger *slog.Logger, orgName string, query string, variables map[string]interface{}, out interface{}) error {
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	graphqlURL := baseURL + "/graphql"
+
+	payload := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+	logGraphQLQuery(ctx, logger, payload)
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal GraphQL payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal GraphQL payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return err
+	}
+
+	if status != http.StatusOK {
+		logger.Error("GraphQL request failed",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("GraphQL request failed with status %d: %s", status, string(body))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		logger.Error("Failed to parse response", slog.Any("error", err))
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Errors) > 0 {
+		gqlErr := &GraphQLResponseError{Errors: result.Errors}
+		if gqlErr.HasType(GraphQLErrorNotFound) || gqlErr.HasType(GraphQLErrorForbidden) || isGraphQLSchemaError(result.Errors) {
+			logger.Warn("IP allow list not supported for organization",
+				slog.String("org", orgName),
+				slog.String("message", result.Errors[0].Message))
+			return fmt.Errorf("%w: %s", ErrOrgPolicyUnsupported, result.Errors[0].Message)
+		}
+		logger.Error("GraphQL errors returned",
+			slog.String("message", result.Errors[0].Message),
+			slog.String("type", result.Errors[0].Type),
+			slog.Any("errors", result.Errors))
+		return gqlErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			logger.Error("Failed to parse response", slog.Any("error", err))
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// isGraphQLSchemaError reports whether the query used a field or argument the instance's
+// schema doesn't have, as on GHES versions without IP allow lists
+func isGraphQLSchemaError(errs []GraphQLError) bool {
+	for _, gqlErr := range errs {
+		switch gqlErr.Extensions["code"] {
+		case "undefinedField", "undefinedType", "argumentNotAccepted":
+			return true
+		}
+	}
+	return false
+}
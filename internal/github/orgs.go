@@ -4,20 +4,62 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/auth"
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 )
 
+// durationFromContext returns the positive duration stored under key, or def if unset
+func durationFromContext(ctx context.Context, key interface{}, def time.Duration) time.Duration {
+	if d, ok := ctx.Value(key).(time.Duration); ok && d > 0 {
+		return d
+	}
+	return def
+}
+
+// InvalidOrgNameError is returned by CreateOrg when the org login or its
+// --org-profile-template display name can't be built for the user. It's raised before any
+// API call, so retrying won't help
+type InvalidOrgNameError struct {
+	Err error
+}
+
+func (e *InvalidOrgNameError) Error() string { return e.Err.Error() }
+
+func (e *InvalidOrgNameError) Unwrap() error { return e.Err }
+
 func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger, user string) (*Organization, error) {
-	orgName := "ghas-labs-" + ctx.Value(config.LabDateKey).(string) + "-" + user
-	logger.Info("Creating organization", slog.String("org", orgName), slog.String("user", user))
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	orgName := util.BuildOrgLogin(ctx.Value(config.LabDateKey).(string), user)
+	if err := util.ValidateOrgLogin(orgName); err != nil {
+		logger.Error("Invalid organization login", slog.String("user", user), slog.Any("error", err))
+		return nil, &InvalidOrgNameError{Err: err}
+	}
+
+	// The login stays machine-generated; --org-profile-template only sets the display name
+	profileName := orgName
+	if tmpl, _ := ctx.Value(config.OrgProfileTemplateKey).(string); tmpl != "" {
+		rendered, err := util.RenderOrgProfileName(tmpl, util.OrgProfileVars{
+			User: user,
+			Date: ctx.Value(config.LabDateKey).(string),
+			Org:  orgName,
+		})
+		if err != nil {
+			logger.Error("Invalid organization profile name", slog.String("user", user), slog.Any("error", err))
+			return nil, &InvalidOrgNameError{Err: fmt.Errorf("invalid --org-profile-template: %w", err)}
+		}
+		profileName = rendered
+	}
+
+	logger.Info("Creating organization", slog.String("org", orgName), slog.String("user", user), slog.String("profile_name", profileName))
+	ctx, cancel := context.WithTimeout(ctx, durationFromContext(ctx, config.OrgCreateTimeoutKey, config.DefaultOrgCreateTimeout))
 	defer cancel()
 
 	rt := NewGithubStyleTransport(ctx, logger, config.EnterpriseType)
@@ -47,7 +89,7 @@ func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger
 		}
 	`
 
-	facilitators := ctx.Value(config.FacilitatorsKey).([]string)
+	facilitators, _ := ctx.Value(config.FacilitatorsKey).([]string)
 	billingEmail := enterprise.BillingEmail
 	if billingEmail == "" && len(facilitators) > 0 {
 		billingEmail = facilitators[0] + "@github.com"
@@ -58,11 +100,12 @@ func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger
 		"variables": map[string]interface{}{
 			"enterpriseId": enterprise.ID,
 			"login":        orgName,
-			"profileName":  orgName,
+			"profileName":  profileName,
 			"adminLogins":  facilitators,
 			"billingEmail": billingEmail,
 		},
 	}
+	logGraphQLQuery(ctx, logger, payload)
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -75,6 +118,7 @@ func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger
 		logger.Error("Failed to create request", slog.Any("error", err))
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	setIdempotencyKey(req, "create-org", enterprise.ID, orgName)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -102,10 +146,7 @@ func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger
 				Organization Organization `json:"organization"`
 			} `json:"createEnterpriseOrganization"`
 		} `json:"data"`
-		Errors []struct {
-			Message string   `json:"message"`
-			Path    []string `json:"path"`
-		} `json:"errors"`
+		Errors []GraphQLError `json:"errors"`
 	}
 	if err := json.Unmarshal(body, &result); err != nil {
 		logger.Error("Failed to parse response", slog.Any("error", err))
@@ -116,8 +157,19 @@ func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger
 	if len(result.Errors) > 0 {
 		logger.Error("GraphQL errors returned",
 			slog.String("message", result.Errors[0].Message),
+			slog.String("type", result.Errors[0].Type),
 			slog.Any("errors", result.Errors))
-		return nil, fmt.Errorf("GraphQL errors: %v", result.Errors)
+		return nil, &GraphQLResponseError{Errors: result.Errors}
+	}
+
+	// The mutation can return a null organization without any errors, e.g. when the
+	// caller lacks permission on the enterprise
+	org := &result.Data.CreateEnterpriseOrganization.Organization
+	if org.Login == "" {
+		logger.Error("GraphQL response contained no organization",
+			slog.String("org", orgName),
+			slog.String("response", string(body)))
+		return nil, fmt.Errorf("createEnterpriseOrganization returned no organization for %s; check the token or app has permission to create organizations in the enterprise", orgName)
 	}
 
 	logger.Info("Successfully created organization",
@@ -125,8 +177,6 @@ func (enterprise *Enterprise) CreateOrg(ctx context.Context, logger *slog.Logger
 		slog.String("user", user),
 		slog.Any("response", result))
 
-	org := &result.Data.CreateEnterpriseOrganization.Organization
-
 	return org, nil
 }
 
@@ -207,9 +257,159 @@ func AddOrgMember(ctx context.Context, logger *slog.Logger, orgName string, user
 	return nil
 }
 
+// ErrNotOrgMember is returned by GetOrgMembership when the user has neither a membership
+// nor a pending invitation
+var ErrNotOrgMember = errors.New("user is not a member of the organization")
+
+// GetOrgMembership returns the user's role on the organization ("admin" or "member").
+// Pending invitations count, since the role applies once the invitation is accepted.
+func GetOrgMembership(ctx context.Context, logger *slog.Logger, orgName string, username string) (string, error) {
+	logger.Info("Getting organization membership",
+		slog.String("org", orgName),
+		slog.String("user", username))
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/orgs/%s/memberships/%s", baseURL, orgName, username)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		logger.Error("Failed to create request", slog.Any("error", err))
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read response body", slog.Any("error", err))
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s in %s", ErrNotOrgMember, username, orgName)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("Failed to get organization membership",
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("response", string(body)))
+		return "", fmt.Errorf("failed to get membership with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var membership struct {
+		State string `json:"state"`
+		Role  string `json:"role"`
+	}
+	if err := json.Unmarshal(body, &membership); err != nil {
+		logger.Error("Failed to parse response", slog.Any("error", err))
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return membership.Role, nil
+}
+
+// ListOrgAdmins returns the logins of the organization's admins. Pending invitations aren't
+// included, since an invited admin has no access until they accept. It returns
+// ErrOrganizationNotFound if the organization doesn't exist.
+func ListOrgAdmins(ctx context.Context, logger *slog.Logger, orgName string) ([]string, error) {
+	logger.Info("Listing organization admins", slog.String("org", orgName))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	admins := []string{}
+	perPage := 100
+	for page := 1; ; page++ {
+		apiURL := fmt.Sprintf("%s/orgs/%s/members?role=admin&per_page=%d&page=%d", baseURL, orgName, perPage, page)
+
+		status, body, err := doReadWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+		})
+		if err != nil {
+			logger.Error("Failed to execute request", slog.Any("error", err))
+			return nil, err
+		}
+
+		if status == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrOrganizationNotFound, orgName)
+		}
+
+		if status != http.StatusOK {
+			logger.Error("Failed to list organization admins",
+				slog.Int("status_code", status),
+				slog.String("response", string(body)))
+			return nil, fmt.Errorf("failed to list organization admins with status %d: %s", status, string(body))
+		}
+
+		var members []struct {
+			Login string `json:"login"`
+		}
+		if err := json.Unmarshal(body, &members); err != nil {
+			logger.Error("Failed to parse response", slog.Any("error", err))
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		for _, member := range members {
+			admins = append(admins, member.Login)
+		}
+
+		// A short page is the last one
+		if len(members) < perPage {
+			break
+		}
+	}
+
+	return admins, nil
+}
+
+// ErrOrgNotAllowed is returned by destructive operations on an organization that doesn't
+// match --org-allowlist
+var ErrOrgNotAllowed = errors.New("organization is not in --org-allowlist")
+
+// checkOrgAllowlist refuses target, an organization or one of its repositories, when
+// --org-allowlist is set and orgLogin matches none of its patterns. Unlike the
+// --require-prefix guard it applies to every command and can't be overridden.
+func checkOrgAllowlist(ctx context.Context, logger *slog.Logger, action string, orgLogin string, target string) error {
+	patterns, _ := ctx.Value(config.OrgAllowlistKey).([]string)
+	if len(patterns) == 0 || util.OrgAllowed(patterns, orgLogin) {
+		return nil
+	}
+	logger.Error("Refusing to act outside the organization allowlist",
+		slog.String("action", action),
+		slog.String("target", target),
+		slog.Any("org_allowlist", patterns))
+	return fmt.Errorf("%w: refusing to %s %s, which matches none of: %s", ErrOrgNotAllowed, action, target, strings.Join(patterns, ", "))
+}
+
+// DeleteOrg deletes an organization. Organizations outside --org-allowlist are refused
+// with ErrOrgNotAllowed.
 func DeleteOrg(ctx context.Context, logger *slog.Logger, orgLogin string) error {
+	if err := checkOrgAllowlist(ctx, logger, "delete organization", orgLogin, orgLogin); err != nil {
+		return err
+	}
+
 	logger.Info("Deleting organization", slog.String("org", orgLogin))
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, durationFromContext(ctx, config.OrgDeleteTimeoutKey, config.DefaultOrgDeleteTimeout))
 	defer cancel()
 
 	rt := NewGithubStyleTransport(ctx, logger, config.EnterpriseType)
@@ -240,6 +440,10 @@ func DeleteOrg(ctx context.Context, logger *slog.Logger, orgLogin string) error
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrOrganizationNotFound, orgLogin)
+	}
+
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusAccepted {
 		logger.Error("Failed to delete organization",
 			slog.Int("status_code", resp.StatusCode),
@@ -254,7 +458,46 @@ func DeleteOrg(ctx context.Context, logger *slog.Logger, orgLogin string) error
 	return nil
 }
 
-// GetOrganization retrieves an organization by name using REST API
+const (
+	orgVisibleTimeout      = 30 * time.Second
+	orgVisiblePollInterval = 2 * time.Second
+)
+
+// WaitForOrgVisible polls GetOrganization until a newly created organization can be
+// retrieved. createEnterpriseOrganization returns before the org is visible everywhere,
+// and installing the app on it too early answers 404. Gives up after orgVisibleTimeout;
+// errors other than not found are returned immediately.
+func WaitForOrgVisible(ctx context.Context, logger *slog.Logger, orgName string) error {
+	ctx, cancel := context.WithTimeout(ctx, orgVisibleTimeout)
+	defer cancel()
+
+	start := time.Now()
+	for {
+		_, err := GetOrganization(ctx, logger, orgName)
+		if err == nil {
+			logger.Info("Organization visible",
+				slog.String("org", orgName),
+				slog.Duration("waited", time.Since(start)))
+			return nil
+		}
+		if !errors.Is(err, ErrOrganizationNotFound) && ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("organization %s not visible after %s", orgName, orgVisibleTimeout)
+		case <-time.After(orgVisiblePollInterval):
+		}
+	}
+}
+
+// ErrOrganizationNotFound is returned by GetOrganization when the organization doesn't
+// exist or isn't visible to the credentials
+var ErrOrganizationNotFound = errors.New("organization not found")
+
+// GetOrganization retrieves an organization by name using REST API. It returns
+// ErrOrganizationNotFound if the organization doesn't exist.
 // Note: This returns the numeric ID from REST API, not the GraphQL node ID
 func GetOrganization(ctx context.Context, logger *slog.Logger, orgName string) (*Organization, error) {
 	logger.Info("Getting organization", slog.String("org", orgName))
@@ -288,6 +531,10 @@ func GetOrganization(ctx context.Context, logger *slog.Logger, orgName string) (
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrganizationNotFound, orgName)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("Failed to get organization",
 			slog.Int("status_code", resp.StatusCode),
@@ -319,22 +566,149 @@ func GetOrganization(ctx context.Context, logger *slog.Logger, orgName string) (
 	}, nil
 }
 
-// InstallAppOnOrg installs a GitHub App on an organization using REST API
+// CheckAppInstalledOnOrg verifies the GitHub App is installed on the organization before
+// any org-scoped work is attempted, so a missing installation is reported as a configuration
+// problem rather than a late token failure
+func CheckAppInstalledOnOrg(ctx context.Context, logger *slog.Logger, orgName string) error {
+	logger.Info("Checking GitHub App installation", slog.String("org", orgName))
+
+	ts := newTokenServiceFromContext(ctx)
+	jwt, err := ts.CreateJWT()
+	if err != nil {
+		return fmt.Errorf("failed to create JWT: %w", err)
+	}
+
+	installations, err := ts.GetInstallations(jwt)
+	if err != nil {
+		logger.Error("Failed to list app installations", slog.String("org", orgName), slog.Any("error", err))
+		return fmt.Errorf("failed to verify GitHub App installation on org %s: %w", orgName, err)
+	}
+
+	for _, installation := range installations {
+		if strings.EqualFold(installation.Account.Login, orgName) {
+			logger.Info("GitHub App is installed on organization",
+				slog.String("org", orgName),
+				slog.Int64("installation_id", installation.ID))
+			return nil
+		}
+	}
+
+	logger.Error("GitHub App is not installed on organization", slog.String("org", orgName))
+	return fmt.Errorf("GitHub App is not installed on org %s — install it first or run with --token: %w", orgName, auth.ErrNoInstallationForOrg)
+}
+
+// AppRepositorySelection is the repository access InstallAppOnOrg grants the app
+const AppRepositorySelection = "all"
+
+// Installation verification statuses reported by VerifyAppInstallation
+const (
+	InstallVerified          = "verified"
+	InstallMissing           = "missing"
+	InstallDuplicate         = "duplicate"
+	InstallSuspended         = "suspended"
+	InstallWrongRepoSelected = "wrong_repository_selection"
+	// InstallUnverified means the installations couldn't be listed
+	InstallUnverified = "unverified"
+)
+
+// InstallVerification is the outcome of checking an organization's app installation
+type InstallVerification struct {
+	Status         string `json:"status"`
+	InstallationID int64  `json:"installation_id,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// VerifyAppInstallation checks through the installations API that the organization has
+// exactly one active installation of the app with the repository selection InstallAppOnOrg
+// requests. It catches installs that were accepted but aren't in effect. An error is only
+// returned when the installations can't be listed.
+func VerifyAppInstallation(ctx context.Context, logger *slog.Logger, orgName string) (*InstallVerification, error) {
+	installations, err := ListAppInstallations(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []auth.Installation
+	for _, installation := range installations {
+		if strings.EqualFold(installation.Account.Login, orgName) {
+			matches = append(matches, installation)
+		}
+	}
+
+	result := &InstallVerification{Status: InstallVerified}
+	switch {
+	case len(matches) == 0:
+		result.Status = InstallMissing
+		result.Detail = "the app has no installation on the organization"
+	case len(matches) > 1:
+		result.Status = InstallDuplicate
+		result.Detail = fmt.Sprintf("found %d installations, expected 1", len(matches))
+	case matches[0].SuspendedAt != nil:
+		result.Status = InstallSuspended
+		result.InstallationID = matches[0].ID
+		result.Detail = fmt.Sprintf("suspended at %s", matches[0].SuspendedAt.Format(time.RFC3339))
+	case matches[0].RepositorySelection != AppRepositorySelection:
+		result.Status = InstallWrongRepoSelected
+		result.InstallationID = matches[0].ID
+		result.Detail = fmt.Sprintf("repository_selection is %q, expected %q", matches[0].RepositorySelection, AppRepositorySelection)
+	default:
+		result.InstallationID = matches[0].ID
+	}
+
+	if result.Status == InstallVerified {
+		logger.Info("Verified app installation",
+			slog.String("org", orgName),
+			slog.Int64("installation_id", result.InstallationID))
+	} else {
+		logger.Warn("App installation verification failed",
+			slog.String("org", orgName),
+			slog.String("status", result.Status),
+			slog.String("detail", result.Detail))
+	}
+	return result, nil
+}
+
+// ListAppInstallations returns every installation of the GitHub App. These are the
+// installations that token acquisition matches against by target type or org login.
+func ListAppInstallations(ctx context.Context, logger *slog.Logger) ([]auth.Installation, error) {
+	logger.Info("Listing GitHub App installations")
+
+	ts := newTokenServiceFromContext(ctx)
+	jwt, err := ts.CreateJWT()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT: %w", err)
+	}
+
+	installations, err := ts.GetInstallations(jwt)
+	if err != nil {
+		logger.Error("Failed to list app installations", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to list app installations: %w", err)
+	}
+
+	logger.Info("Retrieved GitHub App installations", slog.Int("count", len(installations)))
+	return installations, nil
+}
+
+// InstallAppOnOrg installs a GitHub App on an organization using REST API. Server errors
+// and secondary rate limits are retried. If the app is already installed (some GHES
+// versions answer 422), it returns a nil installation and no error so re-runs succeed.
 func (enterprise *Enterprise) InstallAppOnOrg(ctx context.Context, logger *slog.Logger, orgName string) (*AppInstallation, error) {
 	logger.Info("Installing app on organization",
 		slog.String("org", orgName))
 
+	// An enterprise built from --enterprise-id may have no slug, which this endpoint needs
+	if enterprise.Slug == "" {
+		return nil, fmt.Errorf("installing the app needs the enterprise slug: pass --enterprise-slug along with --enterprise-id")
+	}
+
 	//I don't love this but to get the ClientID we need to get an enterprise installation token again. Consider refactoring later.
-	ts := auth.NewTokenService(ctx.Value(config.AppIDKey).(string), ctx.Value(config.PrivateKeyKey).(string), ctx.Value(config.BaseURLKey).(string))
+	ts := newTokenServiceFromContext(ctx)
 	token, err := ts.GetInstallationToken(config.EnterpriseType)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get installation token: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
 	rt := NewGithubStyleTransport(ctx, logger, config.EnterpriseType)
 	client := &http.Client{
 		Transport: rt,
@@ -347,7 +721,7 @@ func (enterprise *Enterprise) InstallAppOnOrg(ctx context.Context, logger *slog.
 	// Prepare request body
 	payload := map[string]interface{}{
 		"client_id":            token.ClientID,
-		"repository_selection": "all",
+		"repository_selection": AppRepositorySelection,
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -356,30 +730,26 @@ func (enterprise *Enterprise) InstallAppOnOrg(ctx context.Context, logger *slog.
 		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(jsonData))
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(jsonData))
+	})
 	if err != nil {
-		logger.Error("Failed to create request", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		logger.Error("Failed to install app on organization", slog.String("org", orgName), slog.Any("error", err))
+		return nil, err
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		logger.Error("Failed to execute request", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error("Failed to read response body", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if status == http.StatusUnprocessableEntity && isAppAlreadyInstalled(body) {
+		logger.Info("App is already installed on organization",
+			slog.String("org", orgName),
+			slog.String("app_id", token.AppID))
+		return nil, nil
 	}
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+	if status != http.StatusCreated && status != http.StatusOK {
 		logger.Error("Failed to install app on organization",
-			slog.Int("status_code", resp.StatusCode),
+			slog.Int("status_code", status),
 			slog.String("response", string(body)))
-		return nil, fmt.Errorf("failed to install app with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to install app with status %d: %s", status, string(body))
 	}
 
 	var installation AppInstallation
@@ -395,3 +765,29 @@ func (enterprise *Enterprise) InstallAppOnOrg(ctx context.Context, logger *slog.
 
 	return &installation, nil
 }
+
+// isAppAlreadyInstalled reports whether a 422 response body says the app is already
+// installed on the organization
+func isAppAlreadyInstalled(body []byte) bool {
+	var errResp struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return false
+	}
+
+	messages := []string{errResp.Message}
+	for _, e := range errResp.Errors {
+		messages = append(messages, e.Message)
+	}
+	for _, message := range messages {
+		message = strings.ToLower(message)
+		if strings.Contains(message, "already installed") || strings.Contains(message, "already exists") {
+			return true
+		}
+	}
+	return false
+}
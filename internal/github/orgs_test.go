@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+func TestCreateOrg(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantLogin string
+		wantErr   bool
+		wantGQL   bool
+	}{
+		{
+			name:      "created",
+			status:    http.StatusOK,
+			body:      `{"data":{"createEnterpriseOrganization":{"organization":{"id":"O_1","login":"ghas-labs-2025-11-07-student1","name":"ghas-labs-2025-11-07-student1"}}}}`,
+			wantLogin: "ghas-labs-2025-11-07-student1",
+		},
+		{
+			name:    "graphql errors",
+			status:  http.StatusOK,
+			body:    `{"data":{"createEnterpriseOrganization":null},"errors":[{"type":"UNPROCESSABLE","message":"Login is already taken"}]}`,
+			wantErr: true,
+			wantGQL: true,
+		},
+		{
+			name:    "null organization",
+			status:  http.StatusOK,
+			body:    `{"data":{"createEnterpriseOrganization":{"organization":null}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "null mutation result",
+			status:  http.StatusOK,
+			body:    `{"data":{"createEnterpriseOrganization":null}}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty object",
+			status:  http.StatusOK,
+			body:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty body",
+			status:  http.StatusOK,
+			body:    ``,
+			wantErr: true,
+		},
+		{
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			body:    `{"message":"Bad Gateway"}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed body",
+			status:  http.StatusOK,
+			body:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.handle("POST /graphql", respond(tt.status, tt.body))
+
+			ctx := context.WithValue(testContext(fake.URL), config.FacilitatorsKey, []string{"facilitator1"})
+			enterprise := &Enterprise{ID: "E_1", Slug: "lab-enterprise"}
+			org, err := enterprise.CreateOrg(ctx, testLogger(), "student1")
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateOrg() = %+v, want error", org)
+				}
+				var gqlErr *GraphQLResponseError
+				if got := errors.As(err, &gqlErr); got != tt.wantGQL {
+					t.Errorf("CreateOrg() error %v is GraphQLResponseError = %v, want %v", err, got, tt.wantGQL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateOrg() error = %v", err)
+			}
+			if org.Login != tt.wantLogin {
+				t.Errorf("CreateOrg() login = %q, want %q", org.Login, tt.wantLogin)
+			}
+
+			requests := fake.requestsTo("POST /graphql")
+			if len(requests) != 1 {
+				t.Fatalf("got %d GraphQL requests, want 1", len(requests))
+			}
+			var payload struct {
+				Variables map[string]any `json:"variables"`
+			}
+			if err := json.Unmarshal(requests[0].Body, &payload); err != nil {
+				t.Fatalf("request body isn't JSON: %v", err)
+			}
+			if payload.Variables["login"] != tt.wantLogin || payload.Variables["enterpriseId"] != "E_1" {
+				t.Errorf("mutation variables = %v", payload.Variables)
+			}
+			if got := requests[0].Header.Get("Authorization"); got != "Bearer test-token" {
+				t.Errorf("Authorization = %q, want the PAT", got)
+			}
+		})
+	}
+}
+
+// An org name that can't be built fails with InvalidOrgNameError, without calling the API
+func TestCreateOrgInvalidName(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		template string
+	}{
+		{name: "invalid login", user: "student_1"},
+		{name: "login too long", user: "a-student-whose-handle-is-far-too-long-to-fit"},
+		{name: "invalid profile template", user: "student1", template: "{{.Missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.handle("POST /graphql", respond(http.StatusOK, `{}`))
+
+			ctx := context.WithValue(testContext(fake.URL), config.FacilitatorsKey, []string{"facilitator1"})
+			if tt.template != "" {
+				ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, tt.template)
+			}
+			enterprise := &Enterprise{ID: "E_1", Slug: "lab-enterprise"}
+			org, err := enterprise.CreateOrg(ctx, testLogger(), tt.user)
+
+			var nameErr *InvalidOrgNameError
+			if !errors.As(err, &nameErr) {
+				t.Errorf("CreateOrg() = %+v, %v, want InvalidOrgNameError", org, err)
+			}
+			if got := len(fake.requestsTo("POST /graphql")); got != 0 {
+				t.Errorf("got %d GraphQL requests, want 0", got)
+			}
+		})
+	}
+}
+
+func TestDeleteOrg(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		allowlist    []string
+		wantErr      error
+		wantAnyErr   bool
+		wantRequests int
+	}{
+		{name: "deleted", status: http.StatusNoContent, wantRequests: 1},
+		{name: "deletion accepted", status: http.StatusAccepted, wantRequests: 1},
+		{name: "not found", status: http.StatusNotFound, wantErr: ErrOrganizationNotFound, wantRequests: 1},
+		{name: "forbidden", status: http.StatusForbidden, wantAnyErr: true, wantRequests: 1},
+		{name: "outside allowlist", allowlist: []string{"other-*"}, wantErr: ErrOrgNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.handle("DELETE /orgs/ghas-labs-2025-11-07-student1", respond(tt.status, `{}`))
+
+			ctx := testContext(fake.URL)
+			if tt.allowlist != nil {
+				ctx = context.WithValue(ctx, config.OrgAllowlistKey, tt.allowlist)
+			}
+			err := DeleteOrg(ctx, testLogger(), "ghas-labs-2025-11-07-student1")
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("DeleteOrg() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAnyErr:
+				if err == nil {
+					t.Error("DeleteOrg() error = nil, want error")
+				}
+			case err != nil:
+				t.Errorf("DeleteOrg() error = %v", err)
+			}
+
+			if got := len(fake.requestsTo("DELETE /orgs/ghas-labs-2025-11-07-student1")); got != tt.wantRequests {
+				t.Errorf("got %d DELETE requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// AdaptiveLimiter bounds the number of in-flight HTTP requests using an
+// additive-increase/multiplicative-decrease (AIMD) policy. The limit is halved
+// whenever a secondary rate limit is observed and grows by one after a full
+// window of successful requests.
+type AdaptiveLimiter struct {
+	mu        sync.Mutex
+	min       int
+	max       int
+	limit     int
+	inFlight  int
+	successes int
+	lastCut   time.Time
+	wake      chan struct{}
+}
+
+// rateLimitCooldown prevents a burst of concurrent rate-limited responses from
+// collapsing the limit to the minimum in one go
+const rateLimitCooldown = 5 * time.Second
+
+// NewAdaptiveLimiter creates a limiter bounded by min and max, starting at max
+func NewAdaptiveLimiter(min, max int) *AdaptiveLimiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &AdaptiveLimiter{
+		min:   min,
+		max:   max,
+		limit: max,
+		wake:  make(chan struct{}),
+	}
+}
+
+var (
+	sharedLimiter     *AdaptiveLimiter
+	sharedLimiterOnce sync.Once
+)
+
+// getSharedLimiter returns the process-wide limiter, creating it on first use
+// from the concurrency bounds stored in the context
+func getSharedLimiter(ctx context.Context) *AdaptiveLimiter {
+	sharedLimiterOnce.Do(func() {
+		min := config.DefaultMinConcurrency
+		max := config.DefaultMaxConcurrency
+		if v, ok := ctx.Value(config.MinConcurrencyKey).(int); ok && v > 0 {
+			min = v
+		}
+		if v, ok := ctx.Value(config.MaxConcurrencyKey).(int); ok && v > 0 {
+			max = v
+		}
+		sharedLimiter = NewAdaptiveLimiter(min, max)
+	})
+	return sharedLimiter
+}
+
+// Acquire blocks until a request slot is available or the context is done
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release frees a request slot and wakes any waiters
+func (l *AdaptiveLimiter) Release() {
+	l.mu.Lock()
+	l.inFlight--
+	l.broadcast()
+	l.mu.Unlock()
+}
+
+// OnSuccess records a successful request and ramps the limit up by one after
+// a full window of successes
+func (l *AdaptiveLimiter) OnSuccess() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit >= l.max {
+		return
+	}
+	l.successes++
+	if l.successes >= l.limit {
+		l.successes = 0
+		l.limit++
+		l.broadcast()
+	}
+}
+
+// OnRateLimited halves the limit (never below min) and reports the new value
+func (l *AdaptiveLimiter) OnRateLimited(logger *slog.Logger) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.successes = 0
+	if time.Since(l.lastCut) < rateLimitCooldown {
+		return
+	}
+	l.lastCut = time.Now()
+
+	previous := l.limit
+	l.limit = l.limit / 2
+	if l.limit < l.min {
+		l.limit = l.min
+	}
+
+	if l.limit != previous {
+		logger.Warn("Secondary rate limit detected, reducing concurrency",
+			slog.Int("previous_limit", previous),
+			slog.Int("new_limit", l.limit))
+	}
+}
+
+// Limit returns the current effective concurrency
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// broadcast wakes all goroutines blocked in Acquire. Caller must hold l.mu.
+func (l *AdaptiveLimiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// isSecondaryRateLimit reports whether the response signals a GitHub secondary
+// rate limit. The body is restored so callers can still read it.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+
+	if resp.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// isRateLimitMessage reports whether a response body says a primary or secondary rate
+// limit was hit, for 403 responses whose headers are no longer at hand
+func isRateLimitMessage(body string) bool {
+	return strings.Contains(strings.ToLower(body), "rate limit")
+}
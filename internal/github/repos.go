@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,18 +13,121 @@ import (
 	"time"
 
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 )
 
-func (org *Organization) CreateRepoFromTemplate(ctx context.Context, logger *slog.Logger, templateRepo string, includeAllBranches bool) (*Repository, error) {
+// ErrRepositoryNotFound is returned by GetRepository when the repository doesn't exist or
+// isn't visible to the credentials
+var ErrRepositoryNotFound = errors.New("repository not found")
+
+// RepoDeleteError is returned by DeleteRepository when GitHub refuses the deletion, e.g.
+// with 403 when the credentials lack permission to delete repositories or a rate limit
+// outlasted the retries
+type RepoDeleteError struct {
+	Repo       string
+	StatusCode int
+	Body       string
+}
+
+func (e *RepoDeleteError) Error() string {
+	if e.StatusCode == http.StatusForbidden && isRateLimitMessage(e.Body) {
+		return fmt.Sprintf("rate limited while deleting repository %s: %s", e.Repo, e.Body)
+	}
+	if e.StatusCode == http.StatusForbidden {
+		return fmt.Sprintf("insufficient permission to delete repository %s: %s", e.Repo, e.Body)
+	}
+	return fmt.Sprintf("failed to delete repository %s with status %d: %s", e.Repo, e.StatusCode, e.Body)
+}
+
+func (org *Organization) CreateRepoFromTemplate(ctx context.Context, logger *slog.Logger, templateRepo string, opts TemplateRepoOptions) (*Repository, error) {
 	// Enrich context with org-specific information for auth scoping
 	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
-	return org.createRepoFromTemplateWithRetry(ctx, logger, templateRepo, includeAllBranches, 0)
+	repo, err := org.createRepoFromTemplateWithRetry(ctx, logger, templateRepo, opts, 0)
+	if err != nil || !opts.WaitReady {
+		return repo, err
+	}
+
+	// The repository exists but isn't populated yet; a repo that never becomes ready is
+	// still created, so this only warns and lets the follow-up steps report their own errors
+	if err := org.waitForRepoReady(ctx, logger, repo.Name); err != nil {
+		logger.Warn("Repository created but contents were not ready in time",
+			slog.String("repo", repo.FullName),
+			slog.Any("error", err))
+	}
+	return repo, nil
+}
+
+const (
+	repoReadyTimeout      = 2 * time.Minute
+	repoReadyPollInterval = 2 * time.Second
+)
+
+// waitForRepoReady polls until the repository generated from a template has its first
+// commit. The generate endpoint returns before the contents are copied, and the commits
+// endpoint answers 409 while the repository is still empty.
+func (org *Organization) waitForRepoReady(ctx context.Context, logger *slog.Logger, repoName string) error {
+	logger.Info("Waiting for repository contents",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName))
+
+	ctx, cancel := context.WithTimeout(ctx, repoReadyTimeout)
+	defer cancel()
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/commits?per_page=1", baseURL, org.Login, repoName)
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	start := time.Now()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("repository %s/%s not ready after %s", org.Login, repoName, repoReadyTimeout)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			logger.Info("Repository contents ready",
+				slog.String("org", org.Login),
+				slog.String("repo", repoName),
+				slog.Duration("waited", time.Since(start)))
+			return nil
+		case http.StatusConflict, http.StatusNotFound:
+			// Still empty, or not yet visible to the API
+		default:
+			return fmt.Errorf("unexpected status %d while waiting for repository %s/%s", resp.StatusCode, org.Login, repoName)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("repository %s/%s not ready after %s", org.Login, repoName, repoReadyTimeout)
+		case <-time.After(repoReadyPollInterval):
+		}
+	}
 }
 
-func (org *Organization) createRepoFromTemplateWithRetry(ctx context.Context, logger *slog.Logger, templateRepo string, includeAllBranches bool, retryCount int) (*Repository, error) {
+// templateRetryDelay is how long createRepoFromTemplateWithRetry waits before retrying a
+// generate request refused with "Resource not accessible by integration". A variable so
+// tests don't wait a minute.
+var templateRetryDelay = 60 * time.Second
+
+func (org *Organization) createRepoFromTemplateWithRetry(ctx context.Context, logger *slog.Logger, templateRepo string, opts TemplateRepoOptions, retryCount int) (*Repository, error) {
 	logger.Info("Creating repository from template",
 		slog.String("template", templateRepo),
-		slog.Bool("include_all_branches", includeAllBranches))
+		slog.Bool("include_all_branches", opts.IncludeAllBranches),
+		slog.Bool("private", opts.Private))
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
 
@@ -37,12 +141,24 @@ func (org *Organization) createRepoFromTemplateWithRetry(ctx context.Context, lo
 	baseURL := ctx.Value(config.BaseURLKey).(string)
 	apiURL := fmt.Sprintf("%s/repos/%s/%s/generate", baseURL, templateOwner, templateRepoName)
 
+	repoName := opts.Name
+	if repoName == "" {
+		repoName = templateRepoName
+	}
+	description := opts.Description
+	if description == "" && !opts.NoDescription {
+		description = fmt.Sprintf("Repository created from template %s", templateRepo)
+	}
+	if description != "" && opts.Marker != "" {
+		description += " " + opts.Marker
+	}
+
 	payload := map[string]interface{}{
 		"owner":                org.Login,
-		"name":                 templateRepoName,
-		"description":          fmt.Sprintf("Repository created from template %s", templateRepo),
-		"include_all_branches": includeAllBranches,
-		"private":              true,
+		"name":                 repoName,
+		"description":          description,
+		"include_all_branches": opts.IncludeAllBranches,
+		"private":              opts.Private,
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -62,6 +178,7 @@ func (org *Organization) createRepoFromTemplateWithRetry(ctx context.Context, lo
 		logger.Error("Failed to create request", slog.Any("error", err))
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	setIdempotencyKey(req, "generate-repo", templateRepo, org.Login, repoName)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -86,9 +203,13 @@ func (org *Organization) createRepoFromTemplateWithRetry(ctx context.Context, lo
 				logger.Warn("Rate limit hit, retrying after delay",
 					slog.Int("retry_count", retryCount))
 
-				logger.Debug("Sleeping for 60 seconds before retry")
-				time.Sleep(60 * time.Second)
-				return org.createRepoFromTemplateWithRetry(ctx, logger, templateRepo, includeAllBranches, retryCount)
+				logger.Debug("Sleeping before retry", slog.Duration("delay", templateRetryDelay))
+				select {
+				case <-time.After(templateRetryDelay):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+				return org.createRepoFromTemplateWithRetry(ctx, logger, templateRepo, opts, retryCount)
 			}
 		}
 		logger.Error("Failed to create repository from template",
@@ -111,24 +232,103 @@ func (org *Organization) createRepoFromTemplateWithRetry(ctx context.Context, lo
 	return &result, nil
 }
 
-// DeleteRepository deletes a repository in the organization
-func (org *Organization) DeleteRepository(ctx context.Context, logger *slog.Logger, repoName string) error {
-	logger.Info("Deleting repository",
+// CreateRepository creates an empty repository in the organization, e.g. as the
+// destination of ImportRepository
+func (org *Organization) CreateRepository(ctx context.Context, logger *slog.Logger, name string, description string, private bool) (*Repository, error) {
+	logger.Info("Creating empty repository",
+		slog.String("org", org.Login),
+		slog.String("repo", name),
+		slog.Bool("private", private))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/orgs/%s/repos", baseURL, org.Login)
+
+	payload := map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"private":     private,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		setIdempotencyKey(req, "create-repo", org.Login, name)
+		return req, nil
+	})
+	if err != nil {
+		logger.Error("Failed to create repository", slog.String("repo", name), slog.Any("error", err))
+		return nil, err
+	}
+
+	if status != http.StatusCreated {
+		logger.Error("Failed to create repository",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return nil, fmt.Errorf("failed to create repository %s with status %d: %s", name, status, string(body))
+	}
+
+	var result Repository
+	if err := json.Unmarshal(body, &result); err != nil {
+		logger.Error("Failed to parse response", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	logger.Info("Successfully created repository",
+		slog.String("repository", result.FullName),
+		slog.String("url", result.HTMLURL))
+
+	return &result, nil
+}
+
+// RenameBranch renames a branch in one of the organization's repositories. Renaming the
+// default branch also updates the repository's default branch setting.
+func (org *Organization) RenameBranch(ctx context.Context, logger *slog.Logger, repoName string, branch string, newName string) error {
+	logger.Info("Renaming branch",
+		slog.String("org", org.Login),
 		slog.String("repo", repoName),
-		slog.String("org", org.Name))
+		slog.String("branch", branch),
+		slog.String("new_name", newName))
 
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	baseURL := ctx.Value(config.BaseURLKey).(string)
-	apiURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, org.Name, repoName)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/branches/%s/rename", baseURL, org.Login, repoName, branch)
+
+	payload := map[string]interface{}{
+		"new_name": newName,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
 
 	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
 	client := &http.Client{
 		Transport: rt,
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		logger.Error("Failed to create request", slog.Any("error", err))
 		return fmt.Errorf("failed to create request: %w", err)
@@ -141,31 +341,374 @@ func (org *Organization) DeleteRepository(ctx context.Context, logger *slog.Logg
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
+	if resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		logger.Error("Failed to delete repository",
+		logger.Error("Failed to rename branch",
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to rename branch with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	logger.Info("Successfully renamed branch",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.String("new_name", newName))
+
+	return nil
+}
+
+// ReplaceTopics replaces all topics on one of the organization's repositories
+func (org *Organization) ReplaceTopics(ctx context.Context, logger *slog.Logger, repoName string, topics []string) error {
+	logger.Info("Setting repository topics",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.Any("topics", topics))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/topics", baseURL, org.Login, repoName)
+
+	payload := map[string]interface{}{
+		"names": topics,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Error("Failed to create request", slog.Any("error", err))
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		logger.Error("Failed to set repository topics",
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to set repository topics with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	logger.Info("Successfully set repository topics",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName))
+
+	return nil
+}
+
+// UpdateRepoSettings applies the set fields of settings (issues, wiki, projects, homepage
+// and description) to one of the organization's repositories with a single update
+func (org *Organization) UpdateRepoSettings(ctx context.Context, logger *slog.Logger, repoName string, settings util.RepoSettings) error {
+	payload := settings.Payload()
+	if len(payload) == 0 {
+		return nil
+	}
+	logger.Info("Updating repository settings",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.Any("settings", payload))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, org.Login, repoName)
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPatch, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to update repository settings", slog.String("repo", repoName), slog.Any("error", err))
+		return err
+	}
+
+	if status != http.StatusOK {
+		logger.Error("Failed to update repository settings",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to update repository settings with status %d: %s", status, string(body))
+	}
+
+	logger.Info("Successfully updated repository settings",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName))
+
+	return nil
+}
+
+// TransferRepository starts transferring one of the organization's repositories to newOwner.
+// GitHub processes transfers asynchronously, so the repository may not exist under the new
+// owner when this returns; use GetRepository to confirm completion.
+func (org *Organization) TransferRepository(ctx context.Context, logger *slog.Logger, repoName string, newOwner string) error {
+	logger.Info("Transferring repository",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.String("new_owner", newOwner))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/transfer", baseURL, org.Login, repoName)
+
+	payload := map[string]interface{}{
+		"new_owner": newOwner,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Error("Failed to create request", slog.Any("error", err))
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		logger.Error("Failed to transfer repository",
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("response", string(body)))
+		return fmt.Errorf("failed to transfer repository with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	logger.Info("Repository transfer accepted",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.String("new_owner", newOwner))
+
+	return nil
+}
+
+// GetRepository fetches a repository by owner and name. It returns ErrRepositoryNotFound
+// if the repository doesn't exist or isn't visible.
+func GetRepository(ctx context.Context, logger *slog.Logger, owner string, repoName string) (*Repository, error) {
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, owner)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repoName)
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		logger.Error("Failed to create request", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Failed to execute request", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read response body", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRepositoryNotFound, owner, repoName)
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("Failed to get repository",
 			slog.Int("status_code", resp.StatusCode),
 			slog.String("response", string(body)))
-		return fmt.Errorf("failed to delete repository with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to get repository with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var repo Repository
+	if err := json.Unmarshal(body, &repo); err != nil {
+		logger.Error("Failed to parse response", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &repo, nil
+}
+
+// DeleteRepository deletes a repository in the organization, retrying 5xx responses and
+// secondary rate limits. A repository that's already gone returns ErrRepositoryNotFound,
+// which re-runs can treat as success; other refusals return a *RepoDeleteError.
+// Repositories of organizations outside --org-allowlist are refused with ErrOrgNotAllowed.
+func (org *Organization) DeleteRepository(ctx context.Context, logger *slog.Logger, repoName string) error {
+	if err := checkOrgAllowlist(ctx, logger, "delete repository", org.Login, org.Login+"/"+repoName); err != nil {
+		return err
+	}
+
+	logger.Info("Deleting repository",
+		slog.String("repo", repoName),
+		slog.String("org", org.Login))
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, org.Login, repoName)
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodDelete, apiURL, nil)
+	})
+	if err != nil {
+		logger.Error("Failed to delete repository", slog.String("repo", repoName), slog.Any("error", err))
+		return err
+	}
+
+	if status == http.StatusNotFound {
+		logger.Info("Repository already deleted",
+			slog.String("repo", repoName),
+			slog.String("org", org.Login))
+		return fmt.Errorf("%w: %s/%s", ErrRepositoryNotFound, org.Login, repoName)
+	}
+
+	if status != http.StatusNoContent {
+		logger.Error("Failed to delete repository",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return &RepoDeleteError{Repo: org.Login + "/" + repoName, StatusCode: status, Body: string(body)}
 	}
 
 	logger.Info("Successfully deleted repository",
 		slog.String("repo", repoName),
-		slog.String("org", org.Name))
+		slog.String("org", org.Login))
 
 	return nil
 }
 
-// ListRepositories lists all repositories in the organization
+// AddCollaborator grants user the permission (pull, triage, push, maintain or admin) on
+// the repository. Users outside the organization receive an invitation they must accept;
+// returns true when an invitation was created rather than access granted directly.
+func (org *Organization) AddCollaborator(ctx context.Context, logger *slog.Logger, repoName string, user string, permission string) (bool, error) {
+	logger.Info("Adding repository collaborator",
+		slog.String("org", org.Login),
+		slog.String("repo", repoName),
+		slog.String("user", user),
+		slog.String("permission", permission))
+
+	// Enrich context with org-specific information for auth scoping
+	ctx = context.WithValue(ctx, config.OrgKey, org.Login)
+
+	baseURL := ctx.Value(config.BaseURLKey).(string)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/collaborators/%s", baseURL, org.Login, repoName, user)
+
+	payload := map[string]interface{}{
+		"permission": permission,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal request payload", slog.Any("error", err))
+		return false, fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	rt := NewGithubStyleTransport(ctx, logger, config.OrganizationType)
+	client := &http.Client{
+		Transport: rt,
+	}
+
+	status, body, err := doWithTransientRetry(ctx, logger, client, 30*time.Second, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPut, apiURL, bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		logger.Error("Failed to add repository collaborator", slog.String("user", user), slog.Any("error", err))
+		return false, err
+	}
+
+	// 201 means an invitation was created, 204 that the user already had access
+	if status != http.StatusCreated && status != http.StatusNoContent {
+		logger.Error("Failed to add repository collaborator",
+			slog.Int("status_code", status),
+			slog.String("response", string(body)))
+		return false, fmt.Errorf("failed to add collaborator %s with status %d: %s", user, status, string(body))
+	}
+
+	logger.Info("Successfully added repository collaborator",
+		slog.String("repo", repoName),
+		slog.String("user", user),
+		slog.Bool("invited", status == http.StatusCreated))
+
+	return status == http.StatusCreated, nil
+}
+
+// ListRepositories lists the names of all repositories in the organization
 func (org *Organization) ListRepositories(ctx context.Context, logger *slog.Logger) ([]string, error) {
-	logger.Info("Listing repositories in organization", slog.String("org", org.Name))
+	repos, err := org.ListRepositoryDetails(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(repos))
+	for i, repo := range repos {
+		names[i] = repo.Name
+	}
+	return names, nil
+}
+
+// ListRepositoryDetails lists all repositories in the organization with their descriptions
+func (org *Organization) ListRepositoryDetails(ctx context.Context, logger *slog.Logger) ([]Repository, error) {
+	logger.Info("Listing repositories in organization", slog.String("org", org.Login))
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	baseURL := ctx.Value(config.BaseURLKey).(string)
 
-	var allRepos []string
+	var allRepos []Repository
 	page := 1
 	perPage := 100
 
@@ -175,7 +718,7 @@ func (org *Organization) ListRepositories(ctx context.Context, logger *slog.Logg
 	}
 
 	for {
-		apiURL := fmt.Sprintf("%s/orgs/%s/repos?per_page=%d&page=%d&type=all", baseURL, org.Name, perPage, page)
+		apiURL := fmt.Sprintf("%s/orgs/%s/repos?per_page=%d&page=%d&type=all", baseURL, org.Login, perPage, page)
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 		if err != nil {
@@ -203,9 +746,7 @@ func (org *Organization) ListRepositories(ctx context.Context, logger *slog.Logg
 			return nil, fmt.Errorf("failed to list repositories with status %d: %s", resp.StatusCode, string(body))
 		}
 
-		var repos []struct {
-			Name string `json:"name"`
-		}
+		var repos []Repository
 		if err := json.Unmarshal(body, &repos); err != nil {
 			logger.Error("Failed to parse response", slog.Any("error", err))
 			return nil, fmt.Errorf("failed to parse response: %w", err)
@@ -216,10 +757,7 @@ func (org *Organization) ListRepositories(ctx context.Context, logger *slog.Logg
 			break
 		}
 
-		// Add repo names to the list
-		for _, repo := range repos {
-			allRepos = append(allRepos, repo.Name)
-		}
+		allRepos = append(allRepos, repos...)
 
 		// If we got fewer repos than requested, we're done
 		if len(repos) < perPage {
@@ -231,7 +769,7 @@ func (org *Organization) ListRepositories(ctx context.Context, logger *slog.Logg
 
 	logger.Info("Found repositories",
 		slog.Int("count", len(allRepos)),
-		slog.String("org", org.Name))
+		slog.String("org", org.Login))
 
 	return allRepos, nil
 }
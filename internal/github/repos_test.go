@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCreateRepoFromTemplate(t *testing.T) {
+	created := respond(http.StatusCreated, `{"id":1,"name":"demo","full_name":"lab-org/demo","html_url":"https://github.com/lab-org/demo"}`)
+	notAccessible := respond(http.StatusUnprocessableEntity, `{"message":"Resource not accessible by integration"}`)
+
+	tests := []struct {
+		name         string
+		template     string
+		handler      http.HandlerFunc
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "created", template: "templates/demo", handler: created, wantRequests: 1},
+		{name: "retried after not accessible", template: "templates/demo", handler: sequence(notAccessible, created), wantRequests: 2},
+		{
+			name:         "other 422 not retried",
+			template:     "templates/demo",
+			handler:      respond(http.StatusUnprocessableEntity, `{"message":"Name already exists on this account"}`),
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "template not found",
+			template:     "templates/demo",
+			handler:      respond(http.StatusNotFound, `{"message":"Not Found"}`),
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{name: "invalid template name", template: "demo", handler: created, wantErr: true},
+	}
+
+	delay := templateRetryDelay
+	templateRetryDelay = 10 * time.Millisecond
+	t.Cleanup(func() { templateRetryDelay = delay })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.handle("POST /repos/templates/demo/generate", tt.handler)
+
+			org := &Organization{Login: "lab-org"}
+			repo, err := org.CreateRepoFromTemplate(testContext(fake.URL), testLogger(), tt.template, TemplateRepoOptions{Private: true})
+
+			requests := fake.requestsTo("POST /repos/templates/demo/generate")
+			if len(requests) != tt.wantRequests {
+				t.Errorf("got %d generate requests, want %d", len(requests), tt.wantRequests)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("CreateRepoFromTemplate() = %+v, want error", repo)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateRepoFromTemplate() error = %v", err)
+			}
+			if repo.FullName != "lab-org/demo" {
+				t.Errorf("CreateRepoFromTemplate() full name = %q, want lab-org/demo", repo.FullName)
+			}
+
+			var payload map[string]any
+			if err := json.Unmarshal(requests[0].Body, &payload); err != nil {
+				t.Fatalf("request body isn't JSON: %v", err)
+			}
+			if payload["owner"] != "lab-org" || payload["name"] != "demo" || payload["private"] != true {
+				t.Errorf("generate payload = %v", payload)
+			}
+			// Every retry of the same creation carries the same idempotency key
+			if key := requests[0].Header.Get(idempotencyKeyHeader); key == "" || key != requests[len(requests)-1].Header.Get(idempotencyKeyHeader) {
+				t.Errorf("idempotency keys differ across attempts or are missing")
+			}
+		})
+	}
+}
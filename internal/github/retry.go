@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	transientMaxAttempts = 3
+	transientBaseDelay   = 2 * time.Second
+)
+
+// isTransientFailure reports whether a response is worth retrying: a server error or a
+// secondary rate limit
+func isTransientFailure(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusInternalServerError || isSecondaryRateLimit(resp)
+}
+
+// retryDelay returns how long to wait before the given retry, honoring Retry-After when
+// the server sent one
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	delay := transientBaseDelay << (attempt - 1)
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		if retryAfter := time.Duration(seconds) * time.Second; retryAfter > delay {
+			delay = retryAfter
+		}
+	}
+	return delay
+}
+
+// doWithTransientRetry sends the request built by newReq, retrying 5xx responses and
+// secondary rate limits with exponential backoff. newReq is called for every attempt so
+// the body can be resent, with a context bounded by attemptTimeout. Returns the status
+// code and body of the last response; other failures are returned without retrying.
+func doWithTransientRetry(ctx context.Context, logger *slog.Logger, client *http.Client, attemptTimeout time.Duration, newReq func(ctx context.Context) (*http.Request, error)) (int, []byte, error) {
+	return doWithRetry(ctx, logger, client, attemptTimeout, false, newReq)
+}
+
+// doReadWithTransientRetry is doWithTransientRetry for requests that are safe to repeat,
+// such as queries: network errors and attempt timeouts are retried as well
+func doReadWithTransientRetry(ctx context.Context, logger *slog.Logger, client *http.Client, attemptTimeout time.Duration, newReq func(ctx context.Context) (*http.Request, error)) (int, []byte, error) {
+	return doWithRetry(ctx, logger, client, attemptTimeout, true, newReq)
+}
+
+func doWithRetry(ctx context.Context, logger *slog.Logger, client *http.Client, attemptTimeout time.Duration, retryNetworkErrors bool, newReq func(ctx context.Context) (*http.Request, error)) (int, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		status, body, retryIn, err := doAttempt(ctx, client, attemptTimeout, newReq, attempt, retryNetworkErrors)
+		if retryIn == 0 {
+			return status, body, err
+		}
+		globalAPICallStats.recordRetry()
+
+		if err != nil {
+			logger.Warn("Transient network failure, retrying after delay",
+				slog.Any("error", err),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", retryIn))
+		} else {
+			logger.Warn("Transient API failure, retrying after delay",
+				slog.Int("status_code", status),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", retryIn))
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(retryIn):
+		}
+	}
+}
+
+// doAttempt performs a single attempt of doWithRetry. retryIn is non-zero when the
+// response was transient, or the request failed and retryNetworkErrors is set, and
+// attempts remain.
+func doAttempt(ctx context.Context, client *http.Client, attemptTimeout time.Duration, newReq func(ctx context.Context) (*http.Request, error), attempt int, retryNetworkErrors bool) (status int, body []byte, retryIn time.Duration, err error) {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
+	req, err := newReq(ctx)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// A cancelled run isn't a network failure
+		if retryNetworkErrors && attempt < transientMaxAttempts && parent.Err() == nil {
+			retryIn = transientBaseDelay << (attempt - 1)
+		}
+		return 0, nil, retryIn, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if attempt < transientMaxAttempts && isTransientFailure(resp) {
+		retryIn = retryDelay(resp, attempt)
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp.StatusCode, body, retryIn, nil
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+var (
+	sharedTransport     http.RoundTripper
+	sharedTransportOnce sync.Once
+)
+
+// getSharedTransport returns the process-wide base transport, creating it on first use.
+// With --max-conns it bounds the TCP connections opened to each host, which the request
+// limiter alone doesn't; otherwise it is http.DefaultTransport.
+func getSharedTransport(ctx context.Context) http.RoundTripper {
+	sharedTransportOnce.Do(func() {
+		maxConns, _ := ctx.Value(config.MaxConnsKey).(int)
+		base, ok := http.DefaultTransport.(*http.Transport)
+		if maxConns <= 0 || !ok {
+			sharedTransport = http.DefaultTransport
+			return
+		}
+		transport := base.Clone()
+		transport.MaxConnsPerHost = maxConns
+		transport.MaxIdleConnsPerHost = maxConns
+		sharedTransport = transport
+	})
+	return sharedTransport
+}
@@ -14,9 +14,29 @@ type Organization struct {
 }
 
 type Repository struct {
-	ID       int64  `json:"id"`
-	FullName string `json:"full_name"`
-	HTMLURL  string `json:"html_url"`
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	HTMLURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+	Description   string `json:"description"`
+}
+
+// TemplateRepoOptions controls how a repository is generated from a template
+type TemplateRepoOptions struct {
+	IncludeAllBranches bool
+	Private            bool
+	// Name and Description default to the template's name and a generated description
+	Name        string
+	Description string
+	// NoDescription leaves the description empty instead of generating one when
+	// Description isn't set
+	NoDescription bool
+	// Marker is appended to the description so lab repositories can be found by content;
+	// it is left out when the description is empty
+	Marker string
+	// WaitReady waits for the generated repository's first commit before returning
+	WaitReady bool
 }
 
 type AppInstallation struct {
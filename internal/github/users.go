@@ -11,10 +11,25 @@ import (
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 )
 
+// InvalidUser is a username that was skipped and the reason why
+type InvalidUser struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// InvalidUserNames returns just the usernames of the invalid users
+func InvalidUserNames(users []InvalidUser) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Name
+	}
+	return names
+}
+
 // UserValidationResult contains the results of user validation
 type UserValidationResult struct {
 	ValidUsers   []string
-	InvalidUsers []string
+	InvalidUsers []InvalidUser
 }
 
 // ValidateAndFilterUsers checks if all provided usernames exist in GitHub Enterprise
@@ -23,7 +38,7 @@ func ValidateAndFilterUsers(ctx context.Context, logger *slog.Logger, usernames
 	if len(usernames) == 0 {
 		return &UserValidationResult{
 			ValidUsers:   []string{},
-			InvalidUsers: []string{},
+			InvalidUsers: []InvalidUser{},
 		}, nil
 	}
 
@@ -40,20 +55,26 @@ func ValidateAndFilterUsers(ctx context.Context, logger *slog.Logger, usernames
 	baseURL := ctx.Value(config.BaseURLKey).(string)
 
 	type validationResult struct {
+		index    int
 		username string
 		valid    bool
+		reason   string
 		err      error
 	}
 
 	resultChan := make(chan validationResult, len(usernames))
 	var wg sync.WaitGroup
 
-	// Validate users concurrently (max 10 at a time to avoid rate limits)
-	semaphore := make(chan struct{}, 10)
+	// Validate users concurrently, bounded by --validation-concurrency to avoid rate limits
+	concurrency, ok := ctx.Value(config.ValidationConcurrencyKey).(int)
+	if !ok || concurrency < 1 {
+		concurrency = config.DefaultValidationConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
 
-	for _, username := range usernames {
+	for i, username := range usernames {
 		wg.Add(1)
-		go func(user string) {
+		go func(index int, user string) {
 			defer wg.Done()
 
 			semaphore <- struct{}{}
@@ -61,7 +82,7 @@ func ValidateAndFilterUsers(ctx context.Context, logger *slog.Logger, usernames
 
 			select {
 			case <-ctx.Done():
-				resultChan <- validationResult{username: user, valid: false, err: ctx.Err()}
+				resultChan <- validationResult{index: index, username: user, valid: false, reason: "skipped: validation timed out", err: ctx.Err()}
 				return
 			default:
 			}
@@ -69,30 +90,35 @@ func ValidateAndFilterUsers(ctx context.Context, logger *slog.Logger, usernames
 			userURL := fmt.Sprintf("%s/users/%s", baseURL, user)
 			req, err := http.NewRequestWithContext(ctx, http.MethodGet, userURL, nil)
 			if err != nil {
-				resultChan <- validationResult{username: user, valid: false, err: err}
+				resultChan <- validationResult{index: index, username: user, valid: false, reason: fmt.Sprintf("illegal username: %v", err), err: err}
 				return
 			}
 
 			resp, err := client.Do(req)
 			if err != nil {
-				resultChan <- validationResult{username: user, valid: false, err: err}
+				resultChan <- validationResult{index: index, username: user, valid: false, reason: fmt.Sprintf("request failed: %v", err), err: err}
 				return
 			}
 			resp.Body.Close()
 
 			if resp.StatusCode == http.StatusNotFound {
 				logger.Warn("User not found - will be skipped", slog.String("username", user))
-				resultChan <- validationResult{username: user, valid: false, err: nil}
+				resultChan <- validationResult{index: index, username: user, valid: false, reason: "not found", err: nil}
+			} else if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+				logger.Warn("Rate limited while validating user - will be skipped",
+					slog.String("username", user),
+					slog.Int("status", resp.StatusCode))
+				resultChan <- validationResult{index: index, username: user, valid: false, reason: fmt.Sprintf("rate limited and skipped (status %d)", resp.StatusCode), err: fmt.Errorf("unexpected status: %d", resp.StatusCode)}
 			} else if resp.StatusCode != http.StatusOK {
 				logger.Warn("Unexpected status for user - will be skipped",
 					slog.String("username", user),
 					slog.Int("status", resp.StatusCode))
-				resultChan <- validationResult{username: user, valid: false, err: fmt.Errorf("unexpected status: %d", resp.StatusCode)}
+				resultChan <- validationResult{index: index, username: user, valid: false, reason: fmt.Sprintf("unexpected status %d", resp.StatusCode), err: fmt.Errorf("unexpected status: %d", resp.StatusCode)}
 			} else {
 				logger.Info("User validated", slog.String("username", user))
-				resultChan <- validationResult{username: user, valid: true, err: nil}
+				resultChan <- validationResult{index: index, username: user, valid: true, err: nil}
 			}
-		}(username)
+		}(i, username)
 	}
 
 	go func() {
@@ -100,27 +126,26 @@ func ValidateAndFilterUsers(ctx context.Context, logger *slog.Logger, usernames
 		close(resultChan)
 	}()
 
-	validationMap := make(map[string]bool)
-	invalidUsers := []string{}
-
+	// Results arrive in completion order; place them by input index so both lists keep
+	// the order of the users file and repeated runs produce identical reports
+	results := make([]validationResult, len(usernames))
 	for result := range resultChan {
-		if result.valid {
-			validationMap[result.username] = true
-		} else {
-			invalidUsers = append(invalidUsers, result.username)
-		}
+		results[result.index] = result
 	}
 
 	validUsers := make([]string, 0, len(usernames))
-	for _, username := range usernames {
-		if validationMap[username] {
-			validUsers = append(validUsers, username)
+	invalidUsers := []InvalidUser{}
+	for _, result := range results {
+		if result.valid {
+			validUsers = append(validUsers, result.username)
+		} else {
+			invalidUsers = append(invalidUsers, InvalidUser{Name: result.username, Reason: result.reason})
 		}
 	}
 
 	if len(invalidUsers) > 0 {
 		logger.Warn("Invalid users found and removed",
-			slog.Any("invalid_users", invalidUsers),
+			slog.Any("invalid_users", InvalidUserNames(invalidUsers)),
 			slog.Int("invalid_count", len(invalidUsers)),
 			slog.Int("valid_count", len(validUsers)),
 			slog.Int("total_count", len(usernames)))
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestValidateAndFilterUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantValid   []string
+		wantInvalid []InvalidUser
+	}{
+		{
+			name:        "found",
+			status:      http.StatusOK,
+			wantValid:   []string{"alice", "bob"},
+			wantInvalid: []InvalidUser{},
+		},
+		{
+			name:        "not found",
+			status:      http.StatusNotFound,
+			wantValid:   []string{"alice"},
+			wantInvalid: []InvalidUser{{Name: "bob", Reason: "not found"}},
+		},
+		{
+			name:        "forbidden",
+			status:      http.StatusForbidden,
+			wantValid:   []string{"alice"},
+			wantInvalid: []InvalidUser{{Name: "bob", Reason: "rate limited and skipped (status 403)"}},
+		},
+		{
+			name:        "server error",
+			status:      http.StatusInternalServerError,
+			wantValid:   []string{"alice"},
+			wantInvalid: []InvalidUser{{Name: "bob", Reason: "unexpected status 500"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.handle("GET /users/alice", respond(http.StatusOK, `{"login":"alice"}`))
+			fake.handle("GET /users/bob", respond(tt.status, `{"login":"bob"}`))
+
+			result, err := ValidateAndFilterUsers(testContext(fake.URL), testLogger(), []string{"alice", "bob"})
+			if err != nil {
+				t.Fatalf("ValidateAndFilterUsers() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.ValidUsers, tt.wantValid) {
+				t.Errorf("valid users = %v, want %v", result.ValidUsers, tt.wantValid)
+			}
+			if !reflect.DeepEqual(result.InvalidUsers, tt.wantInvalid) {
+				t.Errorf("invalid users = %v, want %v", result.InvalidUsers, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestValidateAndFilterUsersNoneValid(t *testing.T) {
+	fake := newFakeGitHub(t)
+	fake.handle("GET /users/alice", respond(http.StatusNotFound, `{"message":"Not Found"}`))
+
+	if _, err := ValidateAndFilterUsers(testContext(fake.URL), testLogger(), []string{"alice"}); err == nil {
+		t.Error("ValidateAndFilterUsers() error = nil, want an error when no user is valid")
+	}
+}
+
+// Invalid users are listed in input order with their reasons, whatever order the
+// concurrent lookups complete in
+func TestValidateAndFilterUsersStableOrder(t *testing.T) {
+	fake := newFakeGitHub(t)
+	for _, user := range []string{"alice", "carol", "erin"} {
+		fake.handle("GET /users/"+user, respond(http.StatusOK, `{}`))
+	}
+	for _, user := range []string{"dave", "bob"} {
+		fake.handle("GET /users/"+user, respond(http.StatusNotFound, `{"message":"Not Found"}`))
+	}
+	for _, user := range []string{"zed", "frank"} {
+		fake.handle("GET /users/"+user, respond(http.StatusForbidden, `{"message":"Forbidden"}`))
+	}
+
+	users := []string{"zed", "alice", "dave", "carol", "bob", "frank", "erin"}
+	wantValid := []string{"alice", "carol", "erin"}
+	wantInvalid := []InvalidUser{
+		{Name: "zed", Reason: "rate limited and skipped (status 403)"},
+		{Name: "dave", Reason: "not found"},
+		{Name: "bob", Reason: "not found"},
+		{Name: "frank", Reason: "rate limited and skipped (status 403)"},
+	}
+
+	for run := 0; run < 5; run++ {
+		result, err := ValidateAndFilterUsers(testContext(fake.URL), testLogger(), users)
+		if err != nil {
+			t.Fatalf("ValidateAndFilterUsers() error = %v", err)
+		}
+		if !reflect.DeepEqual(result.ValidUsers, wantValid) {
+			t.Errorf("run %d: valid users = %v, want %v", run, result.ValidUsers, wantValid)
+		}
+		if !reflect.DeepEqual(result.InvalidUsers, wantInvalid) {
+			t.Errorf("run %d: invalid users = %v, want %v", run, result.InvalidUsers, wantInvalid)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// OrgAdminAudit is the admin audit result for one lab organization
+type OrgAdminAudit struct {
+	User    string   `json:"user"`
+	OrgName string   `json:"org_name"`
+	Admins  []string `json:"admins"`
+	// Missing lists the expected admins that aren't admins of the org
+	Missing []string `json:"missing,omitempty"`
+	// Unexpected lists the admins that are neither expected nor allowed
+	Unexpected []string `json:"unexpected,omitempty"`
+	// NotFound is set when the organization doesn't exist
+	NotFound bool   `json:"not_found,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Compliant reports whether the org exists and its admins are exactly the expected ones,
+// plus any allowed extras
+func (a OrgAdminAudit) Compliant() bool {
+	return !a.NotFound && a.Error == "" && len(a.Missing) == 0 && len(a.Unexpected) == 0
+}
+
+// AuditOrgAdmins lists the admins of each user's lab organization and compares them with
+// the expected admins: the user, and the facilitators when facilitatorRole is admin.
+// allowedAdmins are extra logins, such as an ops account, that aren't flagged. Nothing
+// is changed. Results follow the order of users.
+func AuditOrgAdmins(ctx context.Context, logger *slog.Logger, labDate string, users []string, facilitators []string, facilitatorRole string, allowedAdmins []string) []OrgAdminAudit {
+	allowed := make(map[string]bool, len(allowedAdmins))
+	for _, login := range allowedAdmins {
+		allowed[strings.ToLower(login)] = true
+	}
+
+	concurrency, ok := ctx.Value(config.ValidationConcurrencyKey).(int)
+	if !ok || concurrency < 1 {
+		concurrency = config.DefaultValidationConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	audits := make([]OrgAdminAudit, len(users))
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(index int, user string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			expected := []string{user}
+			if facilitatorRole == "admin" {
+				expected = append(expected, facilitators...)
+			}
+			audits[index] = auditOrgAdmins(ctx, logger, util.BuildOrgLogin(labDate, user), user, expected, allowed)
+		}(i, user)
+	}
+	wg.Wait()
+
+	flagged := 0
+	for _, audit := range audits {
+		if !audit.Compliant() {
+			flagged++
+		}
+	}
+	logger.Info("Organization admin audit complete",
+		slog.Int("org_count", len(audits)),
+		slog.Int("flagged_count", flagged))
+
+	return audits
+}
+
+// auditOrgAdmins compares one organization's admins with the expected logins
+func auditOrgAdmins(ctx context.Context, logger *slog.Logger, orgName string, user string, expected []string, allowed map[string]bool) OrgAdminAudit {
+	audit := OrgAdminAudit{User: user, OrgName: orgName}
+
+	admins, err := api.ListOrgAdmins(ctx, logger, orgName)
+	if err != nil {
+		if errors.Is(err, api.ErrOrganizationNotFound) {
+			audit.NotFound = true
+		} else {
+			audit.Error = err.Error()
+		}
+		return audit
+	}
+	audit.Admins = admins
+
+	current := make(map[string]bool, len(admins))
+	for _, admin := range admins {
+		current[strings.ToLower(admin)] = true
+	}
+
+	wanted := make(map[string]bool, len(expected))
+	for _, login := range expected {
+		key := strings.ToLower(login)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		if !current[key] {
+			audit.Missing = append(audit.Missing, login)
+		}
+	}
+
+	for _, admin := range admins {
+		key := strings.ToLower(admin)
+		if !wanted[key] && !allowed[key] {
+			audit.Unexpected = append(audit.Unexpected, admin)
+		}
+	}
+
+	if !audit.Compliant() {
+		logger.Warn("Organization admins differ from the expected admins",
+			slog.String("org", orgName),
+			slog.Any("missing", audit.Missing),
+			slog.Any("unexpected", audit.Unexpected))
+	}
+	return audit
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// Branch protection outcomes recorded in BranchProtectionResult
+const (
+	BranchProtectionApplied     = "applied"
+	BranchProtectionUnsupported = "unsupported"
+	BranchProtectionFailed      = "failed"
+)
+
+const (
+	// branchProtectionAttempts bounds how often a branch that doesn't exist yet is retried
+	branchProtectionAttempts   = 5
+	branchProtectionRetryDelay = 3 * time.Second
+)
+
+// BranchProtectionResult is the --branch-protection-template outcome for a repository.
+// Neither an unsupported nor a failed protection fails the repository.
+type BranchProtectionResult struct {
+	Branch string `json:"branch"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// protectDefaultBranch applies the branch protection template to the repository's default
+// branch. A freshly generated repository may not have its branch yet, so a missing branch
+// is retried a few times before it's recorded as failed.
+func protectDefaultBranch(ctx context.Context, logger *slog.Logger, organization *api.Organization, repoName string, branch string, protection *util.BranchProtection) *BranchProtectionResult {
+	result := &BranchProtectionResult{Branch: branch, Status: BranchProtectionFailed}
+	if branch == "" {
+		result.Error = "default branch is unknown"
+		return result
+	}
+
+	var err error
+	for attempt := 1; attempt <= branchProtectionAttempts; attempt++ {
+		err = organization.ProtectBranch(ctx, logger, repoName, branch, *protection)
+		if !errors.Is(err, api.ErrBranchNotFound) || attempt == branchProtectionAttempts {
+			break
+		}
+		logger.Warn("Branch to protect not found yet, retrying",
+			slog.String("repo", repoName),
+			slog.String("branch", branch),
+			slog.Int("attempt", attempt))
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(branchProtectionRetryDelay):
+			continue
+		}
+		break
+	}
+
+	switch {
+	case err == nil:
+		result.Status = BranchProtectionApplied
+	case errors.Is(err, api.ErrBranchProtectionUnsupported):
+		result.Status = BranchProtectionUnsupported
+		result.Error = err.Error()
+	default:
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// writeBranchProtectionMarkdown summarizes --branch-protection-template across
+// repositories, listing every repository whose default branch wasn't protected
+func writeBranchProtectionMarkdown(w io.Writer, organizations []OrgReport, heading string) {
+	type problem struct {
+		org    string
+		repo   string
+		result *BranchProtectionResult
+	}
+	counts := map[string]int{}
+	var problems []problem
+	recorded := false
+
+	for _, org := range organizations {
+		for _, repo := range org.Repositories {
+			if repo.BranchProtection == nil {
+				continue
+			}
+			recorded = true
+			counts[repo.BranchProtection.Status]++
+			if repo.BranchProtection.Status != BranchProtectionApplied {
+				problems = append(problems, problem{org: org.OrgName, repo: repo.Name, result: repo.BranchProtection})
+			}
+		}
+	}
+	if !recorded {
+		return
+	}
+
+	fmt.Fprintf(w, "%s 🔒 Branch Protection\n\n", heading)
+	fmt.Fprintf(w, "- **Repositories:** %d protected, %d unsupported, %d failed\n\n",
+		counts[BranchProtectionApplied], counts[BranchProtectionUnsupported], counts[BranchProtectionFailed])
+
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "| Repository | Branch | Status | Detail |\n")
+		fmt.Fprintf(w, "|------------|--------|--------|--------|\n")
+		for _, p := range problems {
+			fmt.Fprintf(w, "| `%s` in `%s` | %s | %s | %s |\n", p.repo, p.org, p.result.Branch, p.result.Status, markdownTableCell(p.result.Error))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DeleteStateEntry is the last recorded deletion outcome of one organization
+type DeleteStateEntry struct {
+	Status    string    `json:"status"` // "success" or "failed"
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeleteState is the --state-file of a deletion run. It is rewritten after every
+// organization, so a run that stops partway can be resumed without re-attempting the
+// organizations it already deleted. A nil state records nothing. Not safe for concurrent use.
+type DeleteState struct {
+	path string
+	Orgs map[string]DeleteStateEntry `json:"orgs"`
+}
+
+// LoadDeleteState reads the state file at path, or starts an empty state if it doesn't
+// exist yet. Returns nil when path is empty.
+func LoadDeleteState(path string) (*DeleteState, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	state := &DeleteState{path: path, Orgs: map[string]DeleteStateEntry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	if state.Orgs == nil {
+		state.Orgs = map[string]DeleteStateEntry{}
+	}
+	return state, nil
+}
+
+// Deleted reports whether an earlier run recorded the organization as deleted
+func (s *DeleteState) Deleted(orgName string) bool {
+	if s == nil {
+		return false
+	}
+	return s.Orgs[strings.ToLower(orgName)].Status == "success"
+}
+
+// Record stores an organization's deletion outcome and rewrites the state file. The file
+// is replaced atomically so an interrupted write can't corrupt it.
+func (s *DeleteState) Record(org DeleteOrgReport) error {
+	if s == nil {
+		return nil
+	}
+	s.Orgs[strings.ToLower(org.OrgName)] = DeleteStateEntry{Status: org.Status, Error: org.Error, UpdatedAt: time.Now()}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state file: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// ResumedOrgReport is the report entry for an organization skipped because the state
+// file records it as deleted
+func ResumedOrgReport(user string, orgName string) DeleteOrgReport {
+	return DeleteOrgReport{User: user, OrgName: orgName, Status: "resumed"}
+}
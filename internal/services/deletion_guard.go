@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// CheckDeletionPrefix refuses the whole deletion if any org login doesn't start with the
+// --require-prefix value, so a mistakenly pasted list can't delete unrelated orgs. The
+// check is skipped when --allow-any-name is set.
+func CheckDeletionPrefix(ctx context.Context, logger *slog.Logger, orgNames []string) error {
+	if allowAnyName, _ := ctx.Value(config.AllowAnyNameKey).(bool); allowAnyName {
+		logger.Warn("Deletion prefix guard disabled by --allow-any-name",
+			slog.Int("org_count", len(orgNames)))
+		return nil
+	}
+
+	prefix, _ := ctx.Value(config.RequirePrefixKey).(string)
+	if prefix == "" {
+		return fmt.Errorf("--require-prefix cannot be empty; use --allow-any-name to delete organizations with arbitrary names")
+	}
+
+	offending := util.OrgLoginsWithoutPrefix(orgNames, prefix)
+	if len(offending) > 0 {
+		logger.Error("Refusing to delete organizations without the required prefix",
+			slog.String("required_prefix", prefix),
+			slog.Any("offending_orgs", offending))
+		return fmt.Errorf("refusing to delete %d organization(s) not starting with %q: %s (use --allow-any-name to override)",
+			len(offending), prefix, strings.Join(offending, ", "))
+	}
+
+	return nil
+}
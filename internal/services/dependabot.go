@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// Dependabot enablement outcomes recorded in DependabotResult
+const (
+	DependabotEnabled     = "enabled"
+	DependabotUnsupported = "unsupported"
+	DependabotFailed      = "failed"
+)
+
+// DependabotResult is the --enable-dependabot outcome for an organization or repository.
+// Neither an unsupported nor a failed enablement fails the org or repository.
+type DependabotResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// isDependabotEnabled reports whether --enable-dependabot is set
+func isDependabotEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(config.EnableDependabotKey).(bool)
+	return enabled
+}
+
+// newDependabotResult converts an enablement error into a result
+func newDependabotResult(err error) *DependabotResult {
+	switch {
+	case err == nil:
+		return &DependabotResult{Status: DependabotEnabled}
+	case errors.Is(err, api.ErrDependabotUnsupported):
+		return &DependabotResult{Status: DependabotUnsupported, Error: err.Error()}
+	default:
+		return &DependabotResult{Status: DependabotFailed, Error: err.Error()}
+	}
+}
+
+// enableRepoDependabot turns on Dependabot alerts and then security updates for a
+// repository, stopping at the first step that doesn't succeed
+func enableRepoDependabot(ctx context.Context, logger *slog.Logger, organization *api.Organization, repoName string) *DependabotResult {
+	if err := organization.EnableVulnerabilityAlerts(ctx, logger, repoName); err != nil {
+		return newDependabotResult(err)
+	}
+	return newDependabotResult(organization.EnableAutomatedSecurityFixes(ctx, logger, repoName))
+}
+
+// writeDependabotMarkdown summarizes --enable-dependabot across organizations and
+// repositories, listing every one that wasn't enabled
+func writeDependabotMarkdown(w io.Writer, organizations []OrgReport, heading string) {
+	type problem struct {
+		target string
+		result *DependabotResult
+	}
+	counts := map[string]int{}
+	repoCounts := map[string]int{}
+	var problems []problem
+	recorded := false
+
+	for _, org := range organizations {
+		if org.Dependabot != nil {
+			recorded = true
+			counts[org.Dependabot.Status]++
+			if org.Dependabot.Status != DependabotEnabled {
+				problems = append(problems, problem{target: fmt.Sprintf("`%s`", org.OrgName), result: org.Dependabot})
+			}
+		}
+		for _, repo := range org.Repositories {
+			if repo.Dependabot == nil {
+				continue
+			}
+			recorded = true
+			repoCounts[repo.Dependabot.Status]++
+			if repo.Dependabot.Status != DependabotEnabled {
+				problems = append(problems, problem{target: fmt.Sprintf("`%s` in `%s`", repo.Name, org.OrgName), result: repo.Dependabot})
+			}
+		}
+	}
+	if !recorded {
+		return
+	}
+
+	fmt.Fprintf(w, "%s 🤖 Dependabot\n\n", heading)
+	fmt.Fprintf(w, "- **Organizations:** %d enabled, %d failed\n", counts[DependabotEnabled], counts[DependabotFailed])
+	fmt.Fprintf(w, "- **Repositories:** %d enabled, %d unsupported, %d failed\n\n",
+		repoCounts[DependabotEnabled], repoCounts[DependabotUnsupported], repoCounts[DependabotFailed])
+
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "| Target | Status | Detail |\n")
+		fmt.Fprintf(w, "|--------|--------|--------|\n")
+		for _, p := range problems {
+			fmt.Fprintf(w, "| %s | %s | %s |\n", p.target, p.result.Status, p.result.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
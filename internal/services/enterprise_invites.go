@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// EnterpriseInviteSummary records the enterprise membership state of each user when
+// --invite-to-enterprise is used
+type EnterpriseInviteSummary struct {
+	AlreadyMembers []string `json:"already_members"`
+	AlreadyInvited []string `json:"already_invited,omitempty"`
+	Invited        []string `json:"invited"`
+	Failed         []string `json:"failed,omitempty"`
+}
+
+// inviteUsersToEnterprise invites every user that isn't already an enterprise member or
+// pending invitee. Failures are recorded rather than returned so provisioning can still
+// proceed for users that are already members.
+func inviteUsersToEnterprise(ctx context.Context, logger *slog.Logger, enterprise *api.Enterprise, users []string) *EnterpriseInviteSummary {
+	summary := &EnterpriseInviteSummary{
+		AlreadyMembers: []string{},
+		Invited:        []string{},
+	}
+
+	for _, user := range users {
+		state, err := enterprise.GetMembershipState(ctx, logger, user)
+		if err != nil {
+			logger.Error("Failed to check enterprise membership",
+				slog.String("user", user),
+				slog.Any("error", err))
+			summary.Failed = append(summary.Failed, user)
+			continue
+		}
+
+		switch state {
+		case api.EnterpriseMembershipMember:
+			summary.AlreadyMembers = append(summary.AlreadyMembers, user)
+		case api.EnterpriseMembershipPending:
+			summary.AlreadyInvited = append(summary.AlreadyInvited, user)
+		default:
+			if err := enterprise.InviteMember(ctx, logger, user); err != nil {
+				logger.Error("Failed to invite user to enterprise",
+					slog.String("user", user),
+					slog.Any("error", err))
+				summary.Failed = append(summary.Failed, user)
+				continue
+			}
+			summary.Invited = append(summary.Invited, user)
+		}
+	}
+
+	logger.Info("Enterprise invitations complete",
+		slog.Int("already_members", len(summary.AlreadyMembers)),
+		slog.Int("already_invited", len(summary.AlreadyInvited)),
+		slog.Int("invited", len(summary.Invited)),
+		slog.Int("failed", len(summary.Failed)))
+
+	if len(summary.Invited) > 0 || len(summary.AlreadyInvited) > 0 {
+		logger.Warn("Invited users must accept their enterprise invitation before they can be made organization admins")
+	}
+
+	return summary
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// hookOutputLogBytes bounds how much of a hook's stdout and stderr is copied into the log
+const hookOutputLogBytes = 4096
+
+// errHookFailed is returned by a run that stopped because a hook failed with
+// --hook-failure-mode fail
+var errHookFailed = errors.New("a provisioning hook failed and --hook-failure-mode is fail")
+
+// HookResult is the outcome of running a hook command for one organization
+type HookResult struct {
+	Status     string `json:"status"` // "success" or "failed"
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// provisionHooks runs the --pre-hook and --post-hook commands for a lab run and remembers
+// whether a hook failure should stop the run
+type provisionHooks struct {
+	pre     *util.HookCommand
+	post    *util.HookCommand
+	timeout time.Duration
+	failRun bool
+	aborted atomic.Bool
+}
+
+// newProvisionHooks reads the hook settings from the context
+func newProvisionHooks(ctx context.Context) *provisionHooks {
+	hooks := &provisionHooks{timeout: config.DefaultHookTimeout}
+	if timeout, ok := ctx.Value(config.HookTimeoutKey).(time.Duration); ok && timeout > 0 {
+		hooks.timeout = timeout
+	}
+	if pre, ok := ctx.Value(config.PreHookKey).(util.HookCommand); ok && pre.Raw != "" {
+		hooks.pre = &pre
+	}
+	if post, ok := ctx.Value(config.PostHookKey).(util.HookCommand); ok && post.Raw != "" {
+		hooks.post = &post
+	}
+	mode, _ := ctx.Value(config.HookFailureModeKey).(string)
+	hooks.failRun = mode == config.HookFailureFail
+	return hooks
+}
+
+// isAborted reports whether a hook failed with --hook-failure-mode fail, after which no
+// further organizations are started
+func (h *provisionHooks) isAborted() bool {
+	return h != nil && h.aborted.Load()
+}
+
+// runPreHook runs --pre-hook for the user before their organization is provisioned.
+// Returns nil when no pre-hook is configured.
+func (h *provisionHooks) runPreHook(ctx context.Context, logger *slog.Logger, user string) *HookResult {
+	if h == nil || h.pre == nil {
+		return nil
+	}
+	labDate, _ := ctx.Value(config.LabDateKey).(string)
+	return h.run(ctx, logger, "pre-hook", *h.pre, hookVars(ctx, user, util.BuildOrgLogin(labDate, user)))
+}
+
+// runPostHook runs --post-hook for an organization that was provisioned successfully.
+// Returns nil when no post-hook is configured.
+func (h *provisionHooks) runPostHook(ctx context.Context, logger *slog.Logger, user string, orgName string) *HookResult {
+	if h == nil || h.post == nil {
+		return nil
+	}
+	return h.run(ctx, logger, "post-hook", *h.post, hookVars(ctx, user, orgName))
+}
+
+// hookVars returns the hook command variables for a user's organization
+func hookVars(ctx context.Context, user string, orgName string) util.HookVars {
+	labDate, _ := ctx.Value(config.LabDateKey).(string)
+	baseURL, _ := ctx.Value(config.BaseURLKey).(string)
+	return util.HookVars{
+		User: user,
+		Date: labDate,
+		Org:  orgName,
+		URL:  webBaseURL(baseURL) + "/" + orgName,
+	}
+}
+
+// run executes one hook command with the hook timeout and logs its output. A failure
+// marks the run aborted when --hook-failure-mode is fail.
+func (h *provisionHooks) run(ctx context.Context, logger *slog.Logger, name string, hook util.HookCommand, vars util.HookVars) *HookResult {
+	start := time.Now()
+	result := &HookResult{Status: "failed"}
+	defer func() {
+		result.DurationMs = time.Since(start).Milliseconds()
+		if result.Status != "success" && h.failRun {
+			h.aborted.Store(true)
+		}
+	}()
+
+	args, err := hook.Args(vars)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	logger.Info("Running hook",
+		slog.String("hook", name),
+		slog.String("org", vars.Org),
+		slog.Any("args", args))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	attrs := []any{
+		slog.String("hook", name),
+		slog.String("org", vars.Org),
+		slog.String("stdout", hookOutput(&stdout)),
+		slog.String("stderr", hookOutput(&stderr)),
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", h.timeout)
+		}
+		result.Error = fmt.Sprintf("%s failed: %v", name, err)
+		logger.Error("Hook failed", append(attrs, slog.Any("error", err))...)
+		return result
+	}
+
+	result.Status = "success"
+	logger.Info("Hook completed", attrs...)
+	return result
+}
+
+// hookOutput returns a hook's captured output for the log, trimmed and truncated
+func hookOutput(r io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(r, hookOutputLogBytes+1))
+	if len(data) > hookOutputLogBytes {
+		return strings.TrimSpace(string(data[:hookOutputLogBytes])) + "... (truncated)"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// writeHooksMarkdown summarizes the hook runs across organizations, listing every failure
+func writeHooksMarkdown(w io.Writer, organizations []OrgReport, heading string) {
+	type failure struct {
+		user   string
+		hook   string
+		result *HookResult
+	}
+	succeeded := 0
+	var failures []failure
+
+	for _, org := range organizations {
+		for _, run := range []struct {
+			hook   string
+			result *HookResult
+		}{{"pre-hook", org.PreHook}, {"post-hook", org.PostHook}} {
+			if run.result == nil {
+				continue
+			}
+			if run.result.Status == "success" {
+				succeeded++
+			} else {
+				failures = append(failures, failure{user: org.User, hook: run.hook, result: run.result})
+			}
+		}
+	}
+	if succeeded == 0 && len(failures) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s 🪝 Hooks\n\n", heading)
+	fmt.Fprintf(w, "- **Hook runs:** %d succeeded, %d failed\n\n", succeeded, len(failures))
+
+	if len(failures) > 0 {
+		fmt.Fprintf(w, "| User | Hook | Error |\n")
+		fmt.Fprintf(w, "|------|------|-------|\n")
+		for _, f := range failures {
+			fmt.Fprintf(w, "| %s | %s | %s |\n", f.user, f.hook, markdownTableCell(f.result.Error))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
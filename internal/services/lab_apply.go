@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// ApplyLabEnvironment brings a lab date to the desired state described by the users and
+// template repos files. It runs the create flow in apply mode: organizations, app
+// installations, admin memberships and repositories that already exist are left as they
+// are and reported as already present, and only what's missing is created.
+func ApplyLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile string, templateReposFile string) error {
+	ctx = context.WithValue(ctx, config.ApplyModeKey, true)
+	_, err := createLabEnvironment(ctx, logger, usersFile, templateReposFile)
+	return err
+}
+
+// isApplyMode reports whether the create flow is running for lab apply
+func isApplyMode(ctx context.Context) bool {
+	applyMode, _ := ctx.Value(config.ApplyModeKey).(bool)
+	return applyMode
+}
+
+// recordApply notes, in apply mode, whether a resource was created by this run or was
+// already present
+func (r *ProvisionResult) recordApply(applyMode bool, resource string, created bool) {
+	if !applyMode {
+		return
+	}
+	if created {
+		r.Created = append(r.Created, resource)
+	} else {
+		r.AlreadyPresent = append(r.AlreadyPresent, resource)
+	}
+}
+
+// findExistingOrg returns the organization if it exists, or nil if it doesn't
+func findExistingOrg(ctx context.Context, logger *slog.Logger, orgName string) (*api.Organization, error) {
+	organization, err := api.GetOrganization(ctx, logger, orgName)
+	if errors.Is(err, api.ErrOrganizationNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Organization already exists", slog.String("org", organization.Login))
+	return organization, nil
+}
+
+// isAppInstalledOnOrg reports whether the GitHub App already has an installation on the org
+func isAppInstalledOnOrg(ctx context.Context, logger *slog.Logger, orgName string) (bool, error) {
+	installations, err := api.ListAppInstallations(ctx, logger)
+	if err != nil {
+		return false, err
+	}
+	for _, installation := range installations {
+		if strings.EqualFold(installation.Account.Login, orgName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ensureOrgMemberships makes sure the org's user is an admin and every facilitator holds
+// the --facilitator-role on an existing organization, changing only memberships that are
+// missing or have a different role. Failures are logged and leave the membership as is.
+func ensureOrgMemberships(ctx context.Context, logger *slog.Logger, result *ProvisionResult, orgName string, orgUser string, facilitators []string) {
+	facilitatorRole, _ := ctx.Value(config.FacilitatorRoleKey).(string)
+	if facilitatorRole == "" {
+		facilitatorRole = "admin"
+	}
+
+	ensure := func(login string, role string) string {
+		current, err := api.GetOrgMembership(ctx, logger, orgName, login)
+		if err != nil && !errors.Is(err, api.ErrNotOrgMember) {
+			logger.Warn("Failed to check organization membership",
+				slog.String("org", orgName),
+				slog.String("user", login),
+				slog.Any("error", err))
+			return current
+		}
+
+		resource := fmt.Sprintf("%s @%s", role, login)
+		if current == role {
+			result.recordApply(true, resource, false)
+			return current
+		}
+
+		if err := api.AddOrgMember(ctx, logger, orgName, login, role); err != nil {
+			logger.Warn("Failed to update organization membership",
+				slog.String("org", orgName),
+				slog.String("user", login),
+				slog.String("role", role),
+				slog.Any("error", err))
+			return current
+		}
+		result.recordApply(true, resource, true)
+		return role
+	}
+
+	ensure(orgUser, "admin")
+
+	for _, facilitator := range facilitators {
+		if facilitator == orgUser {
+			if facilitatorRole != "admin" {
+				result.FacilitatorRoles = append(result.FacilitatorRoles, FacilitatorRole{Login: facilitator, Role: "admin"})
+			}
+			continue
+		}
+		finalRole := ensure(facilitator, facilitatorRole)
+		if facilitatorRole != "admin" {
+			if finalRole == "" {
+				finalRole = "none"
+			}
+			result.FacilitatorRoles = append(result.FacilitatorRoles, FacilitatorRole{Login: facilitator, Role: finalRole})
+		}
+	}
+}
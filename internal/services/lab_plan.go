@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// labPlanVersion is the plan file format written by lab plan
+const labPlanVersion = 1
+
+// LabPlan is the reviewed, validated input of a lab run: lab plan writes it and
+// lab apply --plan provisions exactly what it describes
+type LabPlan struct {
+	Version        int       `json:"version"`
+	GeneratedAt    time.Time `json:"generated_at"`
+	LabDate        string    `json:"lab_date"`
+	EnterpriseSlug string    `json:"enterprise_slug"`
+	// Users and Facilitators are the students and facilitators that passed validation
+	Users        []string `json:"users"`
+	Facilitators []string `json:"facilitators"`
+	// Orgs is every organization to provision, for students and facilitators alike
+	Orgs                []PlannedOrg       `json:"orgs"`
+	InvalidUsers        []api.InvalidUser  `json:"invalid_users,omitempty"`
+	InvalidFacilitators []api.InvalidUser  `json:"invalid_facilitators,omitempty"`
+	UserFilters         *UserFilterSummary `json:"user_filters,omitempty"`
+	TemplateRepos       []util.RepoConfig  `json:"template_repos"`
+	ExcludedTemplates   []string           `json:"excluded_templates,omitempty"`
+	// FacilitatorTemplates is null unless --facilitator-templates was used; an empty list
+	// gives facilitators' orgs no repositories
+	FacilitatorTemplates []util.RepoConfig `json:"facilitator_templates"`
+	// OrgPolicy is the --org-policy applied to every organization
+	OrgPolicy *util.OrgPolicy `json:"org_policy,omitempty"`
+	// BranchProtection is the --branch-protection-template applied to every repository's
+	// default branch
+	BranchProtection *util.BranchProtection `json:"branch_protection,omitempty"`
+	// Estimate is the organization creation estimate made when the plan was built
+	Estimate *OrgEstimate `json:"estimate,omitempty"`
+	Settings PlanSettings `json:"settings"`
+}
+
+// PlannedOrg is one organization the plan will provision
+type PlannedOrg struct {
+	User    string `json:"user"`
+	OrgName string `json:"org_name"`
+}
+
+// PlanSettings are the provisioning flags in effect when the plan was made
+type PlanSettings struct {
+	OrgsOnly               bool   `json:"orgs_only,omitempty"`
+	FacilitatorsAdminsOnly bool   `json:"facilitators_admins_only,omitempty"`
+	FacilitatorRole        string `json:"facilitator_role"`
+	InviteToEnterprise     bool   `json:"invite_to_enterprise,omitempty"`
+	NoDescription          bool   `json:"no_description,omitempty"`
+	WaitRepoReady          bool   `json:"wait_repo_ready,omitempty"`
+	TemplateBaseURL        string `json:"template_base_url,omitempty"`
+	OrgProfileTemplate     string `json:"org_profile_template,omitempty"`
+	OrgRetries             int    `json:"org_retries,omitempty"`
+	WaitBetweenOrgs        string `json:"wait_between_orgs,omitempty"`
+	VerifyInstall          bool   `json:"verify_install,omitempty"`
+	EnableDependabot       bool   `json:"enable_dependabot,omitempty"`
+	SharedRepo             string `json:"shared_repo,omitempty"`
+	SharedRepoOrg          string `json:"shared_repo_org,omitempty"`
+}
+
+// planSettingsFromContext captures the provisioning settings from the context
+func planSettingsFromContext(ctx context.Context) PlanSettings {
+	var s PlanSettings
+	s.OrgsOnly, _ = ctx.Value(config.OrgsOnlyKey).(bool)
+	s.FacilitatorsAdminsOnly, _ = ctx.Value(config.FacilitatorsAdminsOnlyKey).(bool)
+	s.FacilitatorRole, _ = ctx.Value(config.FacilitatorRoleKey).(string)
+	s.InviteToEnterprise, _ = ctx.Value(config.InviteToEnterpriseKey).(bool)
+	s.NoDescription, _ = ctx.Value(config.NoDescriptionKey).(bool)
+	s.WaitRepoReady, _ = ctx.Value(config.WaitRepoReadyKey).(bool)
+	s.TemplateBaseURL, _ = ctx.Value(config.TemplateBaseURLKey).(string)
+	s.OrgProfileTemplate, _ = ctx.Value(config.OrgProfileTemplateKey).(string)
+	s.OrgRetries, _ = ctx.Value(config.OrgRetriesKey).(int)
+	s.WaitBetweenOrgs = waitBetweenOrgsLabel(ctx)
+	s.VerifyInstall, _ = ctx.Value(config.VerifyInstallKey).(bool)
+	s.EnableDependabot, _ = ctx.Value(config.EnableDependabotKey).(bool)
+	s.SharedRepo, _ = ctx.Value(config.SharedRepoKey).(string)
+	s.SharedRepoOrg, _ = ctx.Value(config.SharedRepoOrgKey).(string)
+	return s
+}
+
+// apply stores the settings in the context, replacing any set by flags
+func (s PlanSettings) apply(ctx context.Context) context.Context {
+	waitBetweenOrgs, _ := time.ParseDuration(s.WaitBetweenOrgs)
+	ctx = context.WithValue(ctx, config.OrgsOnlyKey, s.OrgsOnly)
+	ctx = context.WithValue(ctx, config.FacilitatorsAdminsOnlyKey, s.FacilitatorsAdminsOnly)
+	ctx = context.WithValue(ctx, config.FacilitatorRoleKey, s.FacilitatorRole)
+	ctx = context.WithValue(ctx, config.InviteToEnterpriseKey, s.InviteToEnterprise)
+	ctx = context.WithValue(ctx, config.NoDescriptionKey, s.NoDescription)
+	ctx = context.WithValue(ctx, config.WaitRepoReadyKey, s.WaitRepoReady)
+	ctx = context.WithValue(ctx, config.TemplateBaseURLKey, s.TemplateBaseURL)
+	ctx = context.WithValue(ctx, config.OrgProfileTemplateKey, s.OrgProfileTemplate)
+	ctx = context.WithValue(ctx, config.OrgRetriesKey, s.OrgRetries)
+	ctx = context.WithValue(ctx, config.WaitBetweenOrgsKey, waitBetweenOrgs)
+	ctx = context.WithValue(ctx, config.VerifyInstallKey, s.VerifyInstall)
+	ctx = context.WithValue(ctx, config.EnableDependabotKey, s.EnableDependabot)
+	ctx = context.WithValue(ctx, config.SharedRepoKey, s.SharedRepo)
+	ctx = context.WithValue(ctx, config.SharedRepoOrgKey, s.SharedRepoOrg)
+	return ctx
+}
+
+// PlanLabEnvironment validates the users and template repos files and writes the
+// resulting plan to planFile for review. Nothing is created.
+func PlanLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile string, templateReposFile string, planFile string) (*LabPlan, error) {
+	// Plans are run by lab apply, which reuses organizations that already exist
+	ctx = context.WithValue(ctx, config.ApplyModeKey, true)
+
+	plan, err := buildLabPlan(ctx, logger, usersFile, templateReposFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	if err := os.WriteFile(planFile, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write plan file: %w", err)
+	}
+
+	logger.Info("Wrote lab plan",
+		slog.String("file", planFile),
+		slog.String("lab_date", plan.LabDate),
+		slog.Int("org_count", len(plan.Orgs)),
+		slog.Int("template_count", len(plan.TemplateRepos)))
+	return plan, nil
+}
+
+// LoadLabPlan reads a plan written by lab plan
+func LoadLabPlan(planFile string) (*LabPlan, error) {
+	data, err := os.ReadFile(planFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan LabPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan file %s: %w", planFile, err)
+	}
+	if plan.Version != labPlanVersion {
+		return nil, fmt.Errorf("plan file %s has version %d, expected %d; re-run lab plan", planFile, plan.Version, labPlanVersion)
+	}
+	if plan.LabDate == "" || plan.EnterpriseSlug == "" || len(plan.Orgs) == 0 {
+		return nil, fmt.Errorf("plan file %s is incomplete: lab date, enterprise slug and organizations are required", planFile)
+	}
+	return &plan, nil
+}
+
+// ApplyLabPlan provisions exactly the organizations and repositories in the plan file in
+// apply mode. Users aren't validated again unless revalidate is set, in which case any
+// planned user or facilitator that is no longer valid stops the run before anything is
+// created.
+func ApplyLabPlan(ctx context.Context, logger *slog.Logger, planFile string, revalidate bool) error {
+	startTime := time.Now()
+
+	plan, err := LoadLabPlan(planFile)
+	if err != nil {
+		return err
+	}
+	logger.Info("Loaded lab plan",
+		slog.String("file", planFile),
+		slog.String("lab_date", plan.LabDate),
+		slog.Time("generated_at", plan.GeneratedAt),
+		slog.Int("org_count", len(plan.Orgs)))
+
+	if revalidate {
+		if err := revalidateLabPlan(ctx, logger, plan); err != nil {
+			return err
+		}
+	}
+
+	if slug, _ := ctx.Value(config.EnterpriseSlugKey).(string); slug != "" && slug != plan.EnterpriseSlug {
+		return fmt.Errorf("plan was made for enterprise %q, not %q", plan.EnterpriseSlug, slug)
+	}
+
+	ctx = context.WithValue(ctx, config.ApplyModeKey, true)
+	ctx = context.WithValue(ctx, config.EnterpriseSlugKey, plan.EnterpriseSlug)
+	_, err = provisionLabPlan(ctx, logger, plan, startTime)
+	return err
+}
+
+// revalidateLabPlan checks that every planned user and facilitator is still valid
+func revalidateLabPlan(ctx context.Context, logger *slog.Logger, plan *LabPlan) error {
+	logins := append(append([]string{}, plan.Users...), plan.Facilitators...)
+	logger.Info("Revalidating planned users", slog.Int("count", len(logins)))
+
+	validation, err := api.ValidateAndFilterUsers(ctx, logger, logins)
+	if err != nil {
+		return fmt.Errorf("user validation failed: %w", err)
+	}
+	if len(validation.InvalidUsers) == 0 {
+		return nil
+	}
+
+	invalid := make([]string, 0, len(validation.InvalidUsers))
+	for _, u := range validation.InvalidUsers {
+		invalid = append(invalid, fmt.Sprintf("%s (%s)", u.Name, u.Reason))
+	}
+	return fmt.Errorf("plan is out of date: %d planned user(s) are no longer valid, re-run lab plan: %s", len(invalid), strings.Join(invalid, ", "))
+}
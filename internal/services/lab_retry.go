@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// LoadLabReport reads a lab-report-*.json written by lab create or lab apply
+func LoadLabReport(path string) (*LabReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report LabReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid lab report %s: %w", path, err)
+	}
+	if report.LabDate == "" || report.EnterpriseSlug == "" {
+		return nil, fmt.Errorf("invalid lab report %s: lab_date and enterprise_slug are required", path)
+	}
+	return &report, nil
+}
+
+// failedOrgUsers returns the users whose organizations failed in the report, sorted
+func failedOrgUsers(report *LabReport) []string {
+	var users []string
+	for _, org := range report.Organizations {
+		if org.Status == "failed" {
+			users = append(users, org.User)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// RetryFailedOrgs re-provisions only the organizations that failed in a previous lab
+// report and writes a fresh report for them. The lab date, enterprise and facilitators
+// come from the report. Retries run like lab apply, so an organization that was created
+// before its failure is reused rather than failing again.
+func RetryFailedOrgs(ctx context.Context, logger *slog.Logger, reportFile string, templateReposFile string) error {
+	startTime := time.Now()
+
+	report, err := LoadLabReport(reportFile)
+	if err != nil {
+		return err
+	}
+
+	users := failedOrgUsers(report)
+	if len(users) == 0 {
+		logger.Info("No failed organizations to retry",
+			slog.String("report", reportFile),
+			slog.String("lab_date", report.LabDate))
+		fmt.Printf("No failed organizations in %s; nothing to retry\n", reportFile)
+		return nil
+	}
+	logger.Info("Retrying failed organizations",
+		slog.String("report", reportFile),
+		slog.String("lab_date", report.LabDate),
+		slog.Any("users", users))
+
+	ctx = context.WithValue(ctx, config.LabDateKey, report.LabDate)
+	ctx = context.WithValue(ctx, config.EnterpriseSlugKey, report.EnterpriseSlug)
+	ctx = context.WithValue(ctx, config.FacilitatorsKey, report.Facilitators)
+	ctx = context.WithValue(ctx, config.FacilitatorsAdminsOnlyKey, report.FacilitatorsAdminsOnly)
+	// Scoping to the failed users also keeps facilitator orgs that succeeded out of the run
+	ctx = context.WithValue(ctx, config.OnlyUsersKey, users)
+	ctx = context.WithValue(ctx, config.ApplyModeKey, true)
+
+	plan, err := buildLabPlanForUsers(ctx, logger, users, templateReposFile)
+	if err != nil {
+		return err
+	}
+	_, err = provisionLabPlan(ctx, logger, plan, startTime)
+	return err
+}
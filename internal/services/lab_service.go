@@ -2,8 +2,14 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,14 +26,82 @@ type ProvisionResult struct {
 	Error       string
 	Repos       []RepoReport
 	CompletedAt time.Time
+	// FacilitatorRoles is only set when --facilitator-role isn't admin
+	FacilitatorRoles []FacilitatorRole
+	// Created and AlreadyPresent are only set by lab apply
+	Created        []string
+	AlreadyPresent []string
+	// OrgAttempts is the number of organization creation attempts, including retries
+	OrgAttempts int
+	// TemplateSet is "student" or "facilitator" when --facilitator-templates is set
+	TemplateSet string
+	// InstallVerification is set when --verify-install checked the app installation
+	InstallVerification *api.InstallVerification
+	// PacingWait is set when the worker waited --wait-between-orgs before this user
+	PacingWait bool
+	// Dependabot is the org-level --enable-dependabot result
+	Dependabot *DependabotResult
+	// OrgPolicy has one result per --org-policy setting
+	OrgPolicy []OrgPolicyResult
+	// CreatedRepos are the repositories this run created, for --repos-output
+	CreatedRepos []util.CreatedRepo
+	// PreHook is the --pre-hook result
+	PreHook *HookResult
+	// PostHook is the --post-hook result, set when results are collected
+	PostHook *HookResult
+
+	// orgErr is the organization creation error, which may send the user back on the
+	// queue for --org-retries
+	orgErr error
 }
 
-func ProvisionOrgResources(workerId int, ctx context.Context, logger *slog.Logger, orgChan chan string, resultsChan chan ProvisionResult, enterprise *api.Enterprise, templateRepos []util.RepoConfig) {
+// applyFacilitatorRole changes the facilitators' membership on a newly created org to the
+// --facilitator-role. createEnterpriseOrganization only accepts admin logins, so every
+// facilitator starts as admin. A facilitator keeps admin on their own org, and on any org
+// where the change fails. Returns each facilitator's final role, or nil when the role is admin.
+func applyFacilitatorRole(ctx context.Context, logger *slog.Logger, orgName string, orgUser string, facilitators []string) []FacilitatorRole {
+	role, _ := ctx.Value(config.FacilitatorRoleKey).(string)
+	if role == "" || role == "admin" {
+		return nil
+	}
+
+	roles := make([]FacilitatorRole, 0, len(facilitators))
+	for _, facilitator := range facilitators {
+		if facilitator == orgUser {
+			roles = append(roles, FacilitatorRole{Login: facilitator, Role: "admin"})
+			continue
+		}
+		if err := api.AddOrgMember(ctx, logger, orgName, facilitator, role); err != nil {
+			logger.Warn("Failed to change facilitator role, facilitator remains admin",
+				slog.String("facilitator", facilitator),
+				slog.String("org", orgName),
+				slog.String("role", role),
+				slog.Any("error", err))
+			roles = append(roles, FacilitatorRole{Login: facilitator, Role: "admin"})
+			continue
+		}
+		roles = append(roles, FacilitatorRole{Login: facilitator, Role: role})
+	}
+	return roles
+}
+
+// ProvisionOrgResources creates an organization for each user taken from queue and
+// fills it with repositories. Facilitators' organizations get facilitatorTemplates instead
+// of templateRepos when useFacilitatorTemplates is set.
+func ProvisionOrgResources(workerId int, ctx context.Context, logger *slog.Logger, queue *orgQueue, resultsChan chan ProvisionResult, enterprise *api.Enterprise, templateRepos []util.RepoConfig, facilitatorTemplates []util.RepoConfig, useFacilitatorTemplates bool, orgPolicy *util.OrgPolicy, branchProtection *util.BranchProtection, hooks *provisionHooks) {
 
 	logger.Info("Worker started", slog.Int("workerId", workerId))
 
+	waitBetweenOrgs, _ := ctx.Value(config.WaitBetweenOrgsKey).(time.Duration)
+	firstOrg := true
+
 	// Create a new organization for the user
-	for user := range orgChan {
+	for {
+		user, ok := queue.next(ctx)
+		if !ok {
+			break
+		}
+
 		// Check if context is cancelled
 		select {
 		case <-ctx.Done():
@@ -36,138 +110,462 @@ func ProvisionOrgResources(workerId int, ctx context.Context, logger *slog.Logge
 		default:
 		}
 
-		// Initialize result tracking
-		result := ProvisionResult{
-			User:        user,
-			Status:      "failed",
-			Repos:       []RepoReport{},
-			CompletedAt: time.Now(),
+		// An explicit pace between this worker's orgs, for instances that throttle bursts
+		pacingWait := false
+		if waitBetweenOrgs > 0 && !firstOrg {
+			logger.Info("Waiting before next organization",
+				slog.Int("workerId", workerId),
+				slog.Duration("wait", waitBetweenOrgs))
+			select {
+			case <-ctx.Done():
+				logger.Warn("Worker stopping due to context cancellation", slog.Int("workerId", workerId))
+				return
+			case <-time.After(waitBetweenOrgs):
+			}
+			pacingWait = true
+		}
+		firstOrg = false
+
+		// Once a hook has failed with --hook-failure-mode fail, remaining users aren't started
+		if hooks.isAborted() {
+			resultsChan <- ProvisionResult{
+				User:        user,
+				Status:      "failed",
+				Error:       "Not provisioned: " + errHookFailed.Error(),
+				Repos:       []RepoReport{},
+				CompletedAt: time.Now(),
+			}
+			queue.finish()
+			continue
+		}
+
+		attempt := queue.startAttempt(user)
+		orgLogger := orgRunLogger(logger, workerId, user)
+		preHook := queue.runPreHook(ctx, orgLogger, hooks, user)
+		var result ProvisionResult
+		if preHook != nil && preHook.Status != "success" {
+			result = ProvisionResult{
+				User:        user,
+				Status:      "failed",
+				Error:       "Skipped: " + preHook.Error,
+				Repos:       []RepoReport{},
+				CompletedAt: time.Now(),
+			}
+		} else {
+			result = provisionOrg(ctx, orgLogger, user, attempt, enterprise, templateRepos, facilitatorTemplates, useFacilitatorTemplates, orgPolicy, branchProtection)
+			if result.orgErr != nil && queue.retry(ctx, orgLogger, user, attempt, result.orgErr) {
+				continue
+			}
+		}
+		result.PreHook = preHook
+		result.PacingWait = pacingWait
+		resultsChan <- result
+		queue.finish()
+	}
+
+	logger.Info("Worker stopped", slog.Int("workerId", workerId))
+}
+
+// WorkerCount returns how many organization workers to start for jobs organizations:
+// --max-workers, or fewer when there are fewer organizations
+func WorkerCount(ctx context.Context, jobs int) int {
+	workers, ok := ctx.Value(config.MaxWorkersKey).(int)
+	if !ok || workers < 1 {
+		workers = config.DefaultMaxWorkers
+	}
+	if jobs < workers {
+		workers = jobs
+	}
+	return workers
+}
+
+// orgRunLogger returns a logger whose lines all carry the user being processed and a
+// short ID for this pass over their org, so one org's lifecycle can be picked out of the
+// interleaved output of concurrent workers
+func orgRunLogger(logger *slog.Logger, workerId int, user string) *slog.Logger {
+	id := make([]byte, 4)
+	_, _ = rand.Read(id)
+	return logger.With(
+		slog.String("run_user", user),
+		slog.String("run_id", hex.EncodeToString(id)),
+		slog.Int("workerId", workerId))
+}
+
+// provisionOrg creates or, in apply mode, reuses the user's organization and fills it
+// with repositories. attempt numbers this pass for --org-retries. It always returns a
+// result: failures, including panics, are recorded in it rather than stopping the worker.
+func provisionOrg(ctx context.Context, logger *slog.Logger, user string, attempt int, enterprise *api.Enterprise, templateRepos []util.RepoConfig, facilitatorTemplates []util.RepoConfig, useFacilitatorTemplates bool, orgPolicy *util.OrgPolicy, branchProtection *util.BranchProtection) (result ProvisionResult) {
+	// Initialize result tracking
+	result = ProvisionResult{
+		User:        user,
+		Status:      "failed",
+		Repos:       []RepoReport{},
+		CompletedAt: time.Now(),
+	}
+
+	// A panic fails this user's org instead of crashing the run, so the report is still
+	// written and covers everything provisioned so far
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Recovered from panic while provisioning organization",
+				slog.String("user", user),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())))
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("Internal error while provisioning: %v", r)
+		}
+	}()
+
+	applyMode := isApplyMode(ctx)
+	labDate, _ := ctx.Value(config.LabDateKey).(string)
+
+	// In apply mode an organization that already exists is reused rather than created. A
+	// retry only follows a first pass that found none, so one found later is this run's
+	var organization *api.Organization
+	var err error
+	orgExists := false
+	if applyMode && attempt == 1 {
+		organization, err = findExistingOrg(ctx, logger, util.BuildOrgLogin(labDate, user))
+		if err != nil {
+			result.Error = fmt.Sprintf("Failed to look up organization: %v", err)
+			return result
 		}
+		orgExists = organization != nil
+	}
 
-		// Call the GraphQL-based CreateOrg function
-		organization, err := enterprise.CreateOrg(ctx, logger, user)
+	if !orgExists {
+		result.OrgAttempts = attempt
+		// A failed attempt, such as one that timed out, may still have created the org;
+		// creating it again would fail as already taken, so a retry looks for it first
+		if attempt > 1 {
+			organization, err = findExistingOrg(ctx, logger, util.BuildOrgLogin(labDate, user))
+			if err != nil {
+				result.Error = fmt.Sprintf("Failed to look up organization: %v", err)
+				result.orgErr = err
+				return result
+			}
+		}
+		if organization == nil {
+			// Respect the minimum interval between org creations across all workers
+			if err = api.WaitForOrgCreateSlot(ctx, logger); err == nil {
+				organization, err = enterprise.CreateOrg(ctx, logger, user)
+			}
+		}
 		if err != nil {
 			logger.Error("Failed to create organization",
 				slog.String("user", user),
+				slog.Int("attempts", result.OrgAttempts),
 				slog.Any("error", err))
 			result.Error = fmt.Sprintf("Failed to create organization: %v", err)
-			resultsChan <- result
-			continue
+			result.orgErr = err
+			return result
+		}
+	}
+	orgName := organization.Login
+	result.OrgName = orgName
+	result.recordApply(applyMode, "organization", !orgExists)
+
+	//Install app on organization if app installation provided and not PAT
+	if ctx.Value(config.TokenKey) == nil {
+		installed := false
+		if orgExists {
+			installed, err = isAppInstalledOnOrg(ctx, logger, orgName)
+			if err != nil {
+				result.Error = fmt.Sprintf("Failed to check app installation: %v", err)
+				return result
+			}
 		}
-		orgName := organization.Login
-		result.OrgName = orgName
-
-		//Install app on organization if app installation provided and not PAT
-		if ctx.Value(config.TokenKey) == nil {
 
+		if !installed {
+			// A just-created org can take a moment to reach the app installation endpoint;
+			// a failed wait is left for the installation itself to report
+			if !orgExists {
+				if err := api.WaitForOrgVisible(ctx, logger, orgName); err != nil {
+					logger.Warn("Installing the app before the organization is visible",
+						slog.String("org", orgName),
+						slog.Any("error", err))
+				}
+			}
 			_, err = enterprise.InstallAppOnOrg(ctx, logger, orgName)
 			if err != nil {
 				logger.Error("Failed to install app on organization",
 					slog.String("org", orgName),
 					slog.Any("error", err))
 				result.Error = fmt.Sprintf("Failed to install app: %v", err)
-				resultsChan <- result
-				continue
+				return result
 			}
 		}
+		result.recordApply(applyMode, "app installation", !installed)
 
-		// Add organization name to context for token scoping (must be after app installation)
-		ctx = context.WithValue(ctx, config.OrgKey, orgName)
-
-		// Add the user as admin after app installation (if not already in facilitators list)
-		facilitators := ctx.Value(config.FacilitatorsKey).([]string)
-		isUserInFacilitators := false
-		for _, facilitator := range facilitators {
-			if facilitator == user {
-				isUserInFacilitators = true
-				break
+		// Recorded separately from the org's status: a bad installation doesn't fail the org
+		if verifyInstall, _ := ctx.Value(config.VerifyInstallKey).(bool); verifyInstall {
+			result.InstallVerification, err = api.VerifyAppInstallation(ctx, logger, orgName)
+			if err != nil {
+				result.InstallVerification = &api.InstallVerification{
+					Status: api.InstallUnverified,
+					Detail: fmt.Sprintf("failed to list installations: %v", err),
+				}
 			}
 		}
+	}
 
-		if !isUserInFacilitators && len(facilitators) > 0 {
-			logger.Info("Adding user as organization admin", slog.String("user", user), slog.String("org", orgName))
-			if err := api.AddOrgMember(ctx, logger, orgName, user, "admin"); err != nil {
-				logger.Error("Failed to add user as admin",
-					slog.String("user", user),
-					slog.String("org", orgName),
-					slog.Any("error", err))
-				logger.Warn("Organization created but user was not added as admin - manual intervention may be required")
-			}
+	// Add organization name to context for token scoping (must be after app installation)
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	// Add the user as admin after app installation (if not already in facilitators list)
+	facilitators, _ := ctx.Value(config.FacilitatorsKey).([]string)
+	isUserInFacilitators := false
+	for _, facilitator := range facilitators {
+		if facilitator == user {
+			isUserInFacilitators = true
+			break
+		}
+	}
+
+	if orgExists {
+		// The org may predate this run, so check every membership instead of assuming
+		// the ones made at creation time
+		ensureOrgMemberships(ctx, logger, &result, orgName, user, facilitators)
+	} else if !isUserInFacilitators && len(facilitators) > 0 {
+		logger.Info("Adding user as organization admin", slog.String("user", user), slog.String("org", orgName))
+		if err := api.AddOrgMember(ctx, logger, orgName, user, "admin"); err != nil {
+			logger.Error("Failed to add user as admin",
+				slog.String("user", user),
+				slog.String("org", orgName),
+				slog.Any("error", err))
+			logger.Warn("Organization created but user was not added as admin - manual intervention may be required")
 		}
+	}
 
-		logger.Info("Creating repositories in organization", slog.String("org", orgName))
+	if !orgExists {
+		result.FacilitatorRoles = applyFacilitatorRole(ctx, logger, orgName, user, facilitators)
+	}
 
-		// Track each repository creation
-		for _, repoConfig := range templateRepos {
-			logger.Info("Creating repository",
-				slog.String("repo", repoConfig.Template),
-				slog.Bool("include_all_branches", repoConfig.IncludeAllBranches))
+	// Set before the repositories are created so they pick up the org defaults too
+	if isDependabotEnabled(ctx) {
+		result.Dependabot = newDependabotResult(api.EnableOrgDependabot(ctx, logger, orgName))
+	}
+	if orgPolicy != nil {
+		result.OrgPolicy = applyOrgPolicy(ctx, logger, orgName, user, orgPolicy)
+	}
 
-			repoResult := RepoReport{
-				Name:   repoConfig.Template,
-				Status: "failed",
-			}
+	orgTemplates := templateRepos
+	if useFacilitatorTemplates {
+		result.TemplateSet = "student"
+		if isUserInFacilitators {
+			orgTemplates = facilitatorTemplates
+			result.TemplateSet = "facilitator"
+		}
+	}
 
-			createdRepo, err := organization.CreateRepoFromTemplate(ctx, logger, repoConfig.Template, repoConfig.IncludeAllBranches)
-			if err != nil {
-				logger.Error("Failed to create repository",
-					slog.String("repo", repoConfig.Template),
-					slog.Any("error", err))
-				repoResult.Error = fmt.Sprintf("%v", err)
-			} else {
-				repoResult.Status = "success"
-				repoResult.URL = createdRepo.HTMLURL
-			}
+	if len(orgTemplates) > 0 {
+		logger.Info("Creating repositories in organization",
+			slog.String("org", orgName),
+			slog.Int("count", len(orgTemplates)))
+	}
+
+	// Track each repository creation
+	for _, repoConfig := range orgTemplates {
+		repoResult := RepoReport{
+			Name:   repoConfig.SourceRef(),
+			Status: "failed",
+		}
+		if source := repoSource(ctx, repoConfig); source != util.RepoSourceTemplate {
+			repoResult.Source = source
+		}
+
+		// Substitute per-user values right before creation
+		repoConfig, err := repoConfig.Expand(util.RepoTemplateVars{User: user, Date: labDate, Org: orgName})
+		if err != nil {
+			logger.Error("Failed to expand repository config",
+				slog.String("repo", repoResult.Name),
+				slog.Any("error", err))
+			repoResult.Error = err.Error()
 			result.Repos = append(result.Repos, repoResult)
+			continue
 		}
+		repoResult.Name = repoConfig.SourceRef()
 
-		// Mark as success and send result
-		result.Status = "success"
-		resultsChan <- result
-		logger.Info("Finished creating organization", slog.String("org", orgName))
+		// Repositories that already exist are left untouched in apply mode
+		if orgExists {
+			existingRepo, err := api.GetRepository(ctx, logger, orgName, repoConfig.RepoName())
+			if err == nil {
+				logger.Info("Repository already exists, skipping",
+					slog.String("org", orgName),
+					slog.String("repo", repoConfig.RepoName()))
+				repoResult.Status = "skipped"
+				repoResult.URL = existingRepo.HTMLURL
+				repoResult.DefaultBranch = existingRepo.DefaultBranch
+				if isDependabotEnabled(ctx) {
+					repoResult.Dependabot = enableRepoDependabot(ctx, logger, organization, existingRepo.Name)
+				}
+				if branchProtection != nil {
+					repoResult.BranchProtection = protectDefaultBranch(ctx, logger, organization, existingRepo.Name, existingRepo.DefaultBranch, branchProtection)
+				}
+				result.Repos = append(result.Repos, repoResult)
+				continue
+			}
+			if !errors.Is(err, api.ErrRepositoryNotFound) {
+				repoResult.Error = fmt.Sprintf("failed to check for existing repository: %v", err)
+				result.Repos = append(result.Repos, repoResult)
+				continue
+			}
+		}
+
+		logger.Info("Creating repository",
+			slog.String("source", repoSource(ctx, repoConfig)),
+			slog.String("repo", repoConfig.SourceRef()),
+			slog.String("name", repoConfig.RepoName()),
+			slog.Bool("include_all_branches", repoConfig.IncludeAllBranches))
+
+		createdRepo, err := createConfiguredRepo(ctx, logger, organization, repoConfig)
+		if err != nil {
+			logger.Error("Failed to create repository",
+				slog.String("repo", repoConfig.SourceRef()),
+				slog.Any("error", err))
+			repoResult.Error = fmt.Sprintf("%v", err)
+		} else {
+			repoResult.Status = "success"
+			repoResult.URL = createdRepo.HTMLURL
+			result.CreatedRepos = append(result.CreatedRepos, util.CreatedRepo{Org: orgName, Repo: createdRepo.Name, Template: repoConfig.SourceRef()})
+			repoResult.DefaultBranch, repoResult.Settings, repoResult.Warnings = configureCreatedRepo(ctx, logger, organization, createdRepo, repoConfig)
+			if isDependabotEnabled(ctx) {
+				repoResult.Dependabot = enableRepoDependabot(ctx, logger, organization, createdRepo.Name)
+			}
+			if branchProtection != nil {
+				repoResult.BranchProtection = protectDefaultBranch(ctx, logger, organization, createdRepo.Name, repoResult.DefaultBranch, branchProtection)
+			}
+		}
+		result.Repos = append(result.Repos, repoResult)
 	}
 
-	logger.Info("Worker stopped", slog.Int("workerId", workerId))
+	// Mark as success
+	result.Status = "success"
+	logger.Info("Finished creating organization", slog.String("org", orgName))
+	return result
 }
 
 func CreateLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile string, templateReposFile string) error {
+	_, err := createLabEnvironment(ctx, logger, usersFile, templateReposFile)
+	return err
+}
+
+// CreateLabEnvironments runs the full create flow once per lab date, producing a
+// report per date plus a combined cohort summary. A failure for one date does not
+// stop the remaining dates from being provisioned.
+func CreateLabEnvironments(ctx context.Context, logger *slog.Logger, labDates []string, usersFile string, templateReposFile string) error {
+	summaries := make([]CohortDateSummary, 0, len(labDates))
+	failedDates := 0
+
+	for _, labDate := range labDates {
+		logger.Info("Provisioning lab date", slog.String("lab_date", labDate))
 
+		dateCtx := context.WithValue(ctx, config.LabDateKey, labDate)
+		report, err := createLabEnvironment(dateCtx, logger, usersFile, templateReposFile)
+
+		summary := CohortDateSummary{LabDate: labDate}
+		if report != nil {
+			summary.TotalUsers = report.TotalUsers
+			summary.SuccessCount = report.SuccessCount
+			summary.FailureCount = report.FailureCount
+		}
+		if err != nil {
+			failedDates++
+			summary.Error = err.Error()
+			logger.Error("Failed to provision lab date",
+				slog.String("lab_date", labDate),
+				slog.Any("error", err))
+		}
+		summaries = append(summaries, summary)
+	}
+
+	var runErr error
+	if failedDates > 0 {
+		runErr = fmt.Errorf("failed to provision %d of %d lab date(s)", failedDates, len(labDates))
+	}
+
+	reportOpts := ReportOptionsFromContext(ctx)
+	reportErr := GenerateCohortSummaryFile(summaries, reportOpts)
+	return ResolveRunError(logger, reportOpts, runErr, reportErr)
+}
+
+// createLabEnvironment provisions a single lab date and returns the generated report.
+// The report is nil if provisioning stopped before any results were collected.
+func createLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile string, templateReposFile string) (*LabReport, error) {
+	startTime := time.Now()
+
+	plan, err := buildLabPlan(ctx, logger, usersFile, templateReposFile)
+	if err != nil {
+		return nil, err
+	}
+	return provisionLabPlan(ctx, logger, plan, startTime)
+}
+
+// buildLabPlan loads and validates the users and template repos files and resolves
+// everything the provisioning phase needs. No organization or repository is touched.
+func buildLabPlan(ctx context.Context, logger *slog.Logger, usersFile string, templateReposFile string) (*LabPlan, error) {
 	//Get users
 	logger.Info("Loading users from file", slog.String("file", usersFile))
 	users, err := util.LoadFromFile(usersFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	logger.Info("Loaded users", slog.Int("count", len(users)))
 
+	return buildLabPlanForUsers(ctx, logger, users, templateReposFile)
+}
+
+// buildLabPlanForUsers builds the plan for the given students; facilitators come from
+// the context as usual
+func buildLabPlanForUsers(ctx context.Context, logger *slog.Logger, users []string, templateReposFile string) (*LabPlan, error) {
+	filter := newUserFilterFromContext(ctx)
+	users = filter.apply(logger, users)
+
+	// Get lab date from context
+	labDate, ok := ctx.Value(config.LabDateKey).(string)
+	if !ok {
+		logger.Error("Lab date not found in context")
+		return nil, fmt.Errorf("lab date not found in context")
+	}
+
 	// Get facilitators from context
 	facilitators, _ := ctx.Value(config.FacilitatorsKey).([]string)
 
+	// Reject users whose org login would be invalid before making any API calls
+	users, invalidUsers := filterInvalidOrgLogins(logger, labDate, users)
+	if err := checkRequireAllValid(ctx, invalidUsers, nil); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no users with a valid org login for lab date %s", labDate)
+	}
+
 	// Validate and filter users
 	logger.Info("Validating users", slog.Int("count", len(users)))
 	userValidation, err := api.ValidateAndFilterUsers(ctx, logger, users)
 	if err != nil {
 		logger.Error("User validation failed", slog.Any("error", err))
-		return fmt.Errorf("user validation failed: %w", err)
+		return nil, fmt.Errorf("user validation failed: %w", err)
 	}
 
-	invalidUsers := userValidation.InvalidUsers
+	invalidUsers = append(invalidUsers, userValidation.InvalidUsers...)
 	users = userValidation.ValidUsers
 
 	// Validate and filter facilitators
-	invalidFacilitators := []string{}
+	invalidFacilitators := []api.InvalidUser{}
 	if len(facilitators) > 0 {
 		logger.Info("Validating facilitators", slog.Int("count", len(facilitators)))
 		facilitatorValidation, err := api.ValidateAndFilterUsers(ctx, logger, facilitators)
 		if err != nil {
 			logger.Error("Facilitator validation failed", slog.Any("error", err))
-			return fmt.Errorf("facilitator validation failed: %w", err)
+			return nil, fmt.Errorf("facilitator validation failed: %w", err)
 		}
 		invalidFacilitators = facilitatorValidation.InvalidUsers
 		facilitators = facilitatorValidation.ValidUsers
-		// Update context with filtered facilitators
-		ctx = context.WithValue(ctx, config.FacilitatorsKey, facilitators)
+	}
+	if err := checkRequireAllValid(ctx, invalidUsers, invalidFacilitators); err != nil {
+		return nil, err
 	}
 
 	// Combine users and facilitators for provisioning
@@ -179,16 +577,32 @@ func CreateLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile st
 		userSet[user] = true
 	}
 
-	// Add facilitators only if not already present
-	for _, facilitator := range facilitators {
-		userSet[facilitator] = true
+	// Add facilitators only if not already present. Facilitators whose personal org
+	// login would be invalid remain org admins but don't get an org of their own.
+	facilitatorsAdminsOnly, _ := ctx.Value(config.FacilitatorsAdminsOnlyKey).(bool)
+	if facilitatorsAdminsOnly {
+		logger.Info("Facilitators will only be admins on student organizations; no facilitator organizations will be created")
+	} else {
+		for _, facilitator := range facilitators {
+			if !filter.allows(facilitator) {
+				continue
+			}
+			if err := util.ValidateOrgLogin(util.BuildOrgLogin(labDate, facilitator)); err != nil {
+				logger.Warn("Skipping personal organization for facilitator",
+					slog.String("facilitator", facilitator),
+					slog.String("reason", err.Error()))
+				continue
+			}
+			userSet[facilitator] = true
+		}
 	}
 
-	// Convert map to slice
+	// Convert map to slice, sorted so plans of the same input are identical
 	allUsersToProvision := make([]string, 0, len(userSet))
 	for user := range userSet {
 		allUsersToProvision = append(allUsersToProvision, user)
 	}
+	sort.Strings(allUsersToProvision)
 
 	logger.Info("Proceeding with validated users",
 		slog.Int("student_count", len(users)),
@@ -197,61 +611,174 @@ func CreateLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile st
 		slog.Int("invalid_user_count", len(invalidUsers)),
 		slog.Int("invalid_facilitator_count", len(invalidFacilitators)))
 
-	templateRepos, err := util.LoadFromJsonFile(templateReposFile)
-	if err != nil {
-		return err
+	// With --orgs-only there is no template repos file and the repository loop is skipped
+	orgsOnly, _ := ctx.Value(config.OrgsOnlyKey).(bool)
+	var templateRepos []util.RepoConfig
+	var excludedTemplates []string
+	if orgsOnly {
+		logger.Info("Orgs-only mode, repositories will not be created")
+	} else {
+		templateRepos, err = util.LoadFromJsonFile(templateReposFile)
+		if err != nil {
+			return nil, err
+		}
+		excludeTemplates, _ := ctx.Value(config.ExcludeTemplatesKey).([]string)
+		templateRepos, excludedTemplates = excludeTemplateRepos(logger, templateRepos, excludeTemplates)
+	}
+
+	// Facilitators' own organizations can get a smaller template set than students'
+	facilitatorTemplatesFile, _ := ctx.Value(config.FacilitatorTemplatesKey).(string)
+	useFacilitatorTemplates := facilitatorTemplatesFile != "" && !orgsOnly
+	var facilitatorTemplates []util.RepoConfig
+	if useFacilitatorTemplates {
+		facilitatorTemplates, err = util.LoadFromJsonFile(facilitatorTemplatesFile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded facilitator template repositories",
+			slog.String("file", facilitatorTemplatesFile),
+			slog.Int("count", len(facilitatorTemplates)))
+	}
+
+	var orgPolicy *util.OrgPolicy
+	if orgPolicyFile, _ := ctx.Value(config.OrgPolicyKey).(string); orgPolicyFile != "" {
+		orgPolicy, err = util.LoadOrgPolicy(orgPolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded org policy",
+			slog.String("file", orgPolicyFile),
+			slog.Int("settings", len(orgPolicy.Settings)),
+			slog.Bool("ip_allow_list", orgPolicy.IPAllowList != nil),
+			slog.Bool("seats", orgPolicy.Seats != nil))
+	}
+
+	var branchProtection *util.BranchProtection
+	if branchProtectionFile, _ := ctx.Value(config.BranchProtectionKey).(string); branchProtectionFile != "" && !orgsOnly {
+		branchProtection, err = util.LoadBranchProtection(branchProtectionFile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded branch protection template", slog.String("file", branchProtectionFile))
 	}
 
 	// Get enterprise slug from context
 	enterpriseSlug, ok := ctx.Value(config.EnterpriseSlugKey).(string)
 	if !ok {
 		logger.Error("Enterprise slug not found in context")
-		return fmt.Errorf("enterprise slug not found in context")
+		return nil, fmt.Errorf("enterprise slug not found in context")
 	}
 
-	// Get lab date from context
-	labDate, ok := ctx.Value(config.LabDateKey).(string)
-	if !ok {
-		logger.Error("Lab date not found in context")
-		return fmt.Errorf("lab date not found in context")
+	plan := &LabPlan{
+		Version:             labPlanVersion,
+		GeneratedAt:         time.Now(),
+		LabDate:             labDate,
+		EnterpriseSlug:      enterpriseSlug,
+		Users:               users,
+		Facilitators:        facilitators,
+		Orgs:                make([]PlannedOrg, 0, len(allUsersToProvision)),
+		InvalidUsers:        invalidUsers,
+		InvalidFacilitators: invalidFacilitators,
+		UserFilters:         filter.report(allUsersToProvision),
+		TemplateRepos:       templateRepos,
+		ExcludedTemplates:   excludedTemplates,
+		OrgPolicy:           orgPolicy,
+		BranchProtection:    branchProtection,
+		Settings:            planSettingsFromContext(ctx),
+	}
+	if useFacilitatorTemplates {
+		plan.FacilitatorTemplates = facilitatorTemplates
+	}
+	for _, user := range allUsersToProvision {
+		plan.Orgs = append(plan.Orgs, PlannedOrg{User: user, OrgName: util.BuildOrgLogin(labDate, user)})
+	}
+
+	// The estimate is informational, so it never stops the run
+	if isPreflightEnabled(ctx) {
+		orgLogins := make([]string, 0, len(plan.Orgs))
+		for _, org := range plan.Orgs {
+			orgLogins = append(orgLogins, org.OrgName)
+		}
+		estimate, err := estimateOrgCreation(ctx, logger, enterpriseSlug, orgLogins)
+		if err != nil {
+			logger.Warn("Skipping organization creation estimate", slog.Any("error", err))
+		} else {
+			logOrgEstimate(logger, estimate)
+			plan.Estimate = estimate
+		}
+	}
+	return plan, nil
+}
+
+// provisionLabPlan creates the organizations and repositories described by the plan and
+// writes the lab report. The plan's settings take precedence over those in the context.
+func provisionLabPlan(ctx context.Context, logger *slog.Logger, plan *LabPlan, startTime time.Time) (*LabReport, error) {
+	ctx = plan.Settings.apply(ctx)
+	ctx = context.WithValue(ctx, config.LabDateKey, plan.LabDate)
+	ctx = context.WithValue(ctx, config.FacilitatorsKey, plan.Facilitators)
+
+	labDate := plan.LabDate
+	enterpriseSlug := plan.EnterpriseSlug
+	facilitators := plan.Facilitators
+	invalidUsers := plan.InvalidUsers
+	invalidFacilitators := plan.InvalidFacilitators
+	templateRepos := plan.TemplateRepos
+	excludedTemplates := plan.ExcludedTemplates
+	facilitatorTemplates := plan.FacilitatorTemplates
+	useFacilitatorTemplates := plan.FacilitatorTemplates != nil
+	orgsOnly := plan.Settings.OrgsOnly
+	facilitatorsAdminsOnly := plan.Settings.FacilitatorsAdminsOnly
+
+	allUsersToProvision := make([]string, 0, len(plan.Orgs))
+	userSet := make(map[string]bool, len(plan.Orgs))
+	for _, org := range plan.Orgs {
+		allUsersToProvision = append(allUsersToProvision, org.User)
+		userSet[org.User] = true
 	}
 
 	//Get Enterprise details
-	enterprise, err := api.GetEnterprise(ctx, logger, enterpriseSlug)
+	enterprise, err := api.ResolveEnterprise(ctx, logger, enterpriseSlug)
 	if err != nil {
 		logger.Error("Failed to get enterprise details", slog.String("slug", enterpriseSlug), slog.Any("error", err))
-		return err
+		if errors.Is(err, api.ErrGraphQLInsufficientScopes) {
+			return nil, fmt.Errorf("credentials lack the scopes required to read enterprise %s: %w", enterpriseSlug, err)
+		}
+		return nil, err
+	}
+
+	// Invite users who aren't yet enterprise members so they can be made org admins
+	var enterpriseInvites *EnterpriseInviteSummary
+	if invite, _ := ctx.Value(config.InviteToEnterpriseKey).(bool); invite {
+		inviteUsers := append([]string{}, allUsersToProvision...)
+		for _, facilitator := range facilitators {
+			if !userSet[facilitator] {
+				inviteUsers = append(inviteUsers, facilitator)
+			}
+		}
+		logger.Info("Inviting users to enterprise", slog.Int("count", len(inviteUsers)))
+		enterpriseInvites = inviteUsersToEnterprise(ctx, logger, enterprise, inviteUsers)
 	}
 
-	orgChan := make(chan string, len(allUsersToProvision))
+	// Queue all users (students + facilitators); it closes once each has a result
+	queue := newOrgQueue(ctx, allUsersToProvision)
 	// Update channel size to accommodate all users
 	resultsChan := make(chan ProvisionResult, len(allUsersToProvision))
 
 	// Use WaitGroup to track worker goroutines
 	var wg sync.WaitGroup
 
-	// Calculate optimal number of workers: max 9 or number of users
-	numWorkers := 9
-	if len(allUsersToProvision) < numWorkers {
-		numWorkers = len(allUsersToProvision)
-	}
+	numWorkers := WorkerCount(ctx, len(allUsersToProvision))
 	logger.Info("Starting workers", slog.Int("worker_count", numWorkers), slog.Int("total_user_count", len(allUsersToProvision)))
 
+	hooks := newProvisionHooks(ctx)
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func(workerId int) {
 			defer wg.Done()
-			ProvisionOrgResources(workerId, ctx, logger, orgChan, resultsChan, enterprise, templateRepos)
+			ProvisionOrgResources(workerId, ctx, logger, queue, resultsChan, enterprise, templateRepos, facilitatorTemplates, useFacilitatorTemplates, plan.OrgPolicy, plan.BranchProtection, hooks)
 		}(i)
 	}
 
-	// Send all users (students + facilitators) to the channel
-	for _, user := range allUsersToProvision {
-		orgChan <- user
-	}
-	// Close orgChan immediately after sending all work
-	close(orgChan)
-
 	// Close resultsChan once all workers are done
 	go func() {
 		wg.Wait()
@@ -275,45 +802,91 @@ func CreateLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile st
 					slog.Int("failed", failureCount))
 
 				// Generate report
+				completedAt := time.Now()
 				report := &LabReport{
-					GeneratedAt:         time.Now(),
-					LabDate:             labDate,
-					EnterpriseSlug:      enterpriseSlug,
-					TotalUsers:          len(allUsersToProvision),
-					SuccessCount:        successCount,
-					FailureCount:        failureCount,
-					TemplateRepos:       getTemplateNames(templateRepos),
-					Facilitators:        facilitators,
-					InvalidUsers:        invalidUsers,
-					InvalidFacilitators: invalidFacilitators,
-					Organizations:       make([]OrgReport, 0, len(results)),
+					GeneratedAt:            completedAt,
+					StartedAt:              startTime,
+					CompletedAt:            completedAt,
+					DurationMs:             completedAt.Sub(startTime).Milliseconds(),
+					LabDate:                labDate,
+					EnterpriseSlug:         enterpriseSlug,
+					TotalUsers:             len(allUsersToProvision),
+					SuccessCount:           successCount,
+					FailureCount:           failureCount,
+					TemplateRepos:          getTemplateNames(templateRepos),
+					ExcludedTemplates:      excludedTemplates,
+					FacilitatorTemplates:   facilitatorTemplateNames(useFacilitatorTemplates, facilitatorTemplates),
+					Facilitators:           facilitators,
+					FacilitatorsAdminsOnly: facilitatorsAdminsOnly,
+					OrgsOnly:               orgsOnly,
+					WaitBetweenOrgs:        waitBetweenOrgsLabel(ctx),
+					InvalidUsers:           invalidUsers,
+					InvalidFacilitators:    invalidFacilitators,
+					UserFilters:            plan.UserFilters,
+					EnterpriseInvites:      enterpriseInvites,
+					Apply:                  isApplyMode(ctx),
+					Organizations:          make([]OrgReport, 0, len(results)),
 				}
 
 				for _, res := range results {
 					orgReport := OrgReport{
-						User:         res.User,
-						OrgName:      res.OrgName,
-						Status:       res.Status,
-						Error:        res.Error,
-						Repositories: res.Repos,
-						CreatedAt:    res.CompletedAt,
+						User:             res.User,
+						OrgName:          res.OrgName,
+						Status:           res.Status,
+						Error:            res.Error,
+						Repositories:     res.Repos,
+						CreatedAt:        res.CompletedAt,
+						FacilitatorRoles: res.FacilitatorRoles,
+						Created:          res.Created,
+						AlreadyPresent:   res.AlreadyPresent,
+						Attempts:         res.OrgAttempts,
+						TemplateSet:      res.TemplateSet,
+						Installation:     res.InstallVerification,
+						Dependabot:       res.Dependabot,
+						OrgPolicy:        res.OrgPolicy,
+						PreHook:          res.PreHook,
+						PostHook:         res.PostHook,
 					}
 					report.Organizations = append(report.Organizations, orgReport)
+					if res.PacingWait {
+						report.PacingWaits++
+					}
 				}
+				report.SharedRepo = provisionSharedRepo(ctx, logger, report)
 
-				// Generate report files
-				if err := GenerateReportFiles(report, "reports"); err != nil {
-					logger.Error("Failed to generate report files", slog.Any("error", err))
+				// Teardown relies on this list, so failing to write it fails the run
+				var created []util.CreatedRepo
+				for _, res := range results {
+					created = append(created, res.CreatedRepos...)
+				}
+				outputErr := recordCreatedRepos(ctx, logger, created)
+
+				if hooks.isAborted() {
+					outputErr = errors.Join(outputErr, errHookFailed)
 				}
 
+				// Generate report files
+				reportOpts := ReportOptionsFromContext(ctx)
+				reportErr := GenerateReportFiles(report, reportOpts)
+
 				if resultCount == len(allUsersToProvision) {
 					logger.Info("All organizations and repositories created successfully")
-					return nil
+					return report, ResolveRunError(logger, reportOpts, outputErr, reportErr)
 				}
 				logger.Error("Workers finished but not all users processed",
 					slog.Int("expected", len(allUsersToProvision)),
 					slog.Int("processed", resultCount))
-				return ctx.Err()
+				return report, ResolveRunError(logger, reportOpts, errors.Join(ctx.Err(), outputErr), reportErr)
+			}
+
+			// Run --post-hook as each successful result arrives; a failure only fails the
+			// org with --hook-failure-mode fail
+			if res.Status == "success" {
+				res.PostHook = hooks.runPostHook(ctx, logger, res.User, res.OrgName)
+				if res.PostHook != nil && res.PostHook.Status != "success" && hooks.failRun {
+					res.Status = "failed"
+					res.Error = res.PostHook.Error
+				}
 			}
 
 			// Track results
@@ -332,16 +905,107 @@ func CreateLabEnvironment(ctx context.Context, logger *slog.Logger, usersFile st
 
 		case <-ctx.Done():
 			logger.Error("Timeout reached while creating lab environment")
-			return ctx.Err()
+			return nil, ctx.Err()
 		}
 	}
 }
 
+// checkRequireAllValid returns an error listing every invalid user and facilitator when
+// --require-all-valid is set, so a bad roster stops the run instead of being skipped
+func checkRequireAllValid(ctx context.Context, invalidUsers []api.InvalidUser, invalidFacilitators []api.InvalidUser) error {
+	requireAllValid, _ := ctx.Value(config.RequireAllValidKey).(bool)
+	if !requireAllValid || len(invalidUsers)+len(invalidFacilitators) == 0 {
+		return nil
+	}
+
+	invalid := make([]string, 0, len(invalidUsers)+len(invalidFacilitators))
+	for _, u := range invalidUsers {
+		invalid = append(invalid, fmt.Sprintf("%s (%s)", u.Name, u.Reason))
+	}
+	for _, u := range invalidFacilitators {
+		invalid = append(invalid, fmt.Sprintf("facilitator %s (%s)", u.Name, u.Reason))
+	}
+	return fmt.Errorf("--require-all-valid: %d invalid user(s), nothing was provisioned: %s", len(invalid), strings.Join(invalid, ", "))
+}
+
+// filterInvalidOrgLogins splits users into those whose resulting org login is valid
+// and those that would produce an invalid login, logging the reason for each rejection
+func filterInvalidOrgLogins(logger *slog.Logger, labDate string, users []string) ([]string, []api.InvalidUser) {
+	valid := make([]string, 0, len(users))
+	invalid := []api.InvalidUser{}
+	for _, user := range users {
+		if err := util.ValidateOrgLogin(util.BuildOrgLogin(labDate, user)); err != nil {
+			logger.Warn("User will be skipped",
+				slog.String("user", user),
+				slog.String("reason", err.Error()))
+			invalid = append(invalid, api.InvalidUser{Name: user, Reason: err.Error()})
+			continue
+		}
+		valid = append(valid, user)
+	}
+	return valid, invalid
+}
+
+// excludeTemplateRepos drops the --exclude-templates entries from the loaded template
+// repos, matching owner/repo case-insensitively. Returns the remaining repos and the
+// templates that were actually excluded; exclusions matching nothing are logged.
+func excludeTemplateRepos(logger *slog.Logger, configs []util.RepoConfig, exclude []string) ([]util.RepoConfig, []string) {
+	if len(exclude) == 0 {
+		return configs, nil
+	}
+
+	kept := make([]util.RepoConfig, 0, len(configs))
+	var excluded []string
+	matched := make(map[string]bool, len(exclude))
+	for _, repoConfig := range configs {
+		skip := false
+		for _, e := range exclude {
+			if strings.EqualFold(repoConfig.SourceRef(), e) {
+				matched[strings.ToLower(e)] = true
+				skip = true
+			}
+		}
+		if skip {
+			excluded = append(excluded, repoConfig.SourceRef())
+			continue
+		}
+		kept = append(kept, repoConfig)
+	}
+
+	for _, e := range exclude {
+		if !matched[strings.ToLower(e)] {
+			logger.Warn("Excluded template is not in the template repos file", slog.String("template", e))
+		}
+	}
+	if len(excluded) > 0 {
+		logger.Info("Excluding template repositories for this run", slog.Any("templates", excluded))
+	}
+	return kept, excluded
+}
+
 // Helper function to extract template names for the report
+// waitBetweenOrgsLabel returns the --wait-between-orgs pace for the report, empty when unset
+func waitBetweenOrgsLabel(ctx context.Context) string {
+	wait, _ := ctx.Value(config.WaitBetweenOrgsKey).(time.Duration)
+	if wait <= 0 {
+		return ""
+	}
+	return wait.String()
+}
+
+// facilitatorTemplateNames returns the facilitator template names for the report, or nil
+// when --facilitator-templates isn't set
+func facilitatorTemplateNames(useFacilitatorTemplates bool, configs []util.RepoConfig) []string {
+	if !useFacilitatorTemplates {
+		return nil
+	}
+	return getTemplateNames(configs)
+}
+
 func getTemplateNames(configs []util.RepoConfig) []string {
 	names := make([]string, len(configs))
 	for i, config := range configs {
-		names[i] = config.Template
+		names[i] = config.SourceRef()
 	}
 	return names
 }
@@ -358,11 +1022,12 @@ func DestroyOrgResources(workerId int, ctx context.Context, logger *slog.Logger,
 		default:
 		}
 
-		orgName := "ghas-labs-" + labDate + "-" + user
-		logger.Info("Deleting organization", slog.String("org", orgName), slog.String("user", user))
+		orgName := util.BuildOrgLogin(labDate, user)
+		orgLogger := orgRunLogger(logger, workerId, user)
+		orgLogger.Info("Deleting organization", slog.String("org", orgName), slog.String("user", user))
 
-		if err := api.DeleteOrg(ctx, logger, orgName); err != nil {
-			logger.Error("Failed to delete organization",
+		if err := api.DeleteOrg(ctx, orgLogger, orgName); err != nil {
+			orgLogger.Error("Failed to delete organization",
 				slog.String("user", user),
 				slog.String("org", orgName),
 				slog.Any("error", err))
@@ -372,13 +1037,17 @@ func DestroyOrgResources(workerId int, ctx context.Context, logger *slog.Logger,
 		}
 
 		resultsChan <- orgName
-		logger.Info("Finished deleting organization", slog.String("org", orgName))
+		orgLogger.Info("Finished deleting organization", slog.String("org", orgName))
 	}
 
 	logger.Info("Destroy worker stopped", slog.Int("workerId", workerId))
 }
 
-func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate string, usersFile string) error {
+// DestroyLabEnvironment deletes the lab organizations for labDate and writes the delete
+// report. The report is returned whenever deletion was attempted, including on failure
+// and timeout, so callers can inspect counts and per-org statuses; it is nil when the run
+// stopped before deleting anything.
+func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate string, usersFile string) (*DeleteLabReport, error) {
 
 	startTime := time.Now()
 
@@ -386,16 +1055,19 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 	logger.Info("Loading users from file", slog.String("file", usersFile))
 	users, err := util.LoadFromFile(usersFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	logger.Info("Loaded users", slog.Int("count", len(users)))
 
+	filter := newUserFilterFromContext(ctx)
+	users = filter.apply(logger, users)
+
 	// Get enterprise slug from context
 	enterpriseSlug, ok := ctx.Value(config.EnterpriseSlugKey).(string)
 	if !ok {
 		logger.Error("Enterprise slug not found in context")
-		return fmt.Errorf("enterprise slug not found in context")
+		return nil, fmt.Errorf("enterprise slug not found in context")
 	}
 
 	// Get facilitators from context
@@ -406,20 +1078,20 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 	userValidation, err := api.ValidateAndFilterUsers(ctx, logger, users)
 	if err != nil {
 		logger.Error("User validation failed", slog.Any("error", err))
-		return fmt.Errorf("user validation failed: %w", err)
+		return nil, fmt.Errorf("user validation failed: %w", err)
 	}
 
 	invalidUsers := userValidation.InvalidUsers
 	users = userValidation.ValidUsers
 
 	// Validate and filter facilitators
-	invalidFacilitators := []string{}
+	invalidFacilitators := []api.InvalidUser{}
 	if len(facilitators) > 0 {
 		logger.Info("Validating facilitators", slog.Int("count", len(facilitators)))
 		facilitatorValidation, err := api.ValidateAndFilterUsers(ctx, logger, facilitators)
 		if err != nil {
 			logger.Error("Facilitator validation failed", slog.Any("error", err))
-			return fmt.Errorf("facilitator validation failed: %w", err)
+			return nil, fmt.Errorf("facilitator validation failed: %w", err)
 		}
 		invalidFacilitators = facilitatorValidation.InvalidUsers
 		facilitators = facilitatorValidation.ValidUsers
@@ -433,14 +1105,38 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 		userSet[user] = true
 	}
 
-	for _, facilitator := range facilitators {
-		userSet[facilitator] = true
+	// Facilitator orgs weren't created in admins-only mode, so there is nothing to delete
+	if facilitatorsAdminsOnly, _ := ctx.Value(config.FacilitatorsAdminsOnlyKey).(bool); !facilitatorsAdminsOnly {
+		for _, facilitator := range facilitators {
+			if !filter.allows(facilitator) {
+				continue
+			}
+			userSet[facilitator] = true
+		}
+	}
+
+	// Orgs created for users that have since left the users file would otherwise be missed
+	var discoveredOrgs []string
+	if discover, _ := ctx.Value(config.DiscoverOrgsKey).(bool); discover {
+		discoveredUsers, err := discoverLabOrgUsers(ctx, logger, enterpriseSlug, labDate, userSet)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range discoveredUsers {
+			if !filter.allows(user) {
+				continue
+			}
+			userSet[user] = true
+			discoveredOrgs = append(discoveredOrgs, util.BuildOrgLogin(labDate, user))
+		}
 	}
 
 	allUsersToDelete := make([]string, 0, len(userSet))
 	for user := range userSet {
 		allUsersToDelete = append(allUsersToDelete, user)
 	}
+	preserve, _ := ctx.Value(config.PreserveUsersKey).([]string)
+	allUsersToDelete, preservedUsers := splitPreservedUsers(logger, allUsersToDelete, preserve)
 
 	logger.Info("Proceeding with validated users for deletion",
 		slog.Int("student_count", len(users)),
@@ -449,11 +1145,19 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 		slog.Int("invalid_user_count", len(invalidUsers)),
 		slog.Int("invalid_facilitator_count", len(invalidFacilitators)))
 
+	orgNames := make([]string, 0, len(allUsersToDelete))
+	for _, user := range allUsersToDelete {
+		orgNames = append(orgNames, util.BuildOrgLogin(labDate, user))
+	}
+	if err := CheckDeletionPrefix(ctx, logger, orgNames); err != nil {
+		return nil, err
+	}
+
 	// Get Enterprise details
-	enterprise, err := api.GetEnterprise(ctx, logger, enterpriseSlug)
+	enterprise, err := api.ResolveEnterprise(ctx, logger, enterpriseSlug)
 	if err != nil {
 		logger.Error("Failed to get enterprise details", slog.String("slug", enterpriseSlug), slog.Any("error", err))
-		return err
+		return nil, err
 	}
 
 	// Initialize delete report
@@ -467,6 +1171,41 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 		Facilitators:        facilitators,
 		InvalidUsers:        invalidUsers,
 		InvalidFacilitators: invalidFacilitators,
+		UserFilters:         filter.report(allUsersToDelete),
+		DiscoveredOrgs:      discoveredOrgs,
+	}
+	for _, user := range preservedUsers {
+		deleteReport.Organizations = append(deleteReport.Organizations, DeleteOrgReport{
+			User:    user,
+			OrgName: util.BuildOrgLogin(labDate, user),
+			Status:  "preserved",
+		})
+		deleteReport.PreservedCount++
+	}
+
+	// Organizations an interrupted earlier run deleted aren't attempted again
+	stateFile, _ := ctx.Value(config.DeleteStateFileKey).(string)
+	state, err := LoadDeleteState(stateFile)
+	if err != nil {
+		return nil, err
+	}
+	// A new slice: allUsersToDelete's array backs the report's UserFilters
+	remaining := make([]string, 0, len(allUsersToDelete))
+	for _, user := range allUsersToDelete {
+		orgName := util.BuildOrgLogin(labDate, user)
+		if state.Deleted(orgName) {
+			deleteReport.Organizations = append(deleteReport.Organizations, ResumedOrgReport(user, orgName))
+			deleteReport.ResumedCount++
+			continue
+		}
+		remaining = append(remaining, user)
+	}
+	allUsersToDelete = remaining
+	if deleteReport.ResumedCount > 0 {
+		logger.Info("Resuming deletion from state file",
+			slog.String("file", stateFile),
+			slog.Int("already_deleted", deleteReport.ResumedCount),
+			slog.Int("remaining", len(allUsersToDelete)))
 	}
 
 	userChan := make(chan string, len(allUsersToDelete))
@@ -475,11 +1214,7 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 	// Use WaitGroup to track worker goroutines
 	var wg sync.WaitGroup
 
-	// Calculate optimal number of workers: min(9, number of users)
-	numWorkers := 9
-	if len(allUsersToDelete) < numWorkers {
-		numWorkers = len(allUsersToDelete)
-	}
+	numWorkers := WorkerCount(ctx, len(allUsersToDelete))
 	logger.Info("Starting destroy workers", slog.Int("worker_count", numWorkers), slog.Int("total_user_count", len(allUsersToDelete)))
 
 	// Create worker goroutines
@@ -519,15 +1254,19 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 					slog.Int("failed", deleteReport.FailureCount),
 					slog.Duration("duration", time.Since(startTime)))
 
-				// Generate report
-				if err := GenerateDeleteReportFiles(deleteReport, "reports"); err != nil {
-					logger.Error("Failed to generate deletion report", slog.Any("error", err))
-				}
+				deleteReport.StartedAt = startTime
+				deleteReport.CompletedAt = time.Now()
+				deleteReport.DurationMs = deleteReport.CompletedAt.Sub(startTime).Milliseconds()
 
+				var runErr error
 				if deleteReport.FailureCount > 0 {
-					return fmt.Errorf("failed to delete %d organization(s)", deleteReport.FailureCount)
+					runErr = fmt.Errorf("failed to delete %d organization(s)", deleteReport.FailureCount)
 				}
-				return nil
+
+				// Generate report
+				reportOpts := ReportOptionsFromContext(ctx)
+				reportErr := GenerateDeleteReportFiles(deleteReport, reportOpts)
+				return deleteReport, ResolveRunError(logger, reportOpts, runErr, reportErr)
 			}
 
 			resultCount++
@@ -538,18 +1277,91 @@ func DestroyLabEnvironment(ctx context.Context, logger *slog.Logger, labDate str
 			} else {
 				deleteReport.FailureCount++
 			}
+			if err := state.Record(res); err != nil {
+				logger.Warn("Failed to update state file", slog.String("file", stateFile), slog.Any("error", err))
+			}
 
 		case <-ctx.Done():
 			logger.Error("Timeout reached while destroying lab environment")
 
 			// Generate report even on timeout
-			if err := GenerateDeleteReportFiles(deleteReport, "reports"); err != nil {
-				logger.Error("Failed to generate deletion report", slog.Any("error", err))
-			}
+			deleteReport.StartedAt = startTime
+			deleteReport.CompletedAt = time.Now()
+			deleteReport.DurationMs = deleteReport.CompletedAt.Sub(startTime).Milliseconds()
+			reportOpts := ReportOptionsFromContext(ctx)
+			reportErr := GenerateDeleteReportFiles(deleteReport, reportOpts)
+			return deleteReport, ResolveRunError(logger, reportOpts, ctx.Err(), reportErr)
+		}
+	}
+}
+
+// discoverLabOrgUsers lists the enterprise organizations named for labDate and returns
+// the users they were created for, leaving out users already in known (compared
+// case-insensitively)
+func discoverLabOrgUsers(ctx context.Context, logger *slog.Logger, enterpriseSlug string, labDate string, known map[string]bool) ([]string, error) {
+	organizations, err := api.GetEnterpriseOrganizations(ctx, logger, enterpriseSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover lab organizations: %w", err)
+	}
+
+	knownLower := make(map[string]bool, len(known))
+	for user := range known {
+		knownLower[strings.ToLower(user)] = true
+	}
+
+	prefix := strings.ToLower(util.BuildOrgLogin(labDate, ""))
+	var users []string
+	for _, org := range organizations {
+		login := strings.ToLower(org.Login)
+		if !strings.HasPrefix(login, prefix) || len(login) == len(prefix) {
+			continue
+		}
+		user := org.Login[len(prefix):]
+		if knownLower[strings.ToLower(user)] {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Discovered lab organizations not in the users file",
+		slog.String("lab_date", labDate),
+		slog.Int("count", len(users)))
+	return users, nil
+}
+
+// splitPreservedUsers separates the users named by --preserve-users, matched
+// case-insensitively, from the users whose organizations will be deleted. Preserve entries
+// that aren't part of the deletion are logged.
+func splitPreservedUsers(logger *slog.Logger, users []string, preserve []string) ([]string, []string) {
+	if len(preserve) == 0 {
+		return users, nil
+	}
+
+	preserveSet := make(map[string]bool, len(preserve))
+	for _, user := range preserve {
+		preserveSet[strings.ToLower(user)] = true
+	}
+
+	toDelete := make([]string, 0, len(users))
+	var preserved []string
+	for _, user := range users {
+		if preserveSet[strings.ToLower(user)] {
+			delete(preserveSet, strings.ToLower(user))
+			preserved = append(preserved, user)
+			continue
+		}
+		toDelete = append(toDelete, user)
+	}
 
-			return ctx.Err()
+	for _, user := range preserve {
+		if preserveSet[strings.ToLower(user)] {
+			logger.Warn("Preserved user is not part of this deletion", slog.String("user", user))
 		}
 	}
+	if len(preserved) > 0 {
+		logger.Info("Preserving organizations", slog.Any("users", preserved))
+	}
+	return toDelete, preserved
 }
 
 func DestroyOrgResourcesWithReport(workerId int, ctx context.Context, logger *slog.Logger, userChan chan string, resultsChan chan DeleteOrgReport, enterprise *api.Enterprise, labDate string) {
@@ -564,8 +1376,9 @@ func DestroyOrgResourcesWithReport(workerId int, ctx context.Context, logger *sl
 		default:
 		}
 
-		orgName := "ghas-labs-" + labDate + "-" + user
-		logger.Info("Deleting organization", slog.String("org", orgName), slog.String("user", user))
+		orgName := util.BuildOrgLogin(labDate, user)
+		orgLogger := orgRunLogger(logger, workerId, user)
+		orgLogger.Info("Deleting organization", slog.String("org", orgName), slog.String("user", user))
 
 		deleteTime := time.Now()
 		orgReport := DeleteOrgReport{
@@ -574,9 +1387,28 @@ func DestroyOrgResourcesWithReport(workerId int, ctx context.Context, logger *sl
 			DeletedAt: deleteTime,
 		}
 
+		// Repositories that fail to delete are reported, but the organization deletion is
+		// still attempted
+		if deleteReposFirst, _ := ctx.Value(config.DeleteReposFirstKey).(bool); deleteReposFirst {
+			repos, err := DeleteAllOrgRepos(ctx, orgLogger, orgName)
+			if err != nil {
+				orgLogger.Warn("Failed to delete all repositories before the organization",
+					slog.String("org", orgName),
+					slog.Any("error", err))
+				orgReport.RepositoriesError = err.Error()
+			}
+			orgReport.Repositories = repos
+		}
+
 		// Call the GraphQL-based DeleteOrg function
-		if err := api.DeleteOrg(ctx, logger, orgName); err != nil {
-			logger.Error("Failed to delete organization",
+		err := api.DeleteOrg(ctx, orgLogger, orgName)
+		if errors.Is(err, api.ErrOrganizationNotFound) {
+			orgLogger.Info("Organization already deleted", slog.String("org", orgName))
+			orgReport.AlreadyDeleted = true
+			err = nil
+		}
+		if err != nil {
+			orgLogger.Error("Failed to delete organization",
 				slog.String("user", user),
 				slog.String("org", orgName),
 				slog.Any("error", err))
@@ -589,7 +1421,7 @@ func DestroyOrgResourcesWithReport(workerId int, ctx context.Context, logger *sl
 
 		orgReport.Status = "success"
 		resultsChan <- orgReport
-		logger.Info("Finished deleting organization", slog.String("org", orgName))
+		orgLogger.Info("Finished deleting organization", slog.String("org", orgName))
 	}
 
 	logger.Info("Destroy worker stopped", slog.Int("workerId", workerId))
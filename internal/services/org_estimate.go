@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// largeRunOrgThreshold is the number of new organizations above which a run without
+// --org-create-interval is warned about GHEC's org creation abuse limits
+const largeRunOrgThreshold = 100
+
+// OrgEstimate is the preflight estimate of the organizations a run will create, checked
+// against the enterprise's current organization count and known limits. It is informational.
+type OrgEstimate struct {
+	PlannedOrgs int `json:"planned_orgs"`
+	// ExistingOrgs is the number of planned organizations that already exist
+	ExistingOrgs int `json:"existing_orgs"`
+	// NewOrgs is the number of organizations the run will create
+	NewOrgs        int `json:"new_orgs"`
+	EnterpriseOrgs int `json:"enterprise_orgs"`
+	// OrgLimit is --enterprise-org-limit, 0 when unknown
+	OrgLimit int `json:"org_limit,omitempty"`
+	// MinDuration is the least time --org-create-interval allows the creations to take
+	MinDuration string   `json:"min_duration,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// isPreflightEnabled reports whether the estimate runs before provisioning; --no-preflight
+// turns it off
+func isPreflightEnabled(ctx context.Context) bool {
+	noPreflight, _ := ctx.Value(config.NoPreflightKey).(bool)
+	return !noPreflight
+}
+
+// estimateOrgCreation counts the planned organizations that don't exist yet and warns when
+// creating them could exceed --enterprise-org-limit or GHEC's creation abuse limits
+func estimateOrgCreation(ctx context.Context, logger *slog.Logger, enterpriseSlug string, orgLogins []string) (*OrgEstimate, error) {
+	organizations, err := api.GetEnterpriseOrganizations(ctx, logger, enterpriseSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enterprise organizations: %w", err)
+	}
+
+	existing := make(map[string]bool, len(organizations))
+	for _, org := range organizations {
+		existing[strings.ToLower(org.Login)] = true
+	}
+
+	estimate := &OrgEstimate{
+		PlannedOrgs:    len(orgLogins),
+		EnterpriseOrgs: len(organizations),
+	}
+	for _, login := range orgLogins {
+		if existing[strings.ToLower(login)] {
+			estimate.ExistingOrgs++
+		}
+	}
+	estimate.NewOrgs = estimate.PlannedOrgs - estimate.ExistingOrgs
+
+	// Existing orgs make lab create fail for that user rather than count twice
+	if !isApplyMode(ctx) && estimate.ExistingOrgs > 0 {
+		estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("%d planned organization(s) already exist; lab create will fail for them (use lab apply to reuse them)", estimate.ExistingOrgs))
+	}
+
+	estimate.OrgLimit, _ = ctx.Value(config.EnterpriseOrgLimitKey).(int)
+	if estimate.OrgLimit > 0 {
+		total := estimate.EnterpriseOrgs + estimate.NewOrgs
+		switch {
+		case total > estimate.OrgLimit:
+			estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("the enterprise would have %d organizations, over the limit of %d; %d of the new organizations may fail", total, estimate.OrgLimit, total-estimate.OrgLimit))
+		case total*10 >= estimate.OrgLimit*9:
+			estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("the enterprise would have %d organizations, within 10%% of the limit of %d", total, estimate.OrgLimit))
+		}
+	}
+
+	interval, _ := ctx.Value(config.OrgCreateIntervalKey).(time.Duration)
+	if interval > 0 && estimate.NewOrgs > 1 {
+		estimate.MinDuration = (interval * time.Duration(estimate.NewOrgs-1)).String()
+	}
+	if interval == 0 && estimate.NewOrgs > largeRunOrgThreshold {
+		estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("creating %d organizations without --org-create-interval may trip GitHub's org creation abuse limits; consider --org-create-interval 2s", estimate.NewOrgs))
+	}
+
+	return estimate, nil
+}
+
+// logOrgEstimate logs the estimate and each of its warnings
+func logOrgEstimate(logger *slog.Logger, estimate *OrgEstimate) {
+	logger.Info("Organization creation estimate",
+		slog.Int("planned_orgs", estimate.PlannedOrgs),
+		slog.Int("existing_orgs", estimate.ExistingOrgs),
+		slog.Int("new_orgs", estimate.NewOrgs),
+		slog.Int("enterprise_orgs", estimate.EnterpriseOrgs),
+		slog.Int("org_limit", estimate.OrgLimit),
+		slog.String("min_duration", estimate.MinDuration))
+	for _, warning := range estimate.Warnings {
+		logger.Warn("Organization creation estimate warning", slog.String("warning", warning))
+	}
+}
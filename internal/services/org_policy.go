@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// Org policy outcomes recorded in OrgPolicyResult
+const (
+	OrgPolicyApplied     = "applied"
+	OrgPolicyUnsupported = "unsupported"
+	OrgPolicyFailed      = "failed"
+)
+
+// OrgPolicyResult is the outcome of applying one --org-policy setting to an organization.
+// Neither an unsupported nor a failed setting fails the organization.
+type OrgPolicyResult struct {
+	Setting string `json:"setting"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// newOrgPolicyResult converts the error from applying a setting into a result
+func newOrgPolicyResult(setting string, err error) OrgPolicyResult {
+	switch {
+	case err == nil:
+		return OrgPolicyResult{Setting: setting, Status: OrgPolicyApplied}
+	case errors.Is(err, api.ErrOrgPolicyUnsupported):
+		return OrgPolicyResult{Setting: setting, Status: OrgPolicyUnsupported, Error: err.Error()}
+	default:
+		return OrgPolicyResult{Setting: setting, Status: OrgPolicyFailed, Error: err.Error()}
+	}
+}
+
+// applyOrgPolicy applies each setting of the policy to the user's organization
+// independently and returns one result per setting. Entries already on the IP allow list
+// count as applied.
+func applyOrgPolicy(ctx context.Context, logger *slog.Logger, orgName string, user string, policy *util.OrgPolicy) []OrgPolicyResult {
+	var results []OrgPolicyResult
+
+	for _, name := range policy.SettingNames() {
+		err := api.UpdateOrgSetting(ctx, logger, orgName, name, policy.Settings[name])
+		results = append(results, newOrgPolicyResult(name, err))
+	}
+
+	if seats := policy.Seats; seats != nil {
+		if seats.BillingEmail != "" {
+			err := api.UpdateOrgSetting(ctx, logger, orgName, "billing_email", seats.BillingEmail)
+			results = append(results, newOrgPolicyResult("seats billing_email", err))
+		}
+		if seats.CopilotSeat {
+			err := api.AssignCopilotSeat(ctx, logger, orgName, user)
+			results = append(results, newOrgPolicyResult("seats copilot_seat "+user, err))
+		}
+	}
+
+	if policy.IPAllowList == nil {
+		return results
+	}
+
+	allowList, err := api.GetOrgIPAllowList(ctx, logger, orgName)
+	if err != nil {
+		for _, entry := range policy.IPAllowList.Entries {
+			results = append(results, newOrgPolicyResult("ip_allow_list "+entry.String(), err))
+		}
+		if policy.IPAllowList.Enabled {
+			results = append(results, newOrgPolicyResult("ip_allow_list enabled", err))
+		}
+		return results
+	}
+
+	missing := 0
+	for _, entry := range policy.IPAllowList.Entries {
+		var err error
+		if !slices.Contains(allowList.Values, entry.Value) {
+			err = api.AddIPAllowListEntry(ctx, logger, orgName, allowList.OwnerID, entry.Value, entry.Name)
+		}
+		if err != nil {
+			missing++
+		}
+		results = append(results, newOrgPolicyResult("ip_allow_list "+entry.String(), err))
+	}
+
+	if policy.IPAllowList.Enabled {
+		var err error
+		switch {
+		case missing > 0:
+			// Enabling an incomplete list could lock out addresses the policy meant to allow
+			err = fmt.Errorf("not enabled because %d of the policy's entries weren't added", missing)
+		case !allowList.Enabled:
+			err = api.EnableIPAllowList(ctx, logger, orgName, allowList.OwnerID)
+		}
+		results = append(results, newOrgPolicyResult("ip_allow_list enabled", err))
+	}
+
+	return results
+}
+
+// writeOrgPolicyMarkdown summarizes --org-policy across organizations, listing every
+// setting that wasn't applied
+func writeOrgPolicyMarkdown(w io.Writer, organizations []OrgReport, heading string) {
+	type problem struct {
+		org    string
+		result OrgPolicyResult
+	}
+	counts := map[string]int{}
+	var problems []problem
+	recorded := false
+
+	for _, org := range organizations {
+		for _, result := range org.OrgPolicy {
+			recorded = true
+			counts[result.Status]++
+			if result.Status != OrgPolicyApplied {
+				problems = append(problems, problem{org: org.OrgName, result: result})
+			}
+		}
+	}
+	if !recorded {
+		return
+	}
+
+	fmt.Fprintf(w, "%s 🛡️ Organization Policy\n\n", heading)
+	fmt.Fprintf(w, "- **Settings:** %d applied, %d unsupported, %d failed\n\n",
+		counts[OrgPolicyApplied], counts[OrgPolicyUnsupported], counts[OrgPolicyFailed])
+
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "| Organization | Setting | Status | Detail |\n")
+		fmt.Fprintf(w, "|--------------|---------|--------|--------|\n")
+		for _, p := range problems {
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", p.org, p.result.Setting, p.result.Status, markdownTableCell(p.result.Error))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// orgRetryBaseDelay is the wait before the first --org-retries retry; it doubles after
+// each further attempt
+const orgRetryBaseDelay = 5 * time.Second
+
+// orgQueue hands users to the provisioning workers. A user whose organization creation
+// failed is put back on the queue after a backoff, up to --org-retries times, so the wait
+// doesn't hold one of the --max-workers. The queue closes once every user has a final result.
+type orgQueue struct {
+	users   chan string
+	retries int
+
+	mu       sync.Mutex
+	attempts map[string]int
+	preHooks map[string]*HookResult
+	pending  int
+}
+
+// newOrgQueue returns a queue holding users, with --org-retries read from the context
+func newOrgQueue(ctx context.Context, users []string) *orgQueue {
+	retries, _ := ctx.Value(config.OrgRetriesKey).(int)
+	q := &orgQueue{
+		// A user is on the queue at most once at a time, so sends never block
+		users:    make(chan string, len(users)),
+		retries:  retries,
+		attempts: make(map[string]int, len(users)),
+		preHooks: make(map[string]*HookResult, len(users)),
+		pending:  len(users),
+	}
+	for _, user := range users {
+		q.users <- user
+	}
+	if q.pending == 0 {
+		close(q.users)
+	}
+	return q
+}
+
+// next returns the next user to provision, or false once every user is finished or the
+// context is done
+func (q *orgQueue) next(ctx context.Context) (string, bool) {
+	select {
+	case user, ok := <-q.users:
+		return user, ok
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+// startAttempt counts a pass over the user's organization and returns its number, from 1
+func (q *orgQueue) startAttempt(user string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.attempts[user]++
+	return q.attempts[user]
+}
+
+// runPreHook runs --pre-hook on the user's first pass and returns that result on later ones,
+// so a retry doesn't repeat it
+func (q *orgQueue) runPreHook(ctx context.Context, logger *slog.Logger, hooks *provisionHooks, user string) *HookResult {
+	q.mu.Lock()
+	preHook, ran := q.preHooks[user]
+	q.mu.Unlock()
+	if ran {
+		return preHook
+	}
+
+	preHook = hooks.runPreHook(ctx, logger, user)
+	q.mu.Lock()
+	q.preHooks[user] = preHook
+	q.mu.Unlock()
+	return preHook
+}
+
+// retry puts the user back on the queue after a backoff when their organization creation
+// failed with err on the given attempt and retries are left. GraphQL errors (login taken,
+// missing permission) and an org name that can't be built won't change on retry and are
+// final. Reports whether the user was re-queued.
+func (q *orgQueue) retry(ctx context.Context, logger *slog.Logger, user string, attempt int, err error) bool {
+	var graphQLErr *api.GraphQLResponseError
+	var nameErr *api.InvalidOrgNameError
+	if attempt > q.retries || errors.As(err, &graphQLErr) || errors.As(err, &nameErr) || ctx.Err() != nil {
+		return false
+	}
+
+	delay := orgRetryBaseDelay << (attempt - 1)
+	logger.Warn("Failed to create organization, re-queueing after delay",
+		slog.String("user", user),
+		slog.Int("attempt", attempt),
+		slog.Int("max_attempts", q.retries+1),
+		slog.Duration("delay", delay),
+		slog.Any("error", err))
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Workers stop on cancellation, so there's no one left to take the user
+		case <-time.After(delay):
+			q.users <- user
+		}
+	}()
+	return true
+}
+
+// finish records that a user has its final result, closing the queue after the last one
+func (q *orgQueue) finish() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending--
+	if q.pending == 0 {
+		close(q.users)
+	}
+}
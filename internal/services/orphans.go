@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// FindOrphanedOrgs returns the enterprise organizations whose login starts with prefix
+// but that don't appear in any report in reportsDir. These are usually left over from
+// runs that crashed before writing their report and are candidates for cleanup.
+func FindOrphanedOrgs(ctx context.Context, logger *slog.Logger, enterpriseSlug string, reportsDir string, prefix string) ([]api.Organization, error) {
+	known, err := orgsInReports(logger, reportsDir, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations, err := api.GetEnterpriseOrganizations(ctx, logger, enterpriseSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []api.Organization{}
+	for _, org := range organizations {
+		login := strings.ToLower(org.Login)
+		if !strings.HasPrefix(login, strings.ToLower(prefix)) || known[login] {
+			continue
+		}
+		orphans = append(orphans, org)
+	}
+
+	logger.Info("Orphaned organization search complete",
+		slog.Int("enterprise_org_count", len(organizations)),
+		slog.Int("reported_org_count", len(known)),
+		slog.Int("orphan_count", len(orphans)))
+
+	return orphans, nil
+}
+
+// orgsInReports collects the lowercased org logins starting with prefix that are
+// mentioned in the Markdown reports in dir
+func orgsInReports(logger *slog.Logger, dir string, prefix string) (map[string]bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no reports found in %s; pass the directory lab runs wrote their reports to with --reports-dir", dir)
+	}
+
+	loginPattern := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(prefix) + `[a-z0-9-]*`)
+	known := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+		}
+		for _, login := range loginPattern.FindAllString(string(data), -1) {
+			known[strings.ToLower(login)] = true
+		}
+	}
+
+	logger.Info("Loaded organizations from reports",
+		slog.String("dir", dir),
+		slog.Int("report_count", len(paths)),
+		slog.Int("org_count", len(known)))
+
+	return known, nil
+}
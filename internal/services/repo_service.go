@@ -2,15 +2,23 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/s-samadi/ghas-lab-builder/internal/auth"
 	"github.com/s-samadi/ghas-lab-builder/internal/config"
 	api "github.com/s-samadi/ghas-lab-builder/internal/github"
 	util "github.com/s-samadi/ghas-lab-builder/internal/util"
 )
 
-// CreateReposInLabOrg creates repositories from templates in an existing lab organization
+// CreateReposInLabOrg creates repositories from templates in an existing organization.
+// The organization does not need to follow the lab naming scheme; any org the
+// token or GitHub App can access may be targeted.
 func CreateReposInLabOrg(ctx context.Context, logger *slog.Logger, templateReposFile string) error {
 	logger.Info("Starting repository creation in lab organization")
 
@@ -33,6 +41,13 @@ func CreateReposInLabOrg(ctx context.Context, logger *slog.Logger, templateRepos
 		slog.Int("count", len(templateRepos)),
 		slog.String("org", orgName))
 
+	// Fail fast if the GitHub App can't act on this org
+	if ctx.Value(config.TokenKey) == nil {
+		if err := api.CheckAppInstalledOnOrg(ctx, logger, orgName); err != nil {
+			return err
+		}
+	}
+
 	// Get the organization
 	organization, err := api.GetOrganization(ctx, logger, orgName)
 	if err != nil {
@@ -46,25 +61,43 @@ func CreateReposInLabOrg(ctx context.Context, logger *slog.Logger, templateRepos
 
 	// Create repositories from templates
 	successCount := 0
+	var created []util.CreatedRepo
 	for _, repoConfig := range templateRepos {
-		logger.Info("Creating repository from template",
-			slog.String("template", repoConfig.Template),
+		// Outside a lab there is no user or lab date, so only {{.Org}} has a value
+		repoConfig, err := repoConfig.Expand(util.RepoTemplateVars{Org: orgName})
+		if err != nil {
+			logger.Error("Failed to expand repository config",
+				slog.String("repo", repoConfig.SourceRef()),
+				slog.Any("error", err))
+			continue
+		}
+
+		logger.Info("Creating repository",
+			slog.String("source", repoSource(ctx, repoConfig)),
+			slog.String("repo", repoConfig.SourceRef()),
 			slog.Bool("include_all_branches", repoConfig.IncludeAllBranches),
 			slog.String("org", orgName))
 
-		_, err := organization.CreateRepoFromTemplate(ctx, logger, repoConfig.Template, repoConfig.IncludeAllBranches)
+		createdRepo, err := createConfiguredRepo(ctx, logger, organization, repoConfig)
 		if err != nil {
 			logger.Error("Failed to create repository",
-				slog.String("repo", repoConfig.Template),
+				slog.String("repo", repoConfig.SourceRef()),
 				slog.String("org", orgName),
 				slog.Any("error", err))
+			// Every remaining repo would fail the same way, so stop early
+			if errors.Is(err, auth.ErrNoInstallationForOrg) {
+				return fmt.Errorf("GitHub App is not installed on org %s: %w", orgName, err)
+			}
 			// Continue with other repos even if one fails
 			continue
 		}
 
+		configureCreatedRepo(ctx, logger, organization, createdRepo, repoConfig)
+		created = append(created, util.CreatedRepo{Org: orgName, Repo: createdRepo.Name, Template: repoConfig.SourceRef()})
+
 		successCount++
 		logger.Info("Successfully created repository",
-			slog.String("template", repoConfig.Template),
+			slog.String("repo", repoConfig.SourceRef()),
 			slog.String("org", orgName))
 	}
 
@@ -73,6 +106,10 @@ func CreateReposInLabOrg(ctx context.Context, logger *slog.Logger, templateRepos
 		slog.Int("total_repos", len(templateRepos)),
 		slog.String("org", orgName))
 
+	if err := recordCreatedRepos(ctx, logger, created); err != nil {
+		return err
+	}
+
 	if successCount == 0 && len(templateRepos) > 0 {
 		return fmt.Errorf("failed to create any repositories")
 	}
@@ -80,15 +117,38 @@ func CreateReposInLabOrg(ctx context.Context, logger *slog.Logger, templateRepos
 	return nil
 }
 
+// Repository deletion outcomes recorded in RepoDeleteResult
+const (
+	RepoDeleteStatusDeleted  = "deleted"
+	RepoDeleteStatusNotFound = "not_found"
+	RepoDeleteStatusFailed   = "failed"
+)
+
+// RepoDeleteResult is the outcome of deleting one repository
+type RepoDeleteResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
 // DeleteReposInLabOrg deletes repositories in a lab organization
-// If repoNames is nil or empty, all repositories in the organization will be deleted
-func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []string) error {
+// If repoNames is nil or empty, all repositories in the organization will be deleted.
+// Repositories that are already gone are reported as not_found rather than failed, so
+// re-running a deletion is safe.
+func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []string) ([]RepoDeleteResult, error) {
 	logger.Info("Starting repository deletion in lab organization")
 
 	// Get organization name from context
 	orgName, ok := ctx.Value(config.OrgKey).(string)
 	if !ok || orgName == "" {
-		return fmt.Errorf("organization name not found in context")
+		return nil, fmt.Errorf("organization name not found in context")
+	}
+
+	// Fail fast if the GitHub App can't act on this org
+	if ctx.Value(config.TokenKey) == nil {
+		if err := api.CheckAppInstalledOnOrg(ctx, logger, orgName); err != nil {
+			return nil, err
+		}
 	}
 
 	// Get the organization
@@ -97,7 +157,7 @@ func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []s
 		logger.Error("Failed to get organization",
 			slog.String("org", orgName),
 			slog.Any("error", err))
-		return fmt.Errorf("failed to get organization %s: %w", orgName, err)
+		return nil, fmt.Errorf("failed to get organization %s: %w", orgName, err)
 	}
 
 	logger.Info("Found organization", slog.String("org", organization.Login))
@@ -110,7 +170,7 @@ func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []s
 			logger.Error("Failed to list repositories",
 				slog.String("org", orgName),
 				slog.Any("error", err))
-			return fmt.Errorf("failed to list repositories: %w", err)
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
 		}
 		logger.Info("Found repositories to delete",
 			slog.Int("count", len(repoNames)),
@@ -119,7 +179,7 @@ func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []s
 
 	if len(repoNames) == 0 {
 		logger.Info("No repositories to delete", slog.String("org", orgName))
-		return nil
+		return nil, nil
 	}
 
 	logger.Info("Deleting repositories",
@@ -127,6 +187,7 @@ func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []s
 		slog.String("org", orgName))
 
 	// Delete repositories
+	results := make([]RepoDeleteResult, 0, len(repoNames))
 	successCount := 0
 	for _, repoName := range repoNames {
 		logger.Info("Deleting repository",
@@ -134,15 +195,22 @@ func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []s
 			slog.String("org", orgName))
 
 		err := organization.DeleteRepository(ctx, logger, repoName)
+		if errors.Is(err, api.ErrRepositoryNotFound) {
+			results = append(results, RepoDeleteResult{Name: repoName, Status: RepoDeleteStatusNotFound})
+			successCount++
+			continue
+		}
 		if err != nil {
 			logger.Error("Failed to delete repository",
 				slog.String("repo", repoName),
 				slog.String("org", orgName),
 				slog.Any("error", err))
+			results = append(results, RepoDeleteResult{Name: repoName, Status: RepoDeleteStatusFailed, Error: err.Error()})
 			// Continue with other repos even if one fails
 			continue
 		}
 
+		results = append(results, RepoDeleteResult{Name: repoName, Status: RepoDeleteStatusDeleted})
 		successCount++
 		logger.Info("Successfully deleted repository",
 			slog.String("repo", repoName),
@@ -155,8 +223,396 @@ func DeleteReposInLabOrg(ctx context.Context, logger *slog.Logger, repoNames []s
 		slog.String("org", orgName))
 
 	if successCount == 0 && len(repoNames) > 0 {
-		return fmt.Errorf("failed to delete any repositories")
+		return results, fmt.Errorf("failed to delete any repositories")
 	}
 
-	return nil
+	return results, nil
+}
+
+// Repository transfer outcomes returned by TransferRepoFromLabOrg
+const (
+	TransferStatusCompleted = "completed"
+	TransferStatusPending   = "pending"
+)
+
+// TransferRepoFromLabOrg transfers a repository from the organization in the context to
+// newOwner. GitHub completes transfers asynchronously, so the destination is polled briefly.
+// If the repository hasn't appeared by then the transfer is reported as pending: it is still
+// processing, or the destination has to accept it.
+func TransferRepoFromLabOrg(ctx context.Context, logger *slog.Logger, repoName string, newOwner string) (string, error) {
+	// Get organization name from context
+	orgName, ok := ctx.Value(config.OrgKey).(string)
+	if !ok || orgName == "" {
+		return "", fmt.Errorf("organization name not found in context")
+	}
+
+	// Fail fast if the GitHub App can't act on this org
+	if ctx.Value(config.TokenKey) == nil {
+		if err := api.CheckAppInstalledOnOrg(ctx, logger, orgName); err != nil {
+			return "", err
+		}
+	}
+
+	organization, err := api.GetOrganization(ctx, logger, orgName)
+	if err != nil {
+		logger.Error("Failed to get organization",
+			slog.String("org", orgName),
+			slog.Any("error", err))
+		return "", fmt.Errorf("failed to get organization %s: %w", orgName, err)
+	}
+
+	if err := organization.TransferRepository(ctx, logger, repoName, newOwner); err != nil {
+		return "", fmt.Errorf("failed to transfer %s/%s to %s: %w", orgName, repoName, newOwner, err)
+	}
+
+	const maxAttempts = 6
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		repo, err := api.GetRepository(ctx, logger, newOwner, repoName)
+		if err == nil {
+			logger.Info("Repository transfer completed",
+				slog.String("repo", repo.FullName),
+				slog.String("url", repo.HTMLURL))
+			return TransferStatusCompleted, nil
+		}
+		if !errors.Is(err, api.ErrRepositoryNotFound) {
+			// Credentials may not be able to read the destination; the transfer itself was accepted
+			logger.Warn("Unable to confirm repository transfer",
+				slog.String("repo", repoName),
+				slog.String("new_owner", newOwner),
+				slog.Any("error", err))
+			break
+		}
+		if attempt < maxAttempts {
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				return TransferStatusPending, nil
+			}
+		}
+	}
+
+	logger.Warn("Repository transfer accepted but not yet visible in destination; it may still be processing or need to be accepted by the destination",
+		slog.String("repo", repoName),
+		slog.String("new_owner", newOwner))
+	return TransferStatusPending, nil
+}
+
+// templateRepoOptions converts a template repo config into API creation options
+func templateRepoOptions(ctx context.Context, repoConfig util.RepoConfig) api.TemplateRepoOptions {
+	noDescription, _ := ctx.Value(config.NoDescriptionKey).(bool)
+	waitReady, _ := ctx.Value(config.WaitRepoReadyKey).(bool)
+	labDate, _ := ctx.Value(config.LabDateKey).(string)
+	return api.TemplateRepoOptions{
+		IncludeAllBranches: repoConfig.IncludeAllBranches,
+		Private:            repoConfig.IsPrivate(),
+		Name:               repoConfig.Name,
+		Description:        repoConfig.Description,
+		NoDescription:      noDescription,
+		WaitReady:          waitReady,
+		Marker:             util.LabRepoMarker(labDate),
+	}
+}
+
+// createConfiguredRepo creates the repository described by the repo config, generating it
+// from its template or importing it from its git URL. Templates on a --template-base-url
+// host are imported from that host.
+func createConfiguredRepo(ctx context.Context, logger *slog.Logger, organization *api.Organization, repoConfig util.RepoConfig) (*api.Repository, error) {
+	if repoConfig.Source() == util.RepoSourceImport {
+		return importRepo(ctx, logger, organization, repoConfig)
+	}
+	if imported, ok := crossHostImportConfig(ctx, repoConfig); ok {
+		return importRepo(ctx, logger, organization, imported)
+	}
+	return organization.CreateRepoFromTemplate(ctx, logger, repoConfig.Template, templateRepoOptions(ctx, repoConfig))
+}
+
+// importRepo creates an empty repository and imports the configured git repository into
+// it. If the import fails the empty repository is deleted again, so a later run or lab
+// apply retries the import instead of finding an empty repository already present.
+func importRepo(ctx context.Context, logger *slog.Logger, organization *api.Organization, repoConfig util.RepoConfig) (*api.Repository, error) {
+	opts := templateRepoOptions(ctx, repoConfig)
+	description := opts.Description
+	if description == "" && !opts.NoDescription {
+		description = fmt.Sprintf("Repository imported from %s", repoConfig.Import)
+	}
+	if description != "" && opts.Marker != "" {
+		description += " " + opts.Marker
+	}
+
+	repo, err := organization.CreateRepository(ctx, logger, repoConfig.RepoName(), description, opts.Private)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := organization.ImportRepository(ctx, logger, repo.Name, repoConfig.Import); err != nil {
+		if deleteErr := organization.DeleteRepository(ctx, logger, repo.Name); deleteErr != nil {
+			logger.Warn("Failed to delete repository after failed import",
+				slog.String("repo", repo.FullName),
+				slog.Any("error", deleteErr))
+		}
+		return nil, err
+	}
+
+	// The default branch is only known once the import has pushed it
+	imported, err := api.GetRepository(ctx, logger, organization.Login, repo.Name)
+	if err != nil {
+		logger.Warn("Repository imported but its details could not be refreshed",
+			slog.String("repo", repo.FullName),
+			slog.Any("error", err))
+		return repo, nil
+	}
+	return imported, nil
+}
+
+// FindLabRepos returns the repositories in the organization whose description carries the
+// lab marker for labDate, or any lab marker when labDate is empty. Unlike the org naming
+// convention, this also identifies lab repositories in organizations with custom names.
+func FindLabRepos(ctx context.Context, logger *slog.Logger, orgName string, labDate string) ([]api.Repository, error) {
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+	organization, err := api.GetOrganization(ctx, logger, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization %s: %w", orgName, err)
+	}
+	repos, err := organization.ListRepositoryDetails(ctx, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories in %s: %w", orgName, err)
+	}
+
+	var labRepos []api.Repository
+	for _, repo := range repos {
+		if util.HasLabRepoMarker(repo.Description, labDate) {
+			labRepos = append(labRepos, repo)
+		}
+	}
+	logger.Info("Found lab repositories by description marker",
+		slog.String("org", orgName),
+		slog.String("lab_date", labDate),
+		slog.Int("count", len(labRepos)))
+	return labRepos, nil
+}
+
+// configureCreatedRepo applies the post-creation settings from the repo config. Failures
+// don't fail the repository; they are logged and returned as warnings for the report.
+// Returns the resulting default branch name.
+func configureCreatedRepo(ctx context.Context, logger *slog.Logger, organization *api.Organization, repo *api.Repository, repoConfig util.RepoConfig) (string, []string, []string) {
+	var warnings []string
+
+	defaultBranch, err := ensureDefaultBranch(ctx, logger, organization, repo, repoConfig.DefaultBranch)
+	if err != nil {
+		logger.Warn("Repository created but default branch was not renamed",
+			slog.String("repo", repo.FullName),
+			slog.String("default_branch", repoConfig.DefaultBranch),
+			slog.Any("error", err))
+		warnings = append(warnings, fmt.Sprintf("default branch rename failed: %v", err))
+	}
+
+	if len(repoConfig.Topics) > 0 {
+		if err := organization.ReplaceTopics(ctx, logger, repo.Name, repoConfig.Topics); err != nil {
+			logger.Warn("Repository created but topics were not set",
+				slog.String("repo", repo.FullName),
+				slog.Any("topics", repoConfig.Topics),
+				slog.Any("error", err))
+			warnings = append(warnings, fmt.Sprintf("setting topics failed: %v", err))
+		}
+	}
+
+	var settings []string
+	if repoConfig.Settings != nil {
+		var err error
+		settings, err = applyRepoSettings(ctx, logger, organization, repo, *repoConfig.Settings)
+		if err != nil {
+			logger.Warn("Repository created but settings were not updated",
+				slog.String("repo", repo.FullName),
+				slog.Any("error", err))
+			warnings = append(warnings, fmt.Sprintf("updating settings failed: %v", err))
+		}
+	}
+
+	return defaultBranch, settings, warnings
+}
+
+// applyRepoSettings applies the repo config's settings block and returns the applied
+// settings as name=value pairs for the report. A settings description gets the lab marker
+// like any other description, so the repository can still be found by FindLabRepos.
+func applyRepoSettings(ctx context.Context, logger *slog.Logger, organization *api.Organization, repo *api.Repository, settings util.RepoSettings) ([]string, error) {
+	if settings.Description != nil && *settings.Description != "" {
+		labDate, _ := ctx.Value(config.LabDateKey).(string)
+		description := *settings.Description + " " + util.LabRepoMarker(labDate)
+		settings.Description = &description
+	}
+
+	if err := organization.UpdateRepoSettings(ctx, logger, repo.Name, settings); err != nil {
+		return nil, err
+	}
+
+	payload := settings.Payload()
+	applied := make([]string, 0, len(payload))
+	for name, value := range payload {
+		applied = append(applied, fmt.Sprintf("%s=%v", name, value))
+	}
+	sort.Strings(applied)
+	return applied, nil
+}
+
+// ensureDefaultBranch renames the repository's default branch to the desired name. It is a
+// no-op when no name is configured or the branch already matches. Repositories generated from
+// templates are populated asynchronously, so the rename is retried briefly if the branch isn't
+// there yet. Returns the resulting default branch name.
+func ensureDefaultBranch(ctx context.Context, logger *slog.Logger, organization *api.Organization, repo *api.Repository, desired string) (string, error) {
+	if desired == "" || repo.DefaultBranch == desired {
+		return repo.DefaultBranch, nil
+	}
+
+	const maxAttempts = 3
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = organization.RenameBranch(ctx, logger, repo.Name, repo.DefaultBranch, desired)
+		if err == nil {
+			return desired, nil
+		}
+		if attempt < maxAttempts {
+			logger.Warn("Default branch rename failed, retrying",
+				slog.String("repo", repo.FullName),
+				slog.Int("attempt", attempt),
+				slog.Any("error", err))
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				return repo.DefaultBranch, ctx.Err()
+			}
+		}
+	}
+	return repo.DefaultBranch, err
+}
+
+// DeleteAllOrgRepos deletes every repository in orgName ahead of deleting the organization
+// itself, for GHES configurations where some repositories block organization deletion.
+// Up to --repo-delete-concurrency repositories are deleted at a time; results follow the
+// listing order. A missing organization returns no results, leaving it to the
+// organization deletion to report.
+func DeleteAllOrgRepos(ctx context.Context, logger *slog.Logger, orgName string) ([]RepoDeleteResult, error) {
+	ctx = context.WithValue(ctx, config.OrgKey, orgName)
+
+	// Fail fast if the GitHub App can't act on this org
+	if ctx.Value(config.TokenKey) == nil {
+		if err := api.CheckAppInstalledOnOrg(ctx, logger, orgName); err != nil {
+			return nil, err
+		}
+	}
+
+	organization, err := api.GetOrganization(ctx, logger, orgName)
+	if errors.Is(err, api.ErrOrganizationNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization %s: %w", orgName, err)
+	}
+
+	repoNames, err := organization.ListRepositories(ctx, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	if len(repoNames) == 0 {
+		logger.Info("No repositories to delete before the organization", slog.String("org", orgName))
+		return nil, nil
+	}
+
+	concurrency, ok := ctx.Value(config.RepoDeleteConcurrencyKey).(int)
+	if !ok || concurrency < 1 {
+		concurrency = config.DefaultRepoDeleteConcurrency
+	}
+	logger.Info("Deleting repositories before the organization",
+		slog.String("org", orgName),
+		slog.Int("count", len(repoNames)),
+		slog.Int("concurrency", concurrency))
+
+	semaphore := make(chan struct{}, concurrency)
+	results := make([]RepoDeleteResult, len(repoNames))
+	var wg sync.WaitGroup
+	for i, repoName := range repoNames {
+		wg.Add(1)
+		go func(index int, repoName string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			err := organization.DeleteRepository(ctx, logger, repoName)
+			switch {
+			case errors.Is(err, api.ErrRepositoryNotFound):
+				results[index] = RepoDeleteResult{Name: repoName, Status: RepoDeleteStatusNotFound}
+			case err != nil:
+				results[index] = RepoDeleteResult{Name: repoName, Status: RepoDeleteStatusFailed, Error: err.Error()}
+			default:
+				results[index] = RepoDeleteResult{Name: repoName, Status: RepoDeleteStatusDeleted}
+			}
+		}(i, repoName)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == RepoDeleteStatusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("failed to delete %d of %d repositories", failed, len(results))
+	}
+	return results, nil
+}
+
+// OrgRepoDeleteResult is the outcome of deleting the listed repositories of one organization
+type OrgRepoDeleteResult struct {
+	Org     string
+	Results []RepoDeleteResult
+	Error   string
+}
+
+// DeleteCreatedRepos deletes exactly the repositories listed in a --repos-output file and
+// leaves their organizations in place. Entries for organizations that don't belong to
+// labDate are skipped, so a file from another lab can't delete its repositories.
+func DeleteCreatedRepos(ctx context.Context, logger *slog.Logger, labDate string, repos []util.CreatedRepo) ([]OrgRepoDeleteResult, error) {
+	orgPrefix := util.BuildOrgLogin(labDate, "")
+
+	var orgs []string
+	repoNames := make(map[string][]string)
+	for _, repo := range repos {
+		if !strings.HasPrefix(repo.Org, orgPrefix) {
+			logger.Warn("Skipping repository outside the lab date",
+				slog.String("org", repo.Org),
+				slog.String("repo", repo.Repo),
+				slog.String("lab_date", labDate))
+			continue
+		}
+		if _, ok := repoNames[repo.Org]; !ok {
+			orgs = append(orgs, repo.Org)
+		}
+		repoNames[repo.Org] = append(repoNames[repo.Org], repo.Repo)
+	}
+	if len(orgs) == 0 {
+		return nil, fmt.Errorf("no repositories for lab date %s in the created repos file", labDate)
+	}
+
+	logger.Info("Deleting created repositories",
+		slog.Int("org_count", len(orgs)),
+		slog.String("lab_date", labDate))
+
+	results := make([]OrgRepoDeleteResult, 0, len(orgs))
+	failedOrgs := 0
+	for _, orgName := range orgs {
+		orgCtx := context.WithValue(ctx, config.OrgKey, orgName)
+		orgResults, err := DeleteReposInLabOrg(orgCtx, logger, repoNames[orgName])
+		result := OrgRepoDeleteResult{Org: orgName, Results: orgResults}
+		if err != nil {
+			result.Error = err.Error()
+			failedOrgs++
+		}
+		results = append(results, result)
+	}
+
+	if failedOrgs > 0 {
+		return results, fmt.Errorf("failed to delete repositories in %d of %d organization(s)", failedOrgs, len(orgs))
+	}
+	return results, nil
 }
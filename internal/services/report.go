@@ -1,25 +1,58 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
 )
 
 // LabReport represents the complete lab environment creation report
 type LabReport struct {
-	GeneratedAt         time.Time   `json:"generated_at"`
-	LabDate             string      `json:"lab_date"`
-	EnterpriseSlug      string      `json:"enterprise_slug"`
-	TotalUsers          int         `json:"total_users"`
-	SuccessCount        int         `json:"success_count"`
-	FailureCount        int         `json:"failure_count"`
-	Organizations       []OrgReport `json:"organizations"`
-	TemplateRepos       []string    `json:"template_repos"`
-	Facilitators        []string    `json:"facilitators,omitempty"`
-	InvalidUsers        []string    `json:"invalid_users,omitempty"`
-	InvalidFacilitators []string    `json:"invalid_facilitators,omitempty"`
+	GeneratedAt    time.Time   `json:"generated_at"`
+	StartedAt      time.Time   `json:"started_at"`
+	CompletedAt    time.Time   `json:"completed_at"`
+	DurationMs     int64       `json:"duration_ms"`
+	LabDate        string      `json:"lab_date"`
+	EnterpriseSlug string      `json:"enterprise_slug"`
+	TotalUsers     int         `json:"total_users"`
+	SuccessCount   int         `json:"success_count"`
+	FailureCount   int         `json:"failure_count"`
+	Organizations  []OrgReport `json:"organizations"`
+	TemplateRepos  []string    `json:"template_repos"`
+	// ExcludedTemplates lists the templates skipped with --exclude-templates
+	ExcludedTemplates []string `json:"excluded_templates,omitempty"`
+	// FacilitatorTemplates is the --facilitator-templates set used for facilitators' orgs,
+	// nil when every org got TemplateRepos
+	FacilitatorTemplates []string           `json:"facilitator_templates,omitempty"`
+	Facilitators         []string           `json:"facilitators,omitempty"`
+	InvalidUsers         []api.InvalidUser  `json:"invalid_users,omitempty"`
+	InvalidFacilitators  []api.InvalidUser  `json:"invalid_facilitators,omitempty"`
+	UserFilters          *UserFilterSummary `json:"user_filters,omitempty"`
+	// Apply is set when the report comes from lab apply rather than lab create
+	Apply bool `json:"apply,omitempty"`
+	// FacilitatorsAdminsOnly is set when --facilitators-as-admins-only skipped facilitator orgs
+	FacilitatorsAdminsOnly bool `json:"facilitators_admins_only,omitempty"`
+	// OrgsOnly is set when --orgs-only skipped repository creation
+	OrgsOnly bool `json:"orgs_only,omitempty"`
+	// WaitBetweenOrgs is the --wait-between-orgs pace, and PacingWaits how many times a
+	// worker waited it before starting its next organization
+	WaitBetweenOrgs string `json:"wait_between_orgs,omitempty"`
+	PacingWaits     int    `json:"pacing_waits,omitempty"`
+	// SharedRepo is set when --shared-repo created one repository for the whole lab
+	SharedRepo *SharedRepoReport `json:"shared_repo,omitempty"`
+	// EnterpriseInvites is set when --invite-to-enterprise was used
+	EnterpriseInvites *EnterpriseInviteSummary `json:"enterprise_invites,omitempty"`
 }
 
 // OrgReport represents the details of a single organization
@@ -30,72 +63,253 @@ type OrgReport struct {
 	Error        string       `json:"error,omitempty"`
 	Repositories []RepoReport `json:"repositories"`
 	CreatedAt    time.Time    `json:"created_at"`
+	// FacilitatorRoles is set when --facilitator-role isn't admin
+	FacilitatorRoles []FacilitatorRole `json:"facilitator_roles,omitempty"`
+	// Created and AlreadyPresent list the org-level resources lab apply created or found
+	Created        []string `json:"created,omitempty"`
+	AlreadyPresent []string `json:"already_present,omitempty"`
+	// Attempts is the number of organization creation attempts, 0 when the org already existed
+	Attempts int `json:"attempts,omitempty"`
+	// TemplateSet is "student" or "facilitator" when --facilitator-templates was used
+	TemplateSet string `json:"template_set,omitempty"`
+	// Installation is the --verify-install result for the org's app installation
+	Installation *api.InstallVerification `json:"installation,omitempty"`
+	// Dependabot is the org-level --enable-dependabot result
+	Dependabot *DependabotResult `json:"dependabot,omitempty"`
+	// OrgPolicy has one result per --org-policy setting
+	OrgPolicy []OrgPolicyResult `json:"org_policy,omitempty"`
+	// PreHook is the --pre-hook result, run before the organization was provisioned
+	PreHook *HookResult `json:"pre_hook,omitempty"`
+	// PostHook is the --post-hook result, run after the organization was provisioned
+	PostHook *HookResult `json:"post_hook,omitempty"`
+}
+
+// FacilitatorRole is the role a facilitator holds on an organization after provisioning
+type FacilitatorRole struct {
+	Login string `json:"login"`
+	Role  string `json:"role"`
 }
 
 // RepoReport represents the details of a repository
 type RepoReport struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
-	URL    string `json:"url,omitempty"`
+	Name          string   `json:"name"`
+	Status        string   `json:"status"`
+	Error         string   `json:"error,omitempty"`
+	URL           string   `json:"url,omitempty"`
+	DefaultBranch string   `json:"default_branch,omitempty"`
+	Warnings      []string `json:"warnings,omitempty"`
+	// Settings lists the settings block values applied after creation, e.g. "has_wiki=false"
+	Settings []string `json:"settings,omitempty"`
+	// Source is "import" when the repository was imported from a git URL, or
+	// "template-import" when its template was imported from the --template-base-url host,
+	// rather than generated from a template
+	Source string `json:"source,omitempty"`
+	// Dependabot is the --enable-dependabot result for the repository
+	Dependabot *DependabotResult `json:"dependabot,omitempty"`
+	// BranchProtection is the --branch-protection-template result for the default branch
+	BranchProtection *BranchProtectionResult `json:"branch_protection,omitempty"`
 }
 
 // DeleteLabReport represents the complete lab environment deletion report
 type DeleteLabReport struct {
-	GeneratedAt         time.Time         `json:"generated_at"`
-	LabDate             string            `json:"lab_date"`
-	TotalUsers          int               `json:"total_users"`
-	SuccessCount        int               `json:"success_count"`
-	FailureCount        int               `json:"failure_count"`
-	Organizations       []DeleteOrgReport `json:"organizations"`
-	Facilitators        []string          `json:"facilitators,omitempty"`
-	InvalidUsers        []string          `json:"invalid_users,omitempty"`
-	InvalidFacilitators []string          `json:"invalid_facilitators,omitempty"`
+	GeneratedAt  time.Time `json:"generated_at"`
+	StartedAt    time.Time `json:"started_at"`
+	CompletedAt  time.Time `json:"completed_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	LabDate      string    `json:"lab_date"`
+	TotalUsers   int       `json:"total_users"`
+	SuccessCount int       `json:"success_count"`
+	FailureCount int       `json:"failure_count"`
+	// PreservedCount is the number of organizations kept with --preserve-users
+	PreservedCount int `json:"preserved_count,omitempty"`
+	// ResumedCount is the number of organizations a --state-file recorded as deleted by an
+	// earlier run, which weren't attempted again
+	ResumedCount        int                `json:"resumed_count,omitempty"`
+	Organizations       []DeleteOrgReport  `json:"organizations"`
+	Facilitators        []string           `json:"facilitators,omitempty"`
+	InvalidUsers        []api.InvalidUser  `json:"invalid_users,omitempty"`
+	InvalidFacilitators []api.InvalidUser  `json:"invalid_facilitators,omitempty"`
+	UserFilters         *UserFilterSummary `json:"user_filters,omitempty"`
+	// DiscoveredOrgs lists the organizations --discover found that the users file didn't list
+	DiscoveredOrgs []string `json:"discovered_orgs,omitempty"`
+}
+
+// SuccessRate returns the percentage of organizations that are deleted, counting those an
+// earlier run deleted as successes
+func (r *DeleteLabReport) SuccessRate() float64 {
+	if r.TotalUsers == 0 {
+		return 0
+	}
+	return float64(r.SuccessCount+r.ResumedCount) / float64(r.TotalUsers) * 100
 }
 
 // DeleteOrgReport represents the deletion details of a single organization
 type DeleteOrgReport struct {
 	User      string    `json:"user"`
 	OrgName   string    `json:"org_name"`
-	Status    string    `json:"status"` // "success" or "failed"
+	Status    string    `json:"status"` // "success", "failed", "preserved" or "resumed"
 	Error     string    `json:"error,omitempty"`
 	DeletedAt time.Time `json:"deleted_at"`
+	// AlreadyDeleted is set when the organization no longer existed, e.g. because an
+	// interrupted earlier run deleted it
+	AlreadyDeleted bool `json:"already_deleted,omitempty"`
+	// Repositories are the --delete-repos-first deletions made before the organization's
+	Repositories []RepoDeleteResult `json:"repositories,omitempty"`
+	// RepositoriesError is set when --delete-repos-first couldn't delete every repository
+	RepositoriesError string `json:"repositories_error,omitempty"`
+}
+
+// TemplateResult aggregates the outcome of a single template across all organizations
+type TemplateResult struct {
+	Name            string `json:"name"`
+	SuccessCount    int    `json:"success_count"`
+	FailureCount    int    `json:"failure_count"`
+	MostCommonError string `json:"most_common_error,omitempty"`
 }
 
-// GenerateReportFiles generates Markdown report and GitHub Actions summary
-func GenerateReportFiles(report *LabReport, outputDir string) error {
-	if outputDir == "" {
-		outputDir = "."
+// SuccessRate returns the percentage of organizations where the template was created
+func (t TemplateResult) SuccessRate() float64 {
+	total := t.SuccessCount + t.FailureCount
+	if total == 0 {
+		return 0
 	}
+	return float64(t.SuccessCount) / float64(total) * 100
+}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+// CohortDateSummary represents the outcome of provisioning a single lab date in a multi-date run
+type CohortDateSummary struct {
+	LabDate      string `json:"lab_date"`
+	TotalUsers   int    `json:"total_users"`
+	SuccessCount int    `json:"success_count"`
+	FailureCount int    `json:"failure_count"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ReportOptions controls where report files are written and how they are named
+type ReportOptions struct {
+	// OutputDir is the directory reports are written to. Defaults to the current directory.
+	OutputDir string
+	// NoTimestamp drops the timestamp suffix so report paths are predictable
+	NoTimestamp bool
+	// Strict makes a report-write failure fail the run. By default report failures are
+	// logged but never change the run's outcome.
+	Strict bool
+	// IncludeInvalidDetails renders the reason each invalid user was skipped
+	IncludeInvalidDetails bool
+	// MatrixOutput writes the successful organizations as a GitHub Actions matrix output
+	MatrixOutput bool
+	// webBaseURL is the web UI URL organization links in the matrix are built from
+	webBaseURL string
+	// sinks receive the finished report. Nil means the Markdown file only.
+	sinks []ReportSink
+}
+
+// ReportOptionsFromContext builds report options from the values stored in the context
+func ReportOptionsFromContext(ctx context.Context) ReportOptions {
+	noTimestamp, _ := ctx.Value(config.ReportNoTimestampKey).(bool)
+	strict, _ := ctx.Value(config.StrictReportsKey).(bool)
+	includeInvalidDetails, _ := ctx.Value(config.ReportInvalidDetailsKey).(bool)
+	matrixOutput, _ := ctx.Value(config.ActionsMatrixOutputKey).(bool)
+	baseURL, _ := ctx.Value(config.BaseURLKey).(string)
+	opts := ReportOptions{
+		OutputDir:             "reports",
+		NoTimestamp:           noTimestamp,
+		Strict:                strict,
+		IncludeInvalidDetails: includeInvalidDetails,
+		MatrixOutput:          matrixOutput,
+		webBaseURL:            webBaseURL(baseURL),
+	}
+	opts.sinks = newReportSinks(ctx, opts.OutputDir, opts.NoTimestamp)
+	return opts
+}
+
+// ResolveRunError applies the report failure policy. Report errors are always logged; they
+// are only combined with the run's error when strict reports are enabled, or when the JSON
+// report couldn't be written.
+func ResolveRunError(logger *slog.Logger, opts ReportOptions, runErr error, reportErr error) error {
+	if reportErr == nil {
+		return runErr
 	}
 
-	timestamp := time.Now().Format("20060102-150405")
-	filename := fmt.Sprintf("lab-report-%s-%s.md", report.LabDate, timestamp)
-	mdPath := filepath.Join(outputDir, filename)
+	logger.Error("Failed to generate report files",
+		slog.Any("error", reportErr),
+		slog.Bool("strict_reports", opts.Strict))
 
-	// Generate Markdown report
-	if err := generateMarkdownReport(report, mdPath); err != nil {
-		return err
+	if !opts.Strict && !errors.Is(reportErr, errJSONReport) {
+		return runErr
 	}
+	return errors.Join(runErr, fmt.Errorf("report generation failed: %w", reportErr))
+}
+
+// reportTimestampLayout is the local-time timestamp appended to report file names
+const reportTimestampLayout = "20060102-150405"
+
+// reportFileName builds a report file name from its base, appending a timestamp unless disabled
+func reportFileName(base string, ext string, noTimestamp bool) string {
+	if noTimestamp {
+		return fmt.Sprintf("%s.%s", base, ext)
+	}
+	return fmt.Sprintf("%s-%s.%s", base, time.Now().Format(reportTimestampLayout), ext)
+}
+
+// errJSONReport marks a failure to write the JSON report. Downstream automation depends on
+// it, so unlike other report failures it fails the run even without --strict-reports.
+var errJSONReport = errors.New("failed to write JSON report")
+
+// generateJSONReport writes the lab report as indented JSON for downstream automation
+func generateJSONReport(report *LabReport, filePath string) error {
+	return writeJSONReport(report, filePath)
+}
+
+// generateDeleteJSONReport writes the deletion report as indented JSON
+func generateDeleteJSONReport(report *DeleteLabReport, filePath string) error {
+	return writeJSONReport(report, filePath)
+}
+
+func writeJSONReport(report any, filePath string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %v", errJSONReport, err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("%w: %v", errJSONReport, err)
+	}
+	return nil
+}
+
+// GenerateReportFiles renders the Markdown report, delivers it to the configured report
+// sinks and writes the GitHub Actions summary
+func GenerateReportFiles(report *LabReport, opts ReportOptions) error {
+	recordLabReportMetrics(report)
+
+	var markdown bytes.Buffer
+	writeMarkdownReport(&markdown, report, opts)
 
 	// Generate GitHub Actions Step Summary if running in Actions
-	if err := generateGitHubStepSummary(report); err != nil {
+	if err := generateGitHubStepSummary(report, opts); err != nil {
 		// Don't fail if we can't write to step summary
 		fmt.Fprintf(os.Stderr, "Warning: Failed to write GitHub step summary: %v\n", err)
 	}
+	writeActionsAnnotations(report)
 
-	fmt.Printf("\n✅ Report generated successfully:\n")
-	fmt.Printf("  📝 Markdown: %s\n", mdPath)
+	if opts.MatrixOutput {
+		if err := writeActionsMatrixOutput(report, opts.webBaseURL); err != nil {
+			return fmt.Errorf("failed to write GitHub Actions matrix output: %w", err)
+		}
+	}
 
-	return nil
+	return deliverReport(opts, ReportDocument{
+		BaseName: "lab-report-" + report.LabDate,
+		Title:    fmt.Sprintf("Lab report for %s", report.LabDate),
+		Summary: fmt.Sprintf("%d of %d organizations provisioned successfully, %d failed",
+			report.SuccessCount, report.TotalUsers, report.FailureCount),
+		Markdown: markdown.String(),
+		Data:     report,
+	})
 }
 
 // generateGitHubStepSummary writes a summary to GitHub Actions UI
-func generateGitHubStepSummary(report *LabReport) error {
+func generateGitHubStepSummary(report *LabReport, opts ReportOptions) error {
 	stepSummaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
 	if stepSummaryPath == "" {
 		// Not running in GitHub Actions, skip
@@ -110,6 +324,9 @@ func generateGitHubStepSummary(report *LabReport) error {
 
 	// Write beautiful markdown summary
 	fmt.Fprintf(file, "# 🧪 Lab Environment Report\n\n")
+	if report.Apply {
+		fmt.Fprintf(file, "> ♻️ Generated by `lab apply`: existing resources were left unchanged and are marked as already present.\n\n")
+	}
 
 	// Summary badges/stats
 	successRate := float64(report.SuccessCount) / float64(report.TotalUsers) * 100
@@ -132,31 +349,12 @@ func generateGitHubStepSummary(report *LabReport) error {
 	fmt.Fprintf(file, "| ❌ **Failed** | %d | %.1f%% |\n", report.FailureCount,
 		float64(report.FailureCount)/float64(report.TotalUsers)*100)
 	fmt.Fprintf(file, "\n")
+	writeThroughputMarkdown(file, report.SuccessCount+report.FailureCount, report.DurationMs)
+	writePacingMarkdown(file, report.WaitBetweenOrgs, report.PacingWaits)
+	fmt.Fprintf(file, "\n")
 
 	// Invalid users warning
-	if len(report.InvalidUsers) > 0 || len(report.InvalidFacilitators) > 0 {
-		fmt.Fprintf(file, "## ⚠️ Invalid Users Skipped\n\n")
-		if len(report.InvalidUsers) > 0 {
-			fmt.Fprintf(file, "**Invalid Users (%d):** ", len(report.InvalidUsers))
-			for i, u := range report.InvalidUsers {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "`@%s`", u)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-		if len(report.InvalidFacilitators) > 0 {
-			fmt.Fprintf(file, "**Invalid Facilitators (%d):** ", len(report.InvalidFacilitators))
-			for i, f := range report.InvalidFacilitators {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "`@%s`", f)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-	}
+	writeInvalidUsersMarkdown(file, report.InvalidUsers, report.InvalidFacilitators, opts.IncludeInvalidDetails, "`@%s`")
 
 	// Facilitators
 	if len(report.Facilitators) > 0 {
@@ -169,6 +367,7 @@ func generateGitHubStepSummary(report *LabReport) error {
 		}
 		fmt.Fprintf(file, "\n\n")
 	}
+	writeFacilitatorsAdminsOnlyMarkdown(file, report.FacilitatorsAdminsOnly)
 
 	// Template repos
 	fmt.Fprintf(file, "## 📦 Template Repositories (%d)\n\n", len(report.TemplateRepos))
@@ -177,6 +376,34 @@ func generateGitHubStepSummary(report *LabReport) error {
 		fmt.Fprintf(file, "- `%s`\n", repo)
 	}
 	fmt.Fprintf(file, "\n</details>\n\n")
+	writeExcludedTemplatesMarkdown(file, report.ExcludedTemplates)
+	writeOrgsOnlyMarkdown(file, report.OrgsOnly)
+	writeFacilitatorTemplatesMarkdown(file, report.FacilitatorTemplates)
+
+	// Template results
+	if templateResults := buildTemplateResults(report.Organizations); len(templateResults) > 0 {
+		fmt.Fprintf(file, "## 📈 Template Results\n\n")
+		fmt.Fprintf(file, "| Template | Succeeded | Failed | Success Rate | Most Common Error |\n")
+		fmt.Fprintf(file, "|----------|----------:|-------:|-------------:|-------------------|\n")
+		for _, t := range templateResults {
+			emoji := "✅"
+			if t.FailureCount > 0 {
+				emoji = "⚠️"
+			}
+			if t.SuccessCount == 0 {
+				emoji = "❌"
+			}
+			errorMsg := t.MostCommonError
+			if len(errorMsg) > 80 {
+				errorMsg = errorMsg[:77] + "..."
+			}
+			fmt.Fprintf(file, "| %s `%s` | %d | %d | %.1f%% | %s |\n",
+				emoji, t.Name, t.SuccessCount, t.FailureCount, t.SuccessRate(), markdownTableCell(errorMsg))
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	writeSharedRepoMarkdown(file, report.SharedRepo)
 
 	// Organization results
 	if report.SuccessCount > 0 {
@@ -192,7 +419,7 @@ func generateGitHubStepSummary(report *LabReport) error {
 				for _, repo := range org.Repositories {
 					if repo.Status == "success" {
 						successRepos++
-					} else {
+					} else if repo.Status != "skipped" {
 						failedRepos++
 					}
 				}
@@ -212,8 +439,8 @@ func generateGitHubStepSummary(report *LabReport) error {
 	// Failed organizations
 	if report.FailureCount > 0 {
 		fmt.Fprintf(file, "## ❌ Failed Organizations (%d)\n\n", report.FailureCount)
-		fmt.Fprintf(file, "| Organization | User | Error |\n")
-		fmt.Fprintf(file, "|--------------|------|-------|\n")
+		fmt.Fprintf(file, "| Organization | User | Attempts | Error |\n")
+		fmt.Fprintf(file, "|--------------|------|---------:|-------|\n")
 
 		for _, org := range report.Organizations {
 			if org.Status == "failed" {
@@ -222,11 +449,16 @@ func generateGitHubStepSummary(report *LabReport) error {
 				if len(errorMsg) > 80 {
 					errorMsg = errorMsg[:77] + "..."
 				}
-				fmt.Fprintf(file, "| `%s` | `@%s` | %s |\n", org.OrgName, org.User, errorMsg)
+				fmt.Fprintf(file, "| `%s` | `@%s` | %d | %s |\n", org.OrgName, org.User, org.Attempts, errorMsg)
 			}
 		}
 		fmt.Fprintf(file, "\n")
 	}
+	writeUnverifiedInstallsMarkdown(file, report.Organizations, "##")
+	writeDependabotMarkdown(file, report.Organizations, "##")
+	writeBranchProtectionMarkdown(file, report.Organizations, "##")
+	writeOrgPolicyMarkdown(file, report.Organizations, "##")
+	writeHooksMarkdown(file, report.Organizations, "##")
 
 	// Repository details (collapsible)
 	fmt.Fprintf(file, "## 📁 Repository Details\n\n")
@@ -238,7 +470,9 @@ func generateGitHubStepSummary(report *LabReport) error {
 
 			for _, repo := range org.Repositories {
 				if repo.Status == "success" {
-					fmt.Fprintf(file, "- ✅ [%s](%s)\n", repo.Name, repo.URL)
+					fmt.Fprintf(file, "- ✅ [%s](%s)%s\n", repo.Name, repo.URL, repoSourceNote(repo))
+				} else if repo.Status == "skipped" {
+					fmt.Fprintf(file, "- ⏭️ [%s](%s) - already present\n", repo.Name, repo.URL)
 				} else {
 					fmt.Fprintf(file, "- ❌ `%s` - %s\n", repo.Name, repo.Error)
 				}
@@ -258,18 +492,16 @@ func generateGitHubStepSummary(report *LabReport) error {
 	return nil
 }
 
-func generateMarkdownReport(report *LabReport, filePath string) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create Markdown report file: %w", err)
-	}
-	defer file.Close()
-
+// writeMarkdownReport renders the full lab report
+func writeMarkdownReport(file io.Writer, report *LabReport, opts ReportOptions) {
 	// Write header
 	fmt.Fprintf(file, "# Lab Environment Report\n\n")
 	fmt.Fprintf(file, "**Generated:** %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
 	fmt.Fprintf(file, "**Lab Date:** %s\n\n", report.LabDate)
 	fmt.Fprintf(file, "**Enterprise:** %s\n\n", report.EnterpriseSlug)
+	if report.Apply {
+		fmt.Fprintf(file, "**Mode:** `lab apply` (existing resources were left unchanged and are marked as already present)\n\n")
+	}
 
 	if len(report.Facilitators) > 0 {
 		fmt.Fprintf(file, "**Facilitators:** ")
@@ -281,38 +513,25 @@ func generateMarkdownReport(report *LabReport, filePath string) error {
 		}
 		fmt.Fprintf(file, "\n\n")
 	}
+	writeFacilitatorsAdminsOnlyMarkdown(file, report.FacilitatorsAdminsOnly)
 
-	// Write invalid users warning if any
-	if len(report.InvalidUsers) > 0 || len(report.InvalidFacilitators) > 0 {
-		fmt.Fprintf(file, "## ⚠️ Invalid Users Skipped\n\n")
-		if len(report.InvalidUsers) > 0 {
-			fmt.Fprintf(file, "**Invalid Users (%d):** ", len(report.InvalidUsers))
-			for i, u := range report.InvalidUsers {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "@%s", u)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-		if len(report.InvalidFacilitators) > 0 {
-			fmt.Fprintf(file, "**Invalid Facilitators (%d):** ", len(report.InvalidFacilitators))
-			for i, f := range report.InvalidFacilitators {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "@%s", f)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-	}
+	// Invalid users warning
+	writeInvalidUsersMarkdown(file, report.InvalidUsers, report.InvalidFacilitators, opts.IncludeInvalidDetails, "@%s")
+
+	writeUserFiltersMarkdown(file, report.UserFilters)
+	writeEnterpriseInvitesMarkdown(file, report.EnterpriseInvites)
 
 	// Write summary
 	fmt.Fprintf(file, "## Summary\n\n")
 	fmt.Fprintf(file, "- **Total Users:** %d\n", report.TotalUsers)
 	fmt.Fprintf(file, "- **Successful Organizations:** %d\n", report.SuccessCount)
 	fmt.Fprintf(file, "- **Failed Organizations:** %d\n", report.FailureCount)
-	fmt.Fprintf(file, "- **Success Rate:** %.1f%%\n\n", float64(report.SuccessCount)/float64(report.TotalUsers)*100)
+	fmt.Fprintf(file, "- **Success Rate:** %.1f%%\n", float64(report.SuccessCount)/float64(report.TotalUsers)*100)
+	writeThroughputMarkdown(file, report.SuccessCount+report.FailureCount, report.DurationMs)
+	writePacingMarkdown(file, report.WaitBetweenOrgs, report.PacingWaits)
+	fmt.Fprintf(file, "\n")
+
+	writeUserOrgTableMarkdown(file, report, opts.webBaseURL)
 
 	// Write template repositories
 	fmt.Fprintf(file, "## Template Repositories\n\n")
@@ -320,6 +539,23 @@ func generateMarkdownReport(report *LabReport, filePath string) error {
 		fmt.Fprintf(file, "- `%s`\n", repo)
 	}
 	fmt.Fprintf(file, "\n")
+	writeExcludedTemplatesMarkdown(file, report.ExcludedTemplates)
+	writeOrgsOnlyMarkdown(file, report.OrgsOnly)
+	writeFacilitatorTemplatesMarkdown(file, report.FacilitatorTemplates)
+
+	// Write template results
+	if templateResults := buildTemplateResults(report.Organizations); len(templateResults) > 0 {
+		fmt.Fprintf(file, "## Template Results\n\n")
+		fmt.Fprintf(file, "| Template | Succeeded | Failed | Success Rate | Most Common Error |\n")
+		fmt.Fprintf(file, "|----------|----------:|-------:|-------------:|-------------------|\n")
+		for _, t := range templateResults {
+			fmt.Fprintf(file, "| `%s` | %d | %d | %.1f%% | %s |\n",
+				t.Name, t.SuccessCount, t.FailureCount, t.SuccessRate(), markdownTableCell(t.MostCommonError))
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	writeSharedRepoMarkdown(file, report.SharedRepo)
 
 	// Write successful organizations
 	if report.SuccessCount > 0 {
@@ -329,23 +565,42 @@ func generateMarkdownReport(report *LabReport, filePath string) error {
 				fmt.Fprintf(file, "### %s\n\n", org.OrgName)
 				fmt.Fprintf(file, "- **User:** @%s\n", org.User)
 				fmt.Fprintf(file, "- **Created At:** %s\n", org.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+				if org.TemplateSet != "" {
+					fmt.Fprintf(file, "- **Template Set:** %s\n", org.TemplateSet)
+				}
+				writeInstallVerificationMarkdown(file, org.Installation)
+				writeOrgAttemptsMarkdown(file, org.Attempts)
+				writeFacilitatorRolesMarkdown(file, org.FacilitatorRoles)
+				writeApplyChangesMarkdown(file, org)
 
 				successRepos := 0
 				failedRepos := 0
 				for _, repo := range org.Repositories {
 					if repo.Status == "success" {
 						successRepos++
-					} else {
+					} else if repo.Status != "skipped" {
 						failedRepos++
 					}
 				}
-				fmt.Fprintf(file, "- **Repositories:** %d created, %d failed\n\n", successRepos, failedRepos)
+				if skippedRepos := len(org.Repositories) - successRepos - failedRepos; skippedRepos > 0 {
+					fmt.Fprintf(file, "- **Repositories:** %d created, %d already present, %d failed\n\n", successRepos, skippedRepos, failedRepos)
+				} else {
+					fmt.Fprintf(file, "- **Repositories:** %d created, %d failed\n\n", successRepos, failedRepos)
+				}
 
 				if len(org.Repositories) > 0 {
 					fmt.Fprintf(file, "#### Repositories:\n\n")
 					for _, repo := range org.Repositories {
 						if repo.Status == "success" {
-							fmt.Fprintf(file, "- ✅ `%s` - [%s](%s)\n", repo.Name, repo.URL, repo.URL)
+							fmt.Fprintf(file, "- ✅ `%s` - [%s](%s)%s\n", repo.Name, repo.URL, repo.URL, repoSourceNote(repo))
+							if len(repo.Settings) > 0 {
+								fmt.Fprintf(file, "  - Settings: %s\n", strings.Join(repo.Settings, ", "))
+							}
+							for _, warning := range repo.Warnings {
+								fmt.Fprintf(file, "  - ⚠️ %s\n", warning)
+							}
+						} else if repo.Status == "skipped" {
+							fmt.Fprintf(file, "- ⏭️ `%s` - already present - [%s](%s)\n", repo.Name, repo.URL, repo.URL)
 						} else {
 							fmt.Fprintf(file, "- ❌ `%s` - Error: %s\n", repo.Name, repo.Error)
 						}
@@ -363,48 +618,46 @@ func generateMarkdownReport(report *LabReport, filePath string) error {
 			if org.Status == "failed" {
 				fmt.Fprintf(file, "### %s\n\n", org.OrgName)
 				fmt.Fprintf(file, "- **User:** @%s\n", org.User)
+				writeOrgAttemptsMarkdown(file, org.Attempts)
 				fmt.Fprintf(file, "- **Error:** %s\n\n", org.Error)
 			}
 		}
 	}
 
-	return nil
+	writeUnverifiedInstallsMarkdown(file, report.Organizations, "##")
+	writeDependabotMarkdown(file, report.Organizations, "##")
+	writeBranchProtectionMarkdown(file, report.Organizations, "##")
+	writeOrgPolicyMarkdown(file, report.Organizations, "##")
+	writeHooksMarkdown(file, report.Organizations, "##")
 }
 
-// GenerateDeleteReportFiles generates Markdown report and GitHub Actions summary for deletions
-func GenerateDeleteReportFiles(report *DeleteLabReport, outputDir string) error {
-	if outputDir == "" {
-		outputDir = "."
-	}
+// GenerateDeleteReportFiles renders the Markdown deletion report, delivers it to the
+// configured report sinks and writes the GitHub Actions summary
+func GenerateDeleteReportFiles(report *DeleteLabReport, opts ReportOptions) error {
+	recordDeleteReportMetrics(report)
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	timestamp := time.Now().Format("20060102-150405")
-	filename := fmt.Sprintf("lab-delete-report-%s-%s.md", report.LabDate, timestamp)
-	mdPath := filepath.Join(outputDir, filename)
-
-	// Generate Markdown report
-	if err := generateDeleteMarkdownReport(report, mdPath); err != nil {
-		return err
-	}
+	var markdown bytes.Buffer
+	writeDeleteMarkdownReport(&markdown, report, opts)
 
 	// Generate GitHub Actions Step Summary if running in Actions
-	if err := generateDeleteGitHubStepSummary(report); err != nil {
+	if err := generateDeleteGitHubStepSummary(report, opts); err != nil {
 		// Don't fail if we can't write to step summary
 		fmt.Fprintf(os.Stderr, "Warning: Failed to write GitHub step summary: %v\n", err)
 	}
-
-	fmt.Printf("\n✅ Deletion report generated successfully:\n")
-	fmt.Printf("  📝 Markdown: %s\n", mdPath)
-
-	return nil
+	writeDeleteActionsAnnotations(report)
+
+	return deliverReport(opts, ReportDocument{
+		BaseName: "lab-delete-report-" + report.LabDate,
+		Title:    fmt.Sprintf("Deletion report for %s", report.LabDate),
+		Summary: fmt.Sprintf("%d of %d organizations deleted successfully, %d failed",
+			report.SuccessCount, report.TotalUsers, report.FailureCount),
+		Markdown: markdown.String(),
+		Data:     report,
+	})
 }
 
 // generateDeleteGitHubStepSummary writes a deletion summary to GitHub Actions UI
-func generateDeleteGitHubStepSummary(report *DeleteLabReport) error {
+func generateDeleteGitHubStepSummary(report *DeleteLabReport, opts ReportOptions) error {
 	stepSummaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
 	if stepSummaryPath == "" {
 		// Not running in GitHub Actions, skip
@@ -421,7 +674,7 @@ func generateDeleteGitHubStepSummary(report *DeleteLabReport) error {
 	fmt.Fprintf(file, "# 🗑️ Lab Environment Deletion Report\n\n")
 
 	// Summary badges/stats
-	successRate := float64(report.SuccessCount) / float64(report.TotalUsers) * 100
+	successRate := report.SuccessRate()
 	emoji := "✅"
 	if successRate < 100 {
 		emoji = "⚠️"
@@ -440,32 +693,18 @@ func generateDeleteGitHubStepSummary(report *DeleteLabReport) error {
 	fmt.Fprintf(file, "| ✅ **Successfully Deleted** | %d | %.1f%% |\n", report.SuccessCount, successRate)
 	fmt.Fprintf(file, "| ❌ **Failed to Delete** | %d | %.1f%% |\n", report.FailureCount,
 		float64(report.FailureCount)/float64(report.TotalUsers)*100)
+	if report.PreservedCount > 0 {
+		fmt.Fprintf(file, "| 🛡️ **Preserved** | %d | - |\n", report.PreservedCount)
+	}
+	if report.ResumedCount > 0 {
+		fmt.Fprintf(file, "| ⏭️ **Deleted by an Earlier Run** | %d | - |\n", report.ResumedCount)
+	}
+	fmt.Fprintf(file, "\n")
+	writeThroughputMarkdown(file, report.SuccessCount+report.FailureCount, report.DurationMs)
 	fmt.Fprintf(file, "\n")
 
 	// Invalid users warning
-	if len(report.InvalidUsers) > 0 || len(report.InvalidFacilitators) > 0 {
-		fmt.Fprintf(file, "## ⚠️ Invalid Users Skipped\n\n")
-		if len(report.InvalidUsers) > 0 {
-			fmt.Fprintf(file, "**Invalid Users (%d):** ", len(report.InvalidUsers))
-			for i, u := range report.InvalidUsers {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "`@%s`", u)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-		if len(report.InvalidFacilitators) > 0 {
-			fmt.Fprintf(file, "**Invalid Facilitators (%d):** ", len(report.InvalidFacilitators))
-			for i, f := range report.InvalidFacilitators {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "`@%s`", f)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-	}
+	writeInvalidUsersMarkdown(file, report.InvalidUsers, report.InvalidFacilitators, opts.IncludeInvalidDetails, "`@%s`")
 
 	// Facilitators
 	if len(report.Facilitators) > 0 {
@@ -513,6 +752,20 @@ func generateDeleteGitHubStepSummary(report *DeleteLabReport) error {
 		fmt.Fprintf(file, "\n")
 	}
 
+	// Preserved organizations
+	if report.PreservedCount > 0 {
+		fmt.Fprintf(file, "## 🛡️ Preserved Organizations (%d)\n\n", report.PreservedCount)
+		fmt.Fprintf(file, "| Organization | User |\n")
+		fmt.Fprintf(file, "|--------------|------|\n")
+
+		for _, org := range report.Organizations {
+			if org.Status == "preserved" {
+				fmt.Fprintf(file, "| 🛡️ `%s` | `@%s` |\n", org.OrgName, org.User)
+			}
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
 	// Footer
 	fmt.Fprintf(file, "---\n\n")
 	fmt.Fprintf(file, "*Generated at: %s*\n", report.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
@@ -522,13 +775,8 @@ func generateDeleteGitHubStepSummary(report *DeleteLabReport) error {
 	return nil
 }
 
-func generateDeleteMarkdownReport(report *DeleteLabReport, filePath string) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create Markdown deletion report file: %w", err)
-	}
-	defer file.Close()
-
+// writeDeleteMarkdownReport renders the full deletion report
+func writeDeleteMarkdownReport(file io.Writer, report *DeleteLabReport, opts ReportOptions) {
 	// Write header
 	fmt.Fprintf(file, "# Lab Environment Deletion Report\n\n")
 	fmt.Fprintf(file, "**Generated:** %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
@@ -545,37 +793,26 @@ func generateDeleteMarkdownReport(report *DeleteLabReport, filePath string) erro
 		fmt.Fprintf(file, "\n\n")
 	}
 
-	// Write invalid users warning if any
-	if len(report.InvalidUsers) > 0 || len(report.InvalidFacilitators) > 0 {
-		fmt.Fprintf(file, "## ⚠️ Invalid Users Skipped\n\n")
-		if len(report.InvalidUsers) > 0 {
-			fmt.Fprintf(file, "**Invalid Users (%d):** ", len(report.InvalidUsers))
-			for i, u := range report.InvalidUsers {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "@%s", u)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-		if len(report.InvalidFacilitators) > 0 {
-			fmt.Fprintf(file, "**Invalid Facilitators (%d):** ", len(report.InvalidFacilitators))
-			for i, f := range report.InvalidFacilitators {
-				if i > 0 {
-					fmt.Fprintf(file, ", ")
-				}
-				fmt.Fprintf(file, "@%s", f)
-			}
-			fmt.Fprintf(file, "\n\n")
-		}
-	}
+	// Invalid users warning
+	writeInvalidUsersMarkdown(file, report.InvalidUsers, report.InvalidFacilitators, opts.IncludeInvalidDetails, "@%s")
+
+	writeUserFiltersMarkdown(file, report.UserFilters)
+	writeDiscoveredOrgsMarkdown(file, report.DiscoveredOrgs)
 
 	// Write summary
 	fmt.Fprintf(file, "## Summary\n\n")
 	fmt.Fprintf(file, "- **Total Organizations:** %d\n", report.TotalUsers)
 	fmt.Fprintf(file, "- **Successfully Deleted:** %d\n", report.SuccessCount)
 	fmt.Fprintf(file, "- **Failed to Delete:** %d\n", report.FailureCount)
-	fmt.Fprintf(file, "- **Success Rate:** %.1f%%\n\n", float64(report.SuccessCount)/float64(report.TotalUsers)*100)
+	if report.PreservedCount > 0 {
+		fmt.Fprintf(file, "- **Preserved:** %d\n", report.PreservedCount)
+	}
+	if report.ResumedCount > 0 {
+		fmt.Fprintf(file, "- **Deleted by an Earlier Run:** %d (skipped using the state file)\n", report.ResumedCount)
+	}
+	fmt.Fprintf(file, "- **Success Rate:** %.1f%%\n", report.SuccessRate())
+	writeThroughputMarkdown(file, report.SuccessCount+report.FailureCount, report.DurationMs)
+	fmt.Fprintf(file, "\n")
 
 	// Write successfully deleted organizations
 	if report.SuccessCount > 0 {
@@ -584,7 +821,11 @@ func generateDeleteMarkdownReport(report *DeleteLabReport, filePath string) erro
 			if org.Status == "success" {
 				fmt.Fprintf(file, "### %s\n\n", org.OrgName)
 				fmt.Fprintf(file, "- **User:** @%s\n", org.User)
+				if org.AlreadyDeleted {
+					fmt.Fprintf(file, "- **Already Deleted:** the organization no longer existed\n")
+				}
 				fmt.Fprintf(file, "- **Deleted At:** %s\n\n", org.DeletedAt.Format("2006-01-02 15:04:05 MST"))
+				writeDeletedReposMarkdown(file, org)
 			}
 		}
 	}
@@ -597,9 +838,446 @@ func generateDeleteMarkdownReport(report *DeleteLabReport, filePath string) erro
 				fmt.Fprintf(file, "### %s\n\n", org.OrgName)
 				fmt.Fprintf(file, "- **User:** @%s\n", org.User)
 				fmt.Fprintf(file, "- **Error:** %s\n\n", org.Error)
+				writeDeletedReposMarkdown(file, org)
 			}
 		}
 	}
 
-	return nil
+	// Write preserved organizations
+	if report.PreservedCount > 0 {
+		fmt.Fprintf(file, "## 🛡️ Preserved Organizations\n\n")
+		for _, org := range report.Organizations {
+			if org.Status == "preserved" {
+				fmt.Fprintf(file, "- %s (@%s)\n", org.OrgName, org.User)
+			}
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	// Write organizations an earlier run already deleted
+	if report.ResumedCount > 0 {
+		fmt.Fprintf(file, "## ⏭️ Deleted by an Earlier Run\n\n")
+		for _, org := range report.Organizations {
+			if org.Status == "resumed" {
+				fmt.Fprintf(file, "- %s\n", org.OrgName)
+			}
+		}
+		fmt.Fprintf(file, "\n")
+	}
+}
+
+// writeDeletedReposMarkdown lists the repositories --delete-repos-first deleted ahead of
+// an organization, as a sub-section of that organization's result
+func writeDeletedReposMarkdown(w io.Writer, org DeleteOrgReport) {
+	if len(org.Repositories) == 0 && org.RepositoriesError == "" {
+		return
+	}
+	counts := map[string]int{}
+	for _, repo := range org.Repositories {
+		counts[repo.Status]++
+	}
+	fmt.Fprintf(w, "#### Repositories Deleted First\n\n")
+	if len(org.Repositories) == 0 {
+		fmt.Fprintf(w, "- ❌ %s\n\n", org.RepositoriesError)
+		return
+	}
+	fmt.Fprintf(w, "%d deleted, %d not found, %d failed\n\n",
+		counts[RepoDeleteStatusDeleted], counts[RepoDeleteStatusNotFound], counts[RepoDeleteStatusFailed])
+	for _, repo := range org.Repositories {
+		switch repo.Status {
+		case RepoDeleteStatusDeleted:
+			fmt.Fprintf(w, "- ✅ %s\n", repo.Name)
+		case RepoDeleteStatusNotFound:
+			fmt.Fprintf(w, "- ⏭️ %s (not found)\n", repo.Name)
+		default:
+			fmt.Fprintf(w, "- ❌ %s: %s\n", repo.Name, repo.Error)
+		}
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// GenerateCohortSummaryFile renders the combined Markdown summary for a multi-date run and
+// delivers it to the configured report sinks
+func GenerateCohortSummaryFile(summaries []CohortDateSummary, opts ReportOptions) error {
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	var file bytes.Buffer
+	totalUsers, totalSuccess, totalFailed := 0, 0, 0
+
+	fmt.Fprintf(&file, "# Lab Cohort Summary\n\n")
+	fmt.Fprintf(&file, "**Generated:** %s\n\n", time.Now().Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&file, "| Lab Date | Total Users | Successful | Failed | Error |\n")
+	fmt.Fprintf(&file, "|----------|------------:|-----------:|-------:|-------|\n")
+	for _, s := range summaries {
+		totalUsers += s.TotalUsers
+		totalSuccess += s.SuccessCount
+		totalFailed += s.FailureCount
+
+		emoji := "✅"
+		if s.Error != "" || s.FailureCount > 0 {
+			emoji = "❌"
+		}
+		fmt.Fprintf(&file, "| %s `%s` | %d | %d | %d | %s |\n",
+			emoji, s.LabDate, s.TotalUsers, s.SuccessCount, s.FailureCount, markdownTableCell(s.Error))
+	}
+	fmt.Fprintf(&file, "| **Total** | %d | %d | %d | |\n\n", totalUsers, totalSuccess, totalFailed)
+
+	first, last := summaries[0].LabDate, summaries[len(summaries)-1].LabDate
+	return deliverReport(opts, ReportDocument{
+		BaseName: fmt.Sprintf("lab-cohort-summary-%s-to-%s", first, last),
+		Title:    fmt.Sprintf("Cohort summary for %s to %s", first, last),
+		Summary: fmt.Sprintf("%d lab date(s): %d of %d organizations provisioned successfully, %d failed",
+			len(summaries), totalSuccess, totalUsers, totalFailed),
+		Markdown: file.String(),
+		Data:     summaries,
+	})
+}
+
+// writeInvalidUsersMarkdown writes the users and facilitators skipped as invalid, either as
+// a bare list or, with details, as a table giving the reason for each. nameFormat formats a
+// single username.
+func writeInvalidUsersMarkdown(w io.Writer, invalidUsers []api.InvalidUser, invalidFacilitators []api.InvalidUser, withDetails bool, nameFormat string) {
+	if len(invalidUsers) == 0 && len(invalidFacilitators) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "## ⚠️ Invalid Users Skipped\n\n")
+
+	if withDetails {
+		fmt.Fprintf(w, "| User | Role | Reason |\n")
+		fmt.Fprintf(w, "|------|------|--------|\n")
+		for _, u := range invalidUsers {
+			fmt.Fprintf(w, "| "+nameFormat+" | Student | %s |\n", u.Name, markdownTableCell(u.Reason))
+		}
+		for _, f := range invalidFacilitators {
+			fmt.Fprintf(w, "| "+nameFormat+" | Facilitator | %s |\n", f.Name, markdownTableCell(f.Reason))
+		}
+		fmt.Fprintf(w, "\n")
+		return
+	}
+
+	if len(invalidUsers) > 0 {
+		fmt.Fprintf(w, "**Invalid Users (%d):** ", len(invalidUsers))
+		for i, u := range invalidUsers {
+			if i > 0 {
+				fmt.Fprintf(w, ", ")
+			}
+			fmt.Fprintf(w, nameFormat, u.Name)
+		}
+		fmt.Fprintf(w, "\n\n")
+	}
+	if len(invalidFacilitators) > 0 {
+		fmt.Fprintf(w, "**Invalid Facilitators (%d):** ", len(invalidFacilitators))
+		for i, f := range invalidFacilitators {
+			if i > 0 {
+				fmt.Fprintf(w, ", ")
+			}
+			fmt.Fprintf(w, nameFormat, f.Name)
+		}
+		fmt.Fprintf(w, "\n\n")
+	}
+}
+
+// markdownTableCell escapes a value so it can't break out of a Markdown table cell
+func markdownTableCell(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "|", "\\|"), "\n", " ")
+}
+
+// writeUserFiltersMarkdown writes the applied user filters and the resulting processed set
+func writeUserFiltersMarkdown(w io.Writer, filters *UserFilterSummary) {
+	if filters == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "## Filters Applied\n\n")
+	if len(filters.OnlyUsers) > 0 {
+		fmt.Fprintf(w, "- **Only Users:** %s\n", strings.Join(filters.OnlyUsers, ", "))
+	}
+	if len(filters.ExcludeUsers) > 0 {
+		fmt.Fprintf(w, "- **Excluded Users:** %s\n", strings.Join(filters.ExcludeUsers, ", "))
+	}
+	fmt.Fprintf(w, "- **Processed Users (%d):** %s\n\n", len(filters.ProcessedUsers), strings.Join(filters.ProcessedUsers, ", "))
+}
+
+// writeApplyChangesMarkdown writes which org-level resources lab apply created and which
+// were already present
+func writeApplyChangesMarkdown(w io.Writer, org OrgReport) {
+	if len(org.Created) > 0 {
+		fmt.Fprintf(w, "- **Created:** %s\n", strings.Join(org.Created, ", "))
+	}
+	if len(org.AlreadyPresent) > 0 {
+		fmt.Fprintf(w, "- **Already Present:** %s\n", strings.Join(org.AlreadyPresent, ", "))
+	}
+}
+
+// writeFacilitatorRolesMarkdown writes the role each facilitator holds on an organization
+func writeFacilitatorRolesMarkdown(w io.Writer, roles []FacilitatorRole) {
+	if len(roles) == 0 {
+		return
+	}
+	entries := make([]string, 0, len(roles))
+	for _, r := range roles {
+		entries = append(entries, fmt.Sprintf("@%s (%s)", r.Login, r.Role))
+	}
+	fmt.Fprintf(w, "- **Facilitator Roles:** %s\n", strings.Join(entries, ", "))
+}
+
+// writeDiscoveredOrgsMarkdown lists the organizations --discover added to the deletion
+func writeDiscoveredOrgsMarkdown(w io.Writer, discovered []string) {
+	if len(discovered) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "## 🔎 Discovered Organizations (%d)\n\n", len(discovered))
+	fmt.Fprintf(w, "Found in the enterprise for this lab date but not listed in the users file:\n\n")
+	for _, org := range discovered {
+		fmt.Fprintf(w, "- `%s`\n", org)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// writeThroughputMarkdown writes the run's wall-clock duration and how many organizations
+// it processed per minute, for capacity planning
+func writeThroughputMarkdown(w io.Writer, orgCount int, durationMs int64) {
+	if durationMs <= 0 {
+		return
+	}
+	duration := (time.Duration(durationMs) * time.Millisecond).Round(time.Second)
+	perMinute := float64(orgCount) / (float64(durationMs) / float64(time.Minute/time.Millisecond))
+	fmt.Fprintf(w, "- **Duration:** %s (~%.1f orgs/min)\n", duration, perMinute)
+}
+
+// repoSourceNote marks imported repositories in repository lists
+func repoSourceNote(repo RepoReport) string {
+	switch repo.Source {
+	case util.RepoSourceImport:
+		return " - imported"
+	case util.RepoSourceTemplateImport:
+		return " - imported from the template host"
+	}
+	return ""
+}
+
+// writeUserOrgTableMarkdown lists each processed user with their organization login and
+// URL in a plain table meant for pasting into a spreadsheet. Failed organizations keep the
+// login they were meant to have and get no URL.
+func writeUserOrgTableMarkdown(w io.Writer, report *LabReport, webBaseURL string) {
+	if len(report.Organizations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "## User Organizations\n\n")
+	fmt.Fprintf(w, "| User | Organization | URL | Status |\n")
+	fmt.Fprintf(w, "|------|--------------|-----|--------|\n")
+	for _, org := range report.Organizations {
+		orgName := org.OrgName
+		if orgName == "" {
+			orgName = util.BuildOrgLogin(report.LabDate, org.User)
+		}
+		url, status := "-", "failed"
+		if org.Status == "success" {
+			status = "ready"
+			if webBaseURL != "" {
+				url = webBaseURL + "/" + orgName
+			}
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s |\n", org.User, orgName, url, status)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// writePacingMarkdown notes how often workers waited --wait-between-orgs, so a slow run
+// can be told apart from one throttled by the API
+func writePacingMarkdown(w io.Writer, wait string, waits int) {
+	if wait == "" {
+		return
+	}
+	fmt.Fprintf(w, "- **Pacing Waits:** %d (%s between orgs per worker)\n", waits, wait)
+}
+
+// writeOrgAttemptsMarkdown notes how many attempts organization creation took, when it
+// needed more than one, to tell flaky failures from hard ones
+func writeOrgAttemptsMarkdown(w io.Writer, attempts int) {
+	if attempts > 1 {
+		fmt.Fprintf(w, "- **Creation Attempts:** %d\n", attempts)
+	}
+}
+
+// writeFacilitatorsAdminsOnlyMarkdown notes that facilitators got no organizations of their own
+func writeFacilitatorsAdminsOnlyMarkdown(w io.Writer, adminsOnly bool) {
+	if !adminsOnly {
+		return
+	}
+	fmt.Fprintf(w, "_Facilitators were added as admins on student organizations only; no facilitator organizations were created._\n\n")
+}
+
+// writeSharedRepoMarkdown shows the --shared-repo and which student orgs were granted access
+func writeSharedRepoMarkdown(w io.Writer, shared *SharedRepoReport) {
+	if shared == nil {
+		return
+	}
+	fmt.Fprintf(w, "## 📚 Shared Repository\n\n")
+	fmt.Fprintf(w, "- **Template:** `%s`\n", shared.Template)
+	fmt.Fprintf(w, "- **Organization:** `%s`\n", shared.Org)
+	if shared.Error != "" {
+		fmt.Fprintf(w, "- **Error:** ❌ %s\n\n", shared.Error)
+		return
+	}
+	if shared.AlreadyPresent {
+		fmt.Fprintf(w, "- **Repository:** [%s](%s) (already present)\n\n", shared.URL, shared.URL)
+	} else {
+		fmt.Fprintf(w, "- **Repository:** [%s](%s)\n\n", shared.URL, shared.URL)
+	}
+	if len(shared.Grants) == 0 {
+		fmt.Fprintf(w, "_No student organizations were granted access._\n\n")
+		return
+	}
+	fmt.Fprintf(w, "| Organization | User | Read Access |\n")
+	fmt.Fprintf(w, "|--------------|------|-------------|\n")
+	for _, grant := range shared.Grants {
+		status := "✅ granted"
+		if grant.Error != "" {
+			status = "❌ " + grant.Error
+		} else if grant.Invited {
+			status = "✉️ invited"
+		}
+		fmt.Fprintf(w, "| `%s` | `@%s` | %s |\n", grant.OrgName, grant.User, status)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// writeInstallVerificationMarkdown shows the --verify-install result for an organization
+func writeInstallVerificationMarkdown(w io.Writer, verification *api.InstallVerification) {
+	if verification == nil {
+		return
+	}
+	if verification.Status == api.InstallVerified {
+		fmt.Fprintf(w, "- **App Installation:** ✅ verified (ID %d)\n", verification.InstallationID)
+		return
+	}
+	fmt.Fprintf(w, "- **App Installation:** ⚠️ %s: %s\n", verification.Status, verification.Detail)
+}
+
+// writeUnverifiedInstallsMarkdown lists the organizations whose app installation failed
+// --verify-install, since they are otherwise reported as successful
+func writeUnverifiedInstallsMarkdown(w io.Writer, organizations []OrgReport, heading string) {
+	var unverified []OrgReport
+	for _, org := range organizations {
+		if org.Installation != nil && org.Installation.Status != api.InstallVerified {
+			unverified = append(unverified, org)
+		}
+	}
+	if len(unverified) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s ⚠️ Unverified App Installations (%d)\n\n", heading, len(unverified))
+	fmt.Fprintf(w, "| Organization | Status | Detail |\n")
+	fmt.Fprintf(w, "|--------------|--------|--------|\n")
+	for _, org := range unverified {
+		fmt.Fprintf(w, "| `%s` | %s | %s |\n", org.OrgName, org.Installation.Status, org.Installation.Detail)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// writeFacilitatorTemplatesMarkdown lists the --facilitator-templates set used for
+// facilitators' organizations
+func writeFacilitatorTemplatesMarkdown(w io.Writer, templates []string) {
+	if templates == nil {
+		return
+	}
+	fmt.Fprintf(w, "_Facilitators' organizations received the facilitator template set (%d):_ ", len(templates))
+	if len(templates) == 0 {
+		fmt.Fprintf(w, "no repositories")
+	}
+	for i, template := range templates {
+		if i > 0 {
+			fmt.Fprintf(w, ", ")
+		}
+		fmt.Fprintf(w, "`%s`", template)
+	}
+	fmt.Fprintf(w, "\n\n")
+}
+
+// writeOrgsOnlyMarkdown notes that --orgs-only skipped repository creation
+func writeOrgsOnlyMarkdown(w io.Writer, orgsOnly bool) {
+	if !orgsOnly {
+		return
+	}
+	fmt.Fprintf(w, "_Orgs-only mode: organizations were created and configured, no repositories were attempted._\n\n")
+}
+
+// writeExcludedTemplatesMarkdown notes the templates skipped with --exclude-templates
+func writeExcludedTemplatesMarkdown(w io.Writer, excluded []string) {
+	if len(excluded) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "_Excluded for this run:_ ")
+	for i, template := range excluded {
+		if i > 0 {
+			fmt.Fprintf(w, ", ")
+		}
+		fmt.Fprintf(w, "`%s`", template)
+	}
+	fmt.Fprintf(w, "\n\n")
+}
+
+// writeEnterpriseInvitesMarkdown writes which users were already enterprise members and
+// which had to be invited
+func writeEnterpriseInvitesMarkdown(w io.Writer, invites *EnterpriseInviteSummary) {
+	if invites == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "## Enterprise Invitations\n\n")
+	fmt.Fprintf(w, "- **Already Members (%d):** %s\n", len(invites.AlreadyMembers), strings.Join(invites.AlreadyMembers, ", "))
+	fmt.Fprintf(w, "- **Invited (%d):** %s\n", len(invites.Invited), strings.Join(invites.Invited, ", "))
+	if len(invites.AlreadyInvited) > 0 {
+		fmt.Fprintf(w, "- **Invitation Already Pending (%d):** %s\n", len(invites.AlreadyInvited), strings.Join(invites.AlreadyInvited, ", "))
+	}
+	if len(invites.Failed) > 0 {
+		fmt.Fprintf(w, "- **Failed (%d):** %s\n", len(invites.Failed), strings.Join(invites.Failed, ", "))
+	}
+	if len(invites.Invited) > 0 || len(invites.AlreadyInvited) > 0 {
+		fmt.Fprintf(w, "\n> Invited users must accept their invitation before they can be added as organization admins.\n")
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// buildTemplateResults rolls up repository results per template, in the order templates
+// first appear, so a template failing across every org stands out
+func buildTemplateResults(orgs []OrgReport) []TemplateResult {
+	order := []string{}
+	results := make(map[string]*TemplateResult)
+	errorCounts := make(map[string]map[string]int)
+
+	for _, org := range orgs {
+		for _, repo := range org.Repositories {
+			result, ok := results[repo.Name]
+			if !ok {
+				result = &TemplateResult{Name: repo.Name}
+				results[repo.Name] = result
+				errorCounts[repo.Name] = make(map[string]int)
+				order = append(order, repo.Name)
+			}
+
+			if repo.Status == "success" {
+				result.SuccessCount++
+				continue
+			}
+			if repo.Status == "skipped" {
+				continue
+			}
+			result.FailureCount++
+			errorCounts[repo.Name][repo.Error]++
+			if errorCounts[repo.Name][repo.Error] > errorCounts[repo.Name][result.MostCommonError] {
+				result.MostCommonError = repo.Error
+			}
+		}
+	}
+
+	templateResults := make([]TemplateResult, 0, len(order))
+	for _, name := range order {
+		templateResults = append(templateResults, *results[name])
+	}
+	return templateResults
 }
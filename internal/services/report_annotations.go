@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// writeActionsAnnotations emits a warning annotation for each invalid user and each
+// organization that failed to provision. Does nothing outside GitHub Actions.
+func writeActionsAnnotations(report *LabReport) {
+	writeInvalidUserAnnotations(report.LabDate, report.InvalidUsers, report.InvalidFacilitators)
+	for _, org := range report.Organizations {
+		if org.Status == "failed" {
+			util.ActionsAnnotation(util.AnnotationWarning,
+				fmt.Sprintf("Organization failed (%s)", report.LabDate),
+				fmt.Sprintf("%s for @%s: %s", orgDisplayName(org.OrgName, org.User, report.LabDate), org.User, org.Error))
+		}
+	}
+}
+
+// writeDeleteActionsAnnotations emits a warning annotation for each invalid user and each
+// organization that failed to delete. Does nothing outside GitHub Actions.
+func writeDeleteActionsAnnotations(report *DeleteLabReport) {
+	writeInvalidUserAnnotations(report.LabDate, report.InvalidUsers, report.InvalidFacilitators)
+	for _, org := range report.Organizations {
+		if org.Status == "failed" {
+			util.ActionsAnnotation(util.AnnotationWarning,
+				fmt.Sprintf("Organization deletion failed (%s)", report.LabDate),
+				fmt.Sprintf("%s: %s", orgDisplayName(org.OrgName, org.User, report.LabDate), org.Error))
+		}
+	}
+}
+
+func writeInvalidUserAnnotations(labDate string, invalidUsers []api.InvalidUser, invalidFacilitators []api.InvalidUser) {
+	for _, user := range invalidUsers {
+		util.ActionsAnnotation(util.AnnotationWarning,
+			fmt.Sprintf("Invalid user skipped (%s)", labDate),
+			fmt.Sprintf("@%s: %s", user.Name, user.Reason))
+	}
+	for _, facilitator := range invalidFacilitators {
+		util.ActionsAnnotation(util.AnnotationWarning,
+			fmt.Sprintf("Invalid facilitator skipped (%s)", labDate),
+			fmt.Sprintf("@%s: %s", facilitator.Name, facilitator.Reason))
+	}
+}
+
+// orgDisplayName returns the org login, deriving it from the user when the org was never
+// created and its name wasn't recorded
+func orgDisplayName(orgName string, user string, labDate string) string {
+	if orgName != "" {
+		return orgName
+	}
+	return util.BuildOrgLogin(labDate, user)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// collapseSectionLines is the number of lines above which a report section is wrapped in
+// a collapsed <details> block when posted as a comment
+const collapseSectionLines = 25
+
+// postReportComment posts a formatted report as a comment on the target issue or PR
+func postReportComment(ctx context.Context, logger *slog.Logger, target util.IssueRef, body string) error {
+	if err := api.CreateIssueComment(ctx, logger, target.Owner, target.Repo, target.Number, body); err != nil {
+		return fmt.Errorf("failed to comment report on %s: %w", target, err)
+	}
+
+	fmt.Printf("  💬 Comment: posted to %s\n", target)
+	return nil
+}
+
+// formatReportComment collapses long report sections and truncates the result to fit
+// GitHub's comment size limit. fullReportHint, if set, tells readers of a truncated
+// comment where to find the whole report.
+func formatReportComment(markdown string, fullReportHint string) string {
+	sections := strings.Split(markdown, "\n## ")
+	var sb strings.Builder
+	sb.WriteString(sections[0])
+	for _, section := range sections[1:] {
+		heading, content, _ := strings.Cut(section, "\n")
+		if strings.Count(content, "\n") > collapseSectionLines {
+			fmt.Fprintf(&sb, "\n<details><summary><b>%s</b></summary>\n%s\n</details>\n", heading, content)
+		} else {
+			fmt.Fprintf(&sb, "\n## %s\n%s", heading, content)
+		}
+	}
+	body := sb.String()
+
+	if len(body) <= api.MaxIssueCommentLength {
+		return body
+	}
+
+	// Cut at a line boundary, leaving room for the note and any open <details> block
+	note := "\n\n</details>\n\n> ⚠️ Report truncated to fit the comment size limit."
+	if fullReportHint != "" {
+		note += " " + fullReportHint
+	}
+	note += "\n"
+	cut := body[:api.MaxIssueCommentLength-len(note)]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	if strings.Count(cut, "<details>") <= strings.Count(cut, "</details>") {
+		note = strings.Replace(note, "\n\n</details>", "", 1)
+	}
+	return cut + note
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MatrixEntry is one successfully provisioned organization in the GitHub Actions matrix
+type MatrixEntry struct {
+	Org  string `json:"org"`
+	User string `json:"user"`
+	URL  string `json:"url"`
+}
+
+// matrixEntries accumulates entries across every report written in this run, so a
+// multi-date run produces one matrix covering all dates
+var (
+	matrixMu      sync.Mutex
+	matrixEntries []MatrixEntry
+)
+
+// writeActionsMatrixOutput appends the report's successful organizations to the run's
+// matrix and writes it as the `matrix` step output. Actions uses the last value written
+// for an output name, so rewriting the cumulative matrix after each report is safe.
+// Does nothing outside GitHub Actions.
+func writeActionsMatrixOutput(report *LabReport, webBaseURL string) error {
+	outputPath := os.Getenv("GITHUB_OUTPUT")
+	if outputPath == "" {
+		return nil
+	}
+
+	matrixMu.Lock()
+	defer matrixMu.Unlock()
+
+	for _, org := range report.Organizations {
+		if org.Status != "success" {
+			continue
+		}
+		matrixEntries = append(matrixEntries, MatrixEntry{
+			Org:  org.OrgName,
+			User: org.User,
+			URL:  fmt.Sprintf("%s/%s", webBaseURL, org.OrgName),
+		})
+	}
+
+	include := matrixEntries
+	if include == nil {
+		include = []MatrixEntry{}
+	}
+	matrix, err := json.Marshal(map[string][]MatrixEntry{"include": include})
+	if err != nil {
+		return fmt.Errorf("failed to marshal matrix: %w", err)
+	}
+
+	file, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "matrix=%s\n", matrix)
+	return err
+}
+
+// webBaseURL derives the web UI URL from the API base URL: api.github.com becomes
+// github.com and a GHES /api/v3 suffix is dropped
+func webBaseURL(apiBaseURL string) string {
+	apiBaseURL = strings.TrimSuffix(apiBaseURL, "/")
+	if strings.HasPrefix(apiBaseURL, "https://api.") {
+		return "https://" + strings.TrimPrefix(apiBaseURL, "https://api.")
+	}
+	return strings.TrimSuffix(apiBaseURL, "/api/v3")
+}
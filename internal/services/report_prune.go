@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// reportFilePattern matches the timestamped report files the file sink writes, e.g.
+// lab-report-2025-11-07-20251107-093000.md, capturing the timestamp. Files written with
+// --no-timestamp don't match, so their age is unknown and they're never pruned.
+var reportFilePattern = regexp.MustCompile(`^(?:lab-report|lab-delete-report|lab-cohort-summary)-.+-(\d{8}-\d{6})\.(?:md|json)$`)
+
+// PrunedReport is a report file older than the retention window
+type PrunedReport struct {
+	Path        string    `json:"path"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Error is set when the file couldn't be deleted
+	Error string `json:"error,omitempty"`
+}
+
+// PruneResult lists the report files PruneReports deleted, or would delete with dryRun
+type PruneResult struct {
+	Pruned []PrunedReport
+	// Kept counts the timestamped report files inside the retention window
+	Kept int
+	// Skipped counts the other files in the directory, including untimestamped reports
+	Skipped int
+}
+
+// PruneReports deletes the report files in dir whose file name timestamp is older than
+// olderThan before now. Ages come from the file names rather than modification times, so
+// copying the directory doesn't reset them. With dryRun nothing is deleted. Failing to
+// delete a file is recorded on it and returned as an error once every file was tried.
+func PruneReports(dir string, olderThan time.Duration, now time.Time, dryRun bool) (*PruneResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+
+	cutoff := now.Add(-olderThan)
+	result := &PruneResult{}
+	failed := 0
+	for _, entry := range entries {
+		match := reportFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			result.Skipped++
+			continue
+		}
+		generatedAt, err := time.ParseInLocation(reportTimestampLayout, match[1], time.Local)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+		if !generatedAt.Before(cutoff) {
+			result.Kept++
+			continue
+		}
+
+		pruned := PrunedReport{Path: filepath.Join(dir, entry.Name()), GeneratedAt: generatedAt}
+		if !dryRun {
+			if err := os.Remove(pruned.Path); err != nil {
+				pruned.Error = err.Error()
+				failed++
+			}
+		}
+		result.Pruned = append(result.Pruned, pruned)
+	}
+
+	sort.Slice(result.Pruned, func(i, j int) bool {
+		return result.Pruned[i].GeneratedAt.Before(result.Pruned[j].GeneratedAt)
+	})
+	if failed > 0 {
+		return result, fmt.Errorf("failed to delete %d of %d report file(s)", failed, len(result.Pruned))
+	}
+	return result, nil
+}
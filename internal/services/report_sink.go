@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// ReportDocument is a finished report handed to every configured sink
+type ReportDocument struct {
+	// BaseName identifies the report, e.g. lab-report-2025-11-07. Sinks that write files
+	// add the timestamp and extension.
+	BaseName string
+	// Title and Summary are one-line descriptions used by chat notifications
+	Title   string
+	Summary string
+	// Markdown is the full rendered report
+	Markdown string
+	// Data is the structured report: *LabReport, *DeleteLabReport or []CohortDateSummary
+	Data any
+}
+
+// ReportSink delivers a finished report to one destination
+type ReportSink interface {
+	Name() string
+	Deliver(doc ReportDocument) error
+}
+
+// reportWebhookTimeout bounds each webhook and Slack delivery
+const reportWebhookTimeout = 30 * time.Second
+
+// newReportSinks builds the sinks selected with --report-sink. Without a selection in the
+// context, reports are written to files as before.
+func newReportSinks(ctx context.Context, outputDir string, noTimestamp bool) []ReportSink {
+	names, ok := ctx.Value(config.ReportSinksKey).([]string)
+	if !ok || len(names) == 0 {
+		names = []string{config.ReportSinkFile}
+	}
+
+	hasFileSink := false
+	for _, name := range names {
+		if name == config.ReportSinkFile {
+			hasFileSink = true
+		}
+	}
+
+	sinks := make([]ReportSink, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case config.ReportSinkFile:
+			sink := &fileSink{outputDir: outputDir, noTimestamp: noTimestamp}
+			if target, ok := ctx.Value(config.ReportUploadKey).(util.UploadTarget); ok {
+				sink.uploader = newReportUploader(target)
+			}
+			sinks = append(sinks, sink)
+		case config.ReportSinkStdout:
+			sinks = append(sinks, &stdoutSink{})
+		case config.ReportSinkWebhook:
+			url, _ := ctx.Value(config.ReportWebhookURLKey).(string)
+			sinks = append(sinks, &webhookSink{url: url})
+		case config.ReportSinkSlack:
+			url, _ := ctx.Value(config.ReportSlackWebhookURLKey).(string)
+			sinks = append(sinks, &slackSink{url: url})
+		case config.ReportSinkComment:
+			target, ok := ctx.Value(config.CommentOnKey).(util.IssueRef)
+			if !ok {
+				continue
+			}
+			hint := ""
+			if hasFileSink {
+				hint = fmt.Sprintf("The full report is in the `%s` directory.", outputDir)
+			}
+			sinks = append(sinks, &commentSink{ctx: ctx, target: target, fullReportHint: hint})
+		}
+	}
+	return sinks
+}
+
+// deliverReport sends the report to every sink. A failing sink doesn't stop the others;
+// all failures are returned together.
+func deliverReport(opts ReportOptions, doc ReportDocument) error {
+	sinks := opts.sinks
+	if sinks == nil {
+		sinks = []ReportSink{&fileSink{outputDir: opts.OutputDir, noTimestamp: opts.NoTimestamp}}
+	}
+
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Deliver(doc); err != nil {
+			errs = append(errs, fmt.Errorf("%s report sink: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fileSink writes the Markdown report and, for lab and deletion reports, its JSON form to
+// the reports directory and, with --report-upload, uploads the written files to object storage
+type fileSink struct {
+	outputDir   string
+	noTimestamp bool
+	uploader    ReportUploader
+}
+
+func (s *fileSink) Name() string { return config.ReportSinkFile }
+
+func (s *fileSink) Deliver(doc ReportDocument) error {
+	outputDir := s.outputDir
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	// Ensure output directory exists
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	mdPath := filepath.Join(outputDir, reportFileName(doc.BaseName, "md", s.noTimestamp))
+	if err := os.WriteFile(mdPath, []byte(doc.Markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write Markdown report file: %w", err)
+	}
+
+	// Share the Markdown file's timestamp so the pair is easy to match up
+	jsonPath := strings.TrimSuffix(mdPath, ".md") + ".json"
+	var err error
+	switch data := doc.Data.(type) {
+	case *LabReport:
+		err = generateJSONReport(data, jsonPath)
+	case *DeleteLabReport:
+		err = generateDeleteJSONReport(data, jsonPath)
+	default:
+		jsonPath = ""
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✅ %s generated successfully:\n", doc.Title)
+	fmt.Printf("  📝 Markdown: %s\n", mdPath)
+	paths := []string{mdPath}
+	if jsonPath != "" {
+		fmt.Printf("  🧾 JSON: %s\n", jsonPath)
+		paths = append(paths, jsonPath)
+	}
+
+	// The local files are kept either way, so a failed upload is an ordinary report failure
+	if s.uploader != nil {
+		for _, path := range paths {
+			location, err := s.uploader.Upload(path)
+			if err != nil {
+				return fmt.Errorf("failed to upload report: %w", err)
+			}
+			fmt.Printf("  ☁️  Uploaded: %s\n", location)
+		}
+	}
+	return nil
+}
+
+// stdoutSink prints the Markdown report to standard output
+type stdoutSink struct{}
+
+func (s *stdoutSink) Name() string { return config.ReportSinkStdout }
+
+func (s *stdoutSink) Deliver(doc ReportDocument) error {
+	_, err := fmt.Fprintf(os.Stdout, "\n%s\n", doc.Markdown)
+	return err
+}
+
+// webhookSink POSTs the report as JSON to a generic webhook
+type webhookSink struct {
+	url string
+}
+
+func (s *webhookSink) Name() string { return config.ReportSinkWebhook }
+
+func (s *webhookSink) Deliver(doc ReportDocument) error {
+	return postReportJSON(s.url, map[string]any{
+		"name":     doc.BaseName,
+		"title":    doc.Title,
+		"summary":  doc.Summary,
+		"markdown": doc.Markdown,
+		"report":   doc.Data,
+	})
+}
+
+// slackSink posts the report's title and summary to a Slack incoming webhook. Slack
+// doesn't render GitHub Markdown, so the full report isn't sent.
+type slackSink struct {
+	url string
+}
+
+func (s *slackSink) Name() string { return config.ReportSinkSlack }
+
+func (s *slackSink) Deliver(doc ReportDocument) error {
+	return postReportJSON(s.url, map[string]any{
+		"text": fmt.Sprintf("*%s*\n%s", doc.Title, doc.Summary),
+	})
+}
+
+// postReportJSON POSTs payload to url and expects a 2xx response
+func postReportJSON(url string, payload any) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// commentSink posts the report as a comment on the --comment-on issue or PR
+type commentSink struct {
+	ctx            context.Context
+	target         util.IssueRef
+	fullReportHint string
+}
+
+func (s *commentSink) Name() string { return config.ReportSinkComment }
+
+func (s *commentSink) Deliver(doc ReportDocument) error {
+	logger, ok := s.ctx.Value(config.LoggerKey).(*slog.Logger)
+	if !ok || logger == nil {
+		logger = slog.Default()
+	}
+	return postReportComment(s.ctx, logger, s.target, formatReportComment(doc.Markdown, s.fullReportHint))
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// reportUploadTimeout bounds each report upload
+const reportUploadTimeout = 2 * time.Minute
+
+// ReportUploader copies a report file written locally to object storage and returns the
+// location it was uploaded to
+type ReportUploader interface {
+	Upload(localPath string) (string, error)
+}
+
+// newReportUploader returns the uploader for the --report-upload target. Uploads go
+// through the provider's CLI (aws, gcloud or az), which CI runners already authenticate,
+// so the tool doesn't depend on the cloud SDKs.
+func newReportUploader(target util.UploadTarget) ReportUploader {
+	return &cliUploader{target: target}
+}
+
+// cliUploader uploads with the cloud provider's command line tool
+type cliUploader struct {
+	target util.UploadTarget
+}
+
+// command returns the CLI invocation uploading localPath and the resulting location
+func (u *cliUploader) command(localPath string) (string, []string, string) {
+	objectName := u.target.ObjectName(filepath.Base(localPath))
+	switch u.target.Scheme {
+	case util.UploadSchemeS3:
+		dest := fmt.Sprintf("s3://%s/%s", u.target.Bucket, objectName)
+		return "aws", []string{"s3", "cp", localPath, dest, "--only-show-errors"}, dest
+	case util.UploadSchemeGCS:
+		dest := fmt.Sprintf("gs://%s/%s", u.target.Bucket, objectName)
+		return "gcloud", []string{"storage", "cp", localPath, dest}, dest
+	default:
+		account, container, _ := strings.Cut(u.target.Bucket, "/")
+		dest := fmt.Sprintf("az://%s/%s/%s", account, container, objectName)
+		return "az", []string{"storage", "blob", "upload",
+			"--account-name", account,
+			"--container-name", container,
+			"--name", objectName,
+			"--file", localPath,
+			"--auth-mode", "login",
+			"--overwrite",
+			"--only-show-errors"}, dest
+	}
+}
+
+func (u *cliUploader) Upload(localPath string) (string, error) {
+	name, args, dest := u.command(localPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportUploadTimeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s upload to %s failed: %w: %s", name, dest, err, strings.TrimSpace(output.String()))
+	}
+	return dest, nil
+}
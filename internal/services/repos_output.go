@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// createdRepos accumulates the repositories created across the run, so a multi-date
+// lab create writes one --repos-output file covering all dates
+var (
+	createdReposMu sync.Mutex
+	createdRepos   []util.CreatedRepo
+)
+
+// recordCreatedRepos adds repositories created by this run to the --repos-output file and
+// rewrites it. Does nothing when --repos-output isn't set.
+func recordCreatedRepos(ctx context.Context, logger *slog.Logger, repos []util.CreatedRepo) error {
+	path, _ := ctx.Value(config.ReposOutputKey).(string)
+	if path == "" {
+		return nil
+	}
+
+	createdReposMu.Lock()
+	defer createdReposMu.Unlock()
+
+	createdRepos = append(createdRepos, repos...)
+	if err := util.WriteCreatedReposFile(path, createdRepos); err != nil {
+		logger.Error("Failed to write created repos file", slog.String("file", path), slog.Any("error", err))
+		return err
+	}
+	logger.Info("Wrote created repos file",
+		slog.String("file", path),
+		slog.Int("count", len(createdRepos)))
+	return nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+)
+
+// OutcomeCounts counts resources by outcome
+type OutcomeCounts struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// ReportMetrics is the duration of one lab create or delete report
+type ReportMetrics struct {
+	Kind       string `json:"kind"` // "create" or "delete"
+	LabDate    string `json:"lab_date"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// RunMetrics is the --export-metrics-json file. Org and repo counts come from the lab
+// reports the run generated; API usage comes from the API call summary counters.
+type RunMetrics struct {
+	GeneratedAt  time.Time          `json:"generated_at"`
+	Command      string             `json:"command"`
+	StartedAt    time.Time          `json:"started_at"`
+	DurationMs   int64              `json:"duration_ms"`
+	Failed       bool               `json:"failed"`
+	OrgsCreated  OutcomeCounts      `json:"orgs_created"`
+	ReposCreated OutcomeCounts      `json:"repos_created"`
+	OrgsDeleted  OutcomeCounts      `json:"orgs_deleted"`
+	Reports      []ReportMetrics    `json:"reports"`
+	API          api.APICallMetrics `json:"api"`
+}
+
+// runMetrics accumulates report counts across the run, so a multi-date lab create is
+// covered by one metrics file
+var (
+	runMetricsMu sync.Mutex
+	runMetrics   RunMetrics
+)
+
+// recordLabReportMetrics adds a lab create report's org and repo outcomes to the run metrics.
+// Users skipped during validation count as skipped organizations.
+func recordLabReportMetrics(report *LabReport) {
+	runMetricsMu.Lock()
+	defer runMetricsMu.Unlock()
+
+	for _, org := range report.Organizations {
+		if org.Status == "success" {
+			runMetrics.OrgsCreated.Succeeded++
+		} else {
+			runMetrics.OrgsCreated.Failed++
+		}
+		for _, repo := range org.Repositories {
+			switch repo.Status {
+			case "success":
+				runMetrics.ReposCreated.Succeeded++
+			case "skipped":
+				runMetrics.ReposCreated.Skipped++
+			default:
+				runMetrics.ReposCreated.Failed++
+			}
+		}
+	}
+	runMetrics.OrgsCreated.Skipped += len(report.InvalidUsers) + len(report.InvalidFacilitators)
+	runMetrics.Reports = append(runMetrics.Reports, ReportMetrics{Kind: "create", LabDate: report.LabDate, DurationMs: report.DurationMs})
+}
+
+// recordDeleteReportMetrics adds a deletion report's org outcomes to the run metrics.
+// Organizations kept with --preserve-users or deleted by an earlier run count as skipped.
+func recordDeleteReportMetrics(report *DeleteLabReport) {
+	runMetricsMu.Lock()
+	defer runMetricsMu.Unlock()
+
+	for _, org := range report.Organizations {
+		switch org.Status {
+		case "success":
+			runMetrics.OrgsDeleted.Succeeded++
+		case "preserved", "resumed":
+			runMetrics.OrgsDeleted.Skipped++
+		default:
+			runMetrics.OrgsDeleted.Failed++
+		}
+	}
+	runMetrics.OrgsDeleted.Skipped += len(report.InvalidUsers) + len(report.InvalidFacilitators)
+	runMetrics.Reports = append(runMetrics.Reports, ReportMetrics{Kind: "delete", LabDate: report.LabDate, DurationMs: report.DurationMs})
+}
+
+// WriteRunMetricsFile writes the run's metrics to path as indented JSON, replacing any
+// existing file. failed is set when the command returned an error.
+func WriteRunMetricsFile(path string, command string, startedAt time.Time, failed bool) error {
+	runMetricsMu.Lock()
+	metrics := runMetrics
+	runMetricsMu.Unlock()
+
+	now := time.Now()
+	metrics.GeneratedAt = now
+	metrics.Command = command
+	metrics.StartedAt = startedAt
+	metrics.DurationMs = now.Sub(startedAt).Milliseconds()
+	metrics.Failed = failed
+	metrics.API = api.SnapshotAPICallMetrics()
+	if metrics.Reports == nil {
+		metrics.Reports = []ReportMetrics{}
+	}
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run metrics: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	api "github.com/s-samadi/ghas-lab-builder/internal/github"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// SharedRepoReport describes the --shared-repo created once for the whole lab
+type SharedRepoReport struct {
+	Template string `json:"template"`
+	Org      string `json:"org"`
+	URL      string `json:"url,omitempty"`
+	// AlreadyPresent is set when the repository existed before this run and was reused
+	AlreadyPresent bool `json:"already_present,omitempty"`
+	// Error is set when the repository couldn't be created; no access was granted then
+	Error  string            `json:"error,omitempty"`
+	Grants []SharedRepoGrant `json:"grants,omitempty"`
+}
+
+// SharedRepoGrant records read access to the shared repository for one student org
+type SharedRepoGrant struct {
+	OrgName string `json:"org_name"`
+	User    string `json:"user"`
+	// Invited is set when GitHub sent the user an invitation they must accept
+	Invited bool   `json:"invited,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// sharedRepoPermission is the access students get to the shared repository
+const sharedRepoPermission = "pull"
+
+// provisionSharedRepo creates the --shared-repo once in the shared org, or reuses it if it
+// already exists, and grants the user of every successfully provisioned student org read
+// access. GitHub can't grant one organization access to another organization's
+// repository, so access is granted to each org's user as a repository collaborator.
+// Returns nil when --shared-repo isn't set.
+func provisionSharedRepo(ctx context.Context, logger *slog.Logger, report *LabReport) *SharedRepoReport {
+	template, _ := ctx.Value(config.SharedRepoKey).(string)
+	if template == "" {
+		return nil
+	}
+
+	orgName, _ := ctx.Value(config.SharedRepoOrgKey).(string)
+	if orgName == "" && len(report.Facilitators) > 0 {
+		orgName = util.BuildOrgLogin(report.LabDate, report.Facilitators[0])
+	}
+	result := &SharedRepoReport{Template: template, Org: orgName}
+	if orgName == "" {
+		result.Error = "no organization for the shared repository: set --shared-repo-org"
+		return result
+	}
+
+	logger.Info("Provisioning shared repository",
+		slog.String("template", template),
+		slog.String("org", orgName))
+
+	repo, err := ensureSharedRepo(ctx, logger, result, template, report.LabDate)
+	if err != nil {
+		logger.Error("Failed to provision shared repository",
+			slog.String("template", template),
+			slog.String("org", orgName),
+			slog.Any("error", err))
+		result.Error = err.Error()
+		return result
+	}
+	result.URL = repo.HTMLURL
+
+	organization := &api.Organization{Login: orgName}
+	facilitators := make(map[string]bool, len(report.Facilitators))
+	for _, facilitator := range report.Facilitators {
+		facilitators[facilitator] = true
+	}
+	for _, org := range report.Organizations {
+		// Facilitators are admins on every lab org and need no grant
+		if org.Status != "success" || facilitators[org.User] || strings.EqualFold(org.OrgName, orgName) {
+			continue
+		}
+		grant := SharedRepoGrant{OrgName: org.OrgName, User: org.User}
+		grant.Invited, err = organization.AddCollaborator(ctx, logger, repo.Name, org.User, sharedRepoPermission)
+		if err != nil {
+			grant.Error = err.Error()
+		}
+		result.Grants = append(result.Grants, grant)
+	}
+	return result
+}
+
+// ensureSharedRepo returns the shared repository, creating it from the template unless a
+// previous run already did
+func ensureSharedRepo(ctx context.Context, logger *slog.Logger, result *SharedRepoReport, template string, labDate string) (*api.Repository, error) {
+	parts := strings.Split(template, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid shared repo %q, expected owner/repo", template)
+	}
+
+	existing, err := api.GetRepository(ctx, logger, result.Org, parts[1])
+	if err == nil {
+		logger.Info("Shared repository already exists, reusing it", slog.String("repo", existing.FullName))
+		result.AlreadyPresent = true
+		return existing, nil
+	}
+	if !errors.Is(err, api.ErrRepositoryNotFound) {
+		return nil, fmt.Errorf("failed to check for existing shared repository: %w", err)
+	}
+
+	organization := &api.Organization{Login: result.Org}
+	if imported, ok := crossHostImportConfig(ctx, util.RepoConfig{Template: template}); ok {
+		return importRepo(ctx, logger, organization, imported)
+	}
+	return organization.CreateRepoFromTemplate(ctx, logger, template, api.TemplateRepoOptions{
+		Private: true,
+		Marker:  util.LabRepoMarker(labDate),
+	})
+}
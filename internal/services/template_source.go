@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+	"github.com/s-samadi/ghas-lab-builder/internal/util"
+)
+
+// crossHostTemplateURL returns the clone URL of template on the --template-base-url host,
+// or "" when templates live on the target host. The generate endpoint only accepts
+// templates on its own instance, so such templates have to be imported instead.
+func crossHostTemplateURL(ctx context.Context, template string) string {
+	templateBaseURL, _ := ctx.Value(config.TemplateBaseURLKey).(string)
+	baseURL, _ := ctx.Value(config.BaseURLKey).(string)
+	if templateBaseURL == "" || strings.EqualFold(templateBaseURL, baseURL) {
+		return ""
+	}
+	return webBaseURL(templateBaseURL) + "/" + template + ".git"
+}
+
+// repoSource returns how the repository will be created: RepoSourceTemplate,
+// RepoSourceImport, or RepoSourceTemplateImport for a template on another host
+func repoSource(ctx context.Context, repoConfig util.RepoConfig) string {
+	if repoConfig.Source() == util.RepoSourceTemplate && crossHostTemplateURL(ctx, repoConfig.Template) != "" {
+		return util.RepoSourceTemplateImport
+	}
+	return repoConfig.Source()
+}
+
+// crossHostImportConfig turns a template config into an import of the template's git
+// repository from the --template-base-url host. ok is false when the template is on the
+// target host and can be generated as usual.
+func crossHostImportConfig(ctx context.Context, repoConfig util.RepoConfig) (util.RepoConfig, bool) {
+	if repoConfig.Source() != util.RepoSourceTemplate {
+		return repoConfig, false
+	}
+	sourceURL := crossHostTemplateURL(ctx, repoConfig.Template)
+	if sourceURL == "" {
+		return repoConfig, false
+	}
+	imported := repoConfig
+	imported.Name = repoConfig.RepoName()
+	imported.Import = sourceURL
+	imported.Template = ""
+	return imported, true
+}
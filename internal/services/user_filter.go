@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/s-samadi/ghas-lab-builder/internal/config"
+)
+
+// UserFilterSummary records the --only-users/--exclude-users filters applied to a run
+type UserFilterSummary struct {
+	OnlyUsers      []string `json:"only_users,omitempty"`
+	ExcludeUsers   []string `json:"exclude_users,omitempty"`
+	ProcessedUsers []string `json:"processed_users"`
+}
+
+// userFilter restricts which users from the users file are processed
+type userFilter struct {
+	only    map[string]bool
+	exclude map[string]bool
+	summary *UserFilterSummary
+}
+
+// newUserFilterFromContext builds a filter from the only/exclude lists stored in the
+// context. It returns nil if no filters were requested.
+func newUserFilterFromContext(ctx context.Context) *userFilter {
+	onlyUsers, _ := ctx.Value(config.OnlyUsersKey).([]string)
+	excludeUsers, _ := ctx.Value(config.ExcludeUsersKey).([]string)
+	if len(onlyUsers) == 0 && len(excludeUsers) == 0 {
+		return nil
+	}
+
+	f := &userFilter{
+		only:    make(map[string]bool, len(onlyUsers)),
+		exclude: make(map[string]bool, len(excludeUsers)),
+		summary: &UserFilterSummary{
+			OnlyUsers:    onlyUsers,
+			ExcludeUsers: excludeUsers,
+		},
+	}
+	for _, u := range onlyUsers {
+		f.only[u] = true
+	}
+	for _, u := range excludeUsers {
+		f.exclude[u] = true
+	}
+	return f
+}
+
+// allows reports whether the user passes the filter
+func (f *userFilter) allows(user string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.only) > 0 && !f.only[user] {
+		return false
+	}
+	return !f.exclude[user]
+}
+
+// apply returns the users that pass the filter, logging what was removed
+func (f *userFilter) apply(logger *slog.Logger, users []string) []string {
+	if f == nil {
+		return users
+	}
+
+	filtered := make([]string, 0, len(users))
+	skipped := []string{}
+	for _, user := range users {
+		if f.allows(user) {
+			filtered = append(filtered, user)
+		} else {
+			skipped = append(skipped, user)
+		}
+	}
+
+	logger.Info("Applied user filters",
+		slog.Any("only_users", f.summary.OnlyUsers),
+		slog.Any("exclude_users", f.summary.ExcludeUsers),
+		slog.Int("kept", len(filtered)),
+		slog.Int("skipped", len(skipped)))
+
+	return filtered
+}
+
+// report returns the filter summary for the report with the final processed set,
+// or nil if no filters were applied
+func (f *userFilter) report(processed []string) *UserFilterSummary {
+	if f == nil {
+		return nil
+	}
+	f.summary.ProcessedUsers = processed
+	return f.summary
+}
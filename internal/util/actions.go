@@ -0,0 +1,39 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Annotation levels for GitHub Actions workflow commands
+const (
+	AnnotationWarning = "warning"
+	AnnotationError   = "error"
+)
+
+// InGitHubActions reports whether the process runs in a GitHub Actions job
+func InGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// ActionsAnnotation prints a ::warning:: or ::error:: workflow command so the message shows
+// inline in the workflow run. Does nothing outside GitHub Actions, so local runs don't
+// print the workflow command syntax.
+func ActionsAnnotation(level string, title string, message string) {
+	if !InGitHubActions() {
+		return
+	}
+	fmt.Fprintf(os.Stdout, "::%s title=%s::%s\n", level, escapeAnnotationProperty(title), escapeAnnotationData(message))
+}
+
+// escapeAnnotationData escapes a workflow command's message so multi-line text stays one command
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a workflow command property value, which additionally
+// can't contain the ':' and ',' separators
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
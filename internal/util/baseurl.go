@@ -0,0 +1,29 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NormalizeBaseURL validates a GitHub API base URL and returns it without trailing
+// slashes, since API paths are appended to it with a leading slash. The URL must be
+// absolute with an http or https scheme, e.g. https://ghes.example.com/api/v3.
+func NormalizeBaseURL(raw string) (string, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+
+	u, err := url.Parse(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid URL: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%q must start with http:// or https://", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%q has no host", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%q must not have a query string or fragment", raw)
+	}
+	return trimmed, nil
+}
@@ -0,0 +1,46 @@
+package util
+
+import "testing"
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "github.com", raw: "https://api.github.com", want: "https://api.github.com"},
+		{name: "trailing slash", raw: "https://api.github.com/", want: "https://api.github.com"},
+		{name: "several trailing slashes", raw: "https://api.github.com///", want: "https://api.github.com"},
+		{name: "surrounding whitespace", raw: "  https://api.github.com/ ", want: "https://api.github.com"},
+		{name: "ghes api/v3", raw: "https://ghes.example.com/api/v3", want: "https://ghes.example.com/api/v3"},
+		{name: "ghes api/v3 trailing slash", raw: "https://ghes.example.com/api/v3/", want: "https://ghes.example.com/api/v3"},
+		{name: "http with port", raw: "http://localhost:8080/api/v3", want: "http://localhost:8080/api/v3"},
+		{name: "no scheme", raw: "api.github.com", wantErr: true},
+		{name: "no scheme with path", raw: "ghes.example.com/api/v3", wantErr: true},
+		{name: "unsupported scheme", raw: "ftp://api.github.com", wantErr: true},
+		{name: "no host", raw: "https://", wantErr: true},
+		{name: "invalid url", raw: "https://api.github.com/%zz", wantErr: true},
+		{name: "query string", raw: "https://api.github.com?x=1", wantErr: true},
+		{name: "fragment", raw: "https://api.github.com#top", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeBaseURL(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeBaseURL(%q) = %q, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeBaseURL(%q) error = %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeBaseURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// BranchProtection is the protection --branch-protection-template applies to the default
+// branch of every lab repository. Fields follow the update branch protection API.
+type BranchProtection struct {
+	// RequiredStatusChecks requires these checks to pass before merging; null disables them
+	RequiredStatusChecks *RequiredStatusChecks `json:"required_status_checks"`
+	EnforceAdmins        bool                  `json:"enforce_admins"`
+	// RequiredPullRequestReviews requires pull requests with approvals; null allows pushes
+	RequiredPullRequestReviews     *RequiredPullRequestReviews `json:"required_pull_request_reviews"`
+	RequiredLinearHistory          bool                        `json:"required_linear_history,omitempty"`
+	AllowForcePushes               bool                        `json:"allow_force_pushes,omitempty"`
+	AllowDeletions                 bool                        `json:"allow_deletions,omitempty"`
+	RequiredConversationResolution bool                        `json:"required_conversation_resolution,omitempty"`
+}
+
+// RequiredStatusChecks lists the status checks that must pass on the protected branch
+type RequiredStatusChecks struct {
+	// Strict requires branches to be up to date with the protected branch before merging
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+}
+
+// RequiredPullRequestReviews configures the reviews pull requests need before merging
+type RequiredPullRequestReviews struct {
+	RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+	DismissStaleReviews          bool `json:"dismiss_stale_reviews,omitempty"`
+	RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews,omitempty"`
+	RequireLastPushApproval      bool `json:"require_last_push_approval,omitempty"`
+}
+
+// Validate checks the values GitHub would otherwise reject for every repository
+func (p *BranchProtection) Validate() error {
+	if p.RequiredStatusChecks != nil && p.RequiredStatusChecks.Contexts == nil {
+		p.RequiredStatusChecks.Contexts = []string{}
+	}
+	if reviews := p.RequiredPullRequestReviews; reviews != nil {
+		if reviews.RequiredApprovingReviewCount < 0 || reviews.RequiredApprovingReviewCount > 6 {
+			return fmt.Errorf("required_pull_request_reviews: required_approving_review_count must be between 0 and 6, got %d", reviews.RequiredApprovingReviewCount)
+		}
+	}
+	return nil
+}
+
+// LoadBranchProtection reads and validates a branch protection template file
+func LoadBranchProtection(path string) (*BranchProtection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var protection BranchProtection
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&protection); err != nil {
+		fields := jsonFieldNames(reflect.TypeOf(BranchProtection{}))
+		fields = append(fields, jsonFieldNames(reflect.TypeOf(RequiredStatusChecks{}))...)
+		fields = append(fields, jsonFieldNames(reflect.TypeOf(RequiredPullRequestReviews{}))...)
+		err = describeJSONError(data, 0, data, err, fields)
+		return nil, fmt.Errorf("invalid branch protection template %s: %w", path, err)
+	}
+	if err := protection.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid branch protection template %s: %w", path, err)
+	}
+	return &protection, nil
+}
+
+// CheckBranchProtectionFile confirms the branch protection template exists, parses and validates
+func CheckBranchProtectionFile(path string) error {
+	_, err := LoadBranchProtection(path)
+	return err
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CreatedRepo identifies a repository created by lab create or repo create
+type CreatedRepo struct {
+	Org  string `json:"org"`
+	Repo string `json:"repo"`
+	// Template is the template (owner/repo) or import URL the repository was created from
+	Template string `json:"template"`
+}
+
+// CreatedReposFile is the --repos-output file listing exactly the repositories a run created
+type CreatedReposFile struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Repos       []CreatedRepo `json:"repos"`
+}
+
+// WriteCreatedReposFile writes the created repositories to path as indented JSON,
+// replacing any existing file
+func WriteCreatedReposFile(path string, repos []CreatedRepo) error {
+	if repos == nil {
+		repos = []CreatedRepo{}
+	}
+	data, err := json.MarshalIndent(CreatedReposFile{GeneratedAt: time.Now(), Repos: repos}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal created repos: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write created repos file %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadCreatedReposFile reads a file written with --repos-output
+func LoadCreatedReposFile(path string) ([]CreatedRepo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file CreatedReposFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		err = describeJSONError(data, 0, data, err, []string{"generated_at", "repos", "org", "repo", "template"})
+		return nil, fmt.Errorf("invalid created repos file %s: %w", path, err)
+	}
+	for i, repo := range file.Repos {
+		if repo.Org == "" || repo.Repo == "" {
+			return nil, fmt.Errorf("invalid created repos file %s: repos[%d]: org and repo are required", path, i)
+		}
+	}
+	return file.Repos, nil
+}
+
+// CheckCreatedReposFile confirms the created repos file exists and parses
+func CheckCreatedReposFile(path string) error {
+	_, err := LoadCreatedReposFile(path)
+	return err
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// HookVars are the values available to {{...}} expressions in a hook command
+type HookVars struct {
+	User string
+	Date string
+	Org  string
+	// URL is the organization's web URL
+	URL string
+}
+
+// HookCommand is a local command run per user or organization, such as --pre-hook. It is
+// split into arguments on whitespace before expansion and run without a shell, so user
+// values can't inject shell syntax; quoting isn't supported.
+type HookCommand struct {
+	Raw  string
+	args []*template.Template
+}
+
+func (h HookCommand) String() string {
+	return h.Raw
+}
+
+// ParseHookCommand parses a hook command, rejecting bad template syntax or unknown
+// variables up front rather than on the first user
+func ParseHookCommand(raw string) (HookCommand, error) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return HookCommand{}, fmt.Errorf("hook command is empty")
+	}
+
+	hook := HookCommand{Raw: raw, args: make([]*template.Template, 0, len(fields))}
+	for _, field := range fields {
+		tmpl, err := template.New("hook").Option("missingkey=error").Parse(field)
+		if err != nil {
+			return HookCommand{}, fmt.Errorf("invalid template syntax in %q: %w", field, err)
+		}
+		hook.args = append(hook.args, tmpl)
+	}
+
+	if _, err := hook.Args(HookVars{User: "user", Date: "date", Org: "org", URL: "url"}); err != nil {
+		return HookCommand{}, err
+	}
+	return hook, nil
+}
+
+// Args expands the command's arguments with vars. The first argument is the program.
+func (h HookCommand) Args(vars HookVars) ([]string, error) {
+	args := make([]string, 0, len(h.args))
+	for _, tmpl := range h.args {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, vars); err != nil {
+			return nil, fmt.Errorf("failed to expand hook command %q (available variables: {{.User}}, {{.Date}}, {{.Org}}, {{.URL}}): %w", h.Raw, err)
+		}
+		args = append(args, sb.String())
+	}
+	return args, nil
+}
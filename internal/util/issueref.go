@@ -0,0 +1,35 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IssueRef identifies an issue or pull request as owner/repo#number
+type IssueRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// ParseIssueRef parses an "owner/repo#number" reference
+func ParseIssueRef(value string) (IssueRef, error) {
+	repoPart, numberPart, ok := strings.Cut(value, "#")
+	if !ok {
+		return IssueRef{}, fmt.Errorf("invalid issue reference %q: expected owner/repo#number", value)
+	}
+	owner, repo, ok := strings.Cut(repoPart, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return IssueRef{}, fmt.Errorf("invalid issue reference %q: expected owner/repo#number", value)
+	}
+	number, err := strconv.Atoi(numberPart)
+	if err != nil || number <= 0 {
+		return IssueRef{}, fmt.Errorf("invalid issue reference %q: %q is not an issue number", value, numberPart)
+	}
+	return IssueRef{Owner: owner, Repo: repo, Number: number}, nil
+}
@@ -0,0 +1,141 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var unknownFieldPattern = regexp.MustCompile(`^json: unknown field "(.*)"$`)
+
+// describeJSONError rewrites a decoding error of data with the line and column it occurred
+// at and, for unknown fields, the closest accepted field name. base is the offset of data
+// within the whole file, so errors in a nested value are positioned in the file.
+func describeJSONError(file []byte, base int64, data []byte, err error, fields []string) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%s: %w", position(file, base+syntaxErr.Offset-1), err)
+	case errors.As(err, &typeErr):
+		// The offset is just past the offending value, so point at its key when possible
+		field := typeErr.Field[strings.LastIndex(typeErr.Field, ".")+1:]
+		offset := keyOffset(data, field)
+		if offset < 0 {
+			offset = typeErr.Offset - 1
+		}
+		return fmt.Errorf("%s: field %q must be %s, got %s", position(file, base+offset), field, jsonTypeName(typeErr.Type), typeErr.Value)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%s: unexpected end of file, check for a missing closing bracket or brace", position(file, int64(len(file))))
+	}
+
+	if m := unknownFieldPattern.FindStringSubmatch(err.Error()); m != nil {
+		field := m[1]
+		msg := fmt.Sprintf("unknown field %q", field)
+		if suggestion := closestField(field, fields); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		if offset := keyOffset(data, field); offset >= 0 {
+			return fmt.Errorf("%s: %s", position(file, base+offset), msg)
+		}
+		return errors.New(msg)
+	}
+
+	return err
+}
+
+// position formats a byte offset in file as a 1-based line and column
+func position(file []byte, offset int64) string {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(file)) {
+		offset = int64(len(file))
+	}
+	before := file[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// keyOffset returns the offset of the first object key named field in data, or -1
+func keyOffset(data []byte, field string) int64 {
+	key := regexp.MustCompile(regexp.QuoteMeta(`"`+field+`"`) + `\s*:`)
+	if loc := key.FindIndex(data); loc != nil {
+		return int64(loc[0])
+	}
+	return -1
+}
+
+// closestField returns the accepted field nearest to field, or "" when none is close
+// enough to be a likely typo. An abbreviation of a field, like "desc", also matches.
+func closestField(field string, fields []string) string {
+	field = strings.ToLower(field)
+	best, bestDistance := "", 3
+	for _, candidate := range fields {
+		if len(field) >= 3 && strings.HasPrefix(candidate, field) {
+			return candidate
+		}
+		if d := editDistance(field, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// jsonTypeName describes a Go type by the JSON value it's decoded from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return "a number"
+	}
+}
+
+// jsonFieldNames returns the JSON names of the fields of struct type t
+func jsonFieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -1,14 +1,109 @@
 package util
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"os"
+	"reflect"
+	"strings"
+)
+
+// Repository content sources returned by RepoConfig.Source
+const (
+	RepoSourceTemplate = "template"
+	RepoSourceImport   = "import"
+	// RepoSourceTemplateImport is a template on another host (--template-base-url),
+	// imported with the source imports API since the generate endpoint can't cross hosts
+	RepoSourceTemplateImport = "template-import"
 )
 
 // RepoConfig represents a repository configuration
 type RepoConfig struct {
-	Template           string `json:"template"`
+	Template string `json:"template,omitempty"`
+	// Import is the clone URL of a plain git repository to import instead of generating
+	// the repository from Template
+	Import             string `json:"import,omitempty"`
 	IncludeAllBranches bool   `json:"include_all_branches"`
+	// DefaultBranch renames the created repository's default branch when set
+	DefaultBranch string `json:"default_branch,omitempty"`
+	// Private controls the created repository's visibility. Defaults to true when omitted.
+	Private *bool `json:"private,omitempty"`
+	// Topics replaces the created repository's topics when set
+	Topics []string `json:"topics,omitempty"`
+	// Name overrides the created repository's name, which defaults to the template's name
+	Name string `json:"name,omitempty"`
+	// Description overrides the created repository's description
+	Description string `json:"description,omitempty"`
+	// Settings are applied with a single update once the repository is created
+	Settings *RepoSettings `json:"settings,omitempty"`
+}
+
+// RepoSettings are repository settings changed after creation, e.g. to disable the issues,
+// wiki and projects tabs. Unset fields are left as the template or import created them.
+type RepoSettings struct {
+	HasIssues   *bool   `json:"has_issues,omitempty"`
+	HasWiki     *bool   `json:"has_wiki,omitempty"`
+	HasProjects *bool   `json:"has_projects,omitempty"`
+	Homepage    *string `json:"homepage,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// Payload returns the set fields keyed by their API names
+func (s RepoSettings) Payload() map[string]interface{} {
+	payload := make(map[string]interface{})
+	if s.HasIssues != nil {
+		payload["has_issues"] = *s.HasIssues
+	}
+	if s.HasWiki != nil {
+		payload["has_wiki"] = *s.HasWiki
+	}
+	if s.HasProjects != nil {
+		payload["has_projects"] = *s.HasProjects
+	}
+	if s.Homepage != nil {
+		payload["homepage"] = *s.Homepage
+	}
+	if s.Description != nil {
+		payload["description"] = *s.Description
+	}
+	return payload
+}
+
+// IsPrivate returns the configured visibility, defaulting to private
+func (r RepoConfig) IsPrivate() bool {
+	return r.Private == nil || *r.Private
+}
+
+// Source reports where the repository's contents come from: RepoSourceTemplate or
+// RepoSourceImport
+func (r RepoConfig) Source() string {
+	if r.Import != "" {
+		return RepoSourceImport
+	}
+	return RepoSourceTemplate
+}
+
+// SourceRef returns the template (owner/repo) or import URL the repository is created from
+func (r RepoConfig) SourceRef() string {
+	if r.Import != "" {
+		return r.Import
+	}
+	return r.Template
+}
+
+// RepoName returns the name the created repository will have
+func (r RepoConfig) RepoName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if r.Import != "" {
+		parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(r.Import, "/"), ".git"), "/")
+		return parts[len(parts)-1]
+	}
+	parts := strings.Split(r.Template, "/")
+	return parts[len(parts)-1]
 }
 
 // UnmarshalJSON allows RepoConfig to accept both string and object formats
@@ -21,23 +116,64 @@ func (r *RepoConfig) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
-	// If that fails, try as object
+	// If that fails, try as object, rejecting fields that aren't part of the schema
 	type Alias RepoConfig
 	aux := &struct {
 		*Alias
 	}{
 		Alias: (*Alias)(r),
 	}
-	if err := json.Unmarshal(data, &aux); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&aux); err != nil {
 		return err
 	}
 	return nil
 }
 
-type TemplateReposConfig struct {
-	LabEnvSetup struct {
-		Repos []RepoConfig `json:"repos"`
-	} `json:"lab-env-setup"`
+// Validate checks that the repository configuration is usable
+func (r RepoConfig) Validate() error {
+	if r.Import != "" {
+		if err := r.validateImport(); err != nil {
+			return err
+		}
+	} else {
+		parts := strings.Split(r.Template, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("template must be in 'owner/repo' format, got: %q", r.Template)
+		}
+	}
+	for _, topic := range r.Topics {
+		if topic == "" {
+			return fmt.Errorf("topics cannot contain empty values")
+		}
+	}
+	if r.Settings != nil && r.Settings.Description != nil && r.Description != "" {
+		return fmt.Errorf("description and settings.description are mutually exclusive")
+	}
+	if err := r.checkTemplateSyntax(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateImport checks an import entry: the source is a single http(s) clone URL, and
+// template-only settings aren't used
+func (r RepoConfig) validateImport() error {
+	if r.Template != "" {
+		return fmt.Errorf("template and import are mutually exclusive, got both %q and %q", r.Template, r.Import)
+	}
+	if r.IncludeAllBranches {
+		return fmt.Errorf("include_all_branches only applies to template repositories")
+	}
+	if strings.Contains(r.Import, "{{") {
+		return nil
+	}
+	u, err := url.Parse(r.Import)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return fmt.Errorf("import must be an http(s) git clone URL, got: %q", r.Import)
+	}
+	return nil
 }
 
 func LoadFromJsonFile(path string) ([]RepoConfig, error) {
@@ -46,11 +182,130 @@ func LoadFromJsonFile(path string) ([]RepoConfig, error) {
 		return nil, err
 	}
 
-	var config TemplateReposConfig
-	err = json.Unmarshal(data, &config)
-	if err != nil {
-		return nil, err
+	// Decode the entries separately so errors inside one can be positioned in the file
+	var config struct {
+		LabEnvSetup struct {
+			Repos []json.RawMessage `json:"repos"`
+		} `json:"lab-env-setup"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		err = describeJSONError(data, 0, data, err, []string{"lab-env-setup", "repos"})
+		return nil, fmt.Errorf("invalid template repos file %s: %w (run 'repo schema' to see accepted fields)", path, err)
 	}
 
-	return config.LabEnvSetup.Repos, nil
+	repoFields := jsonFieldNames(reflect.TypeOf(RepoConfig{}))
+	repos := make([]RepoConfig, len(config.LabEnvSetup.Repos))
+	searchFrom := 0
+	for i, raw := range config.LabEnvSetup.Repos {
+		// RawMessage holds the entry's bytes verbatim, so it can be found in the file
+		base := int64(-1)
+		if idx := bytes.Index(data[searchFrom:], raw); idx >= 0 {
+			base = int64(searchFrom + idx)
+			searchFrom += idx + len(raw)
+		}
+
+		if err := json.Unmarshal(raw, &repos[i]); err != nil {
+			if base >= 0 {
+				err = describeJSONError(data, base, raw, err, repoFields)
+			}
+			return nil, fmt.Errorf("invalid template repos file %s: repos[%d]: %w (run 'repo schema' to see accepted fields)", path, i, err)
+		}
+		if err := repos[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid template repos file %s: repos[%d]: %w", path, i, err)
+		}
+	}
+
+	return repos, nil
+}
+
+// RepoConfigJSONSchema is the JSON Schema describing the accepted template repos file format
+const RepoConfigJSONSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "ghas-lab-builder template repositories",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["lab-env-setup"],
+  "properties": {
+    "lab-env-setup": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["repos"],
+      "properties": {
+        "repos": {
+          "type": "array",
+          "items": {
+            "oneOf": [
+              {
+                "type": "string",
+                "description": "Template repository in 'owner/repo' format",
+                "pattern": "^[^/]+/[^/]+$"
+              },
+              {
+                "type": "object",
+                "additionalProperties": false,
+                "oneOf": [
+                  { "required": ["template"] },
+                  { "required": ["import"] }
+                ],
+                "properties": {
+                  "template": {
+                    "type": "string",
+                    "description": "Template repository in 'owner/repo' format. Supports {{.User}}, {{.Date}} and {{.Org}}",
+                    "pattern": "^[^/]+/[^/]+$"
+                  },
+                  "import": {
+                    "type": "string",
+                    "description": "Clone URL of a plain git repository to import instead of using a template. Supports {{.User}}, {{.Date}} and {{.Org}}",
+                    "pattern": "^https?://"
+                  },
+                  "include_all_branches": {
+                    "type": "boolean",
+                    "default": false,
+                    "description": "Copy all branches from the template instead of only the default branch (templates only)"
+                  },
+                  "default_branch": {
+                    "type": "string",
+                    "description": "Rename the created repository's default branch to this name"
+                  },
+                  "private": {
+                    "type": "boolean",
+                    "default": true,
+                    "description": "Create the repository as private"
+                  },
+                  "name": {
+                    "type": "string",
+                    "description": "Name of the created repository, defaults to the template's or imported repository's name. Supports {{.User}}, {{.Date}} and {{.Org}}"
+                  },
+                  "description": {
+                    "type": "string",
+                    "description": "Description of the created repository. Supports {{.User}}, {{.Date}} and {{.Org}}"
+                  },
+                  "topics": {
+                    "type": "array",
+                    "items": { "type": "string", "minLength": 1 },
+                    "description": "Topics to set on the created repository"
+                  },
+                  "settings": {
+                    "type": "object",
+                    "additionalProperties": false,
+                    "description": "Settings applied with one update after the repository is created",
+                    "properties": {
+                      "has_issues": { "type": "boolean", "description": "Enable or disable issues" },
+                      "has_wiki": { "type": "boolean", "description": "Enable or disable the wiki" },
+                      "has_projects": { "type": "boolean", "description": "Enable or disable projects" },
+                      "homepage": { "type": "string", "description": "Homepage URL shown on the repository. Supports {{.User}}, {{.Date}} and {{.Org}}" },
+                      "description": { "type": "string", "description": "Description set after creation, instead of the top-level description. Supports {{.User}}, {{.Date}} and {{.Org}}" }
+                    }
+                  }
+                }
+              }
+            ]
+          }
+        }
+      }
+    }
+  }
 }
+`
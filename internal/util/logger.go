@@ -1,6 +1,9 @@
 package util
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -9,17 +12,37 @@ import (
 	"time"
 )
 
+// Console log formats selectable with --log-format
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Console color modes selectable with --color
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
 // LoggerConfig holds configuration for logger initialization
 type LoggerConfig struct {
 	// LogFilePath is the path to the log file. If empty, logs to stdout only.
 	LogFilePath string
 	// LogLevel is the minimum log level to output
 	LogLevel slog.Level
+	// ConsoleFormat is the format of the console output, LogFormatJSON (default) or
+	// LogFormatText. The log file is always JSON.
+	ConsoleFormat string
+	// Color controls ANSI colors for text console output: ColorAuto (default), ColorAlways
+	// or ColorNever
+	Color string
 }
 
-// NewLogger creates a new structured logger that writes JSON logs to a file and stdout
+// NewLogger creates a new structured logger that writes JSON logs to a file and JSON or
+// text logs to stdout
 func NewLogger(config LoggerConfig) (*slog.Logger, io.Closer, error) {
-	var writer io.Writer
+	var file *os.File
 	var closer io.Closer
 
 	if config.LogFilePath != "" {
@@ -32,28 +55,133 @@ func NewLogger(config LoggerConfig) (*slog.Logger, io.Closer, error) {
 		}
 
 		// Open log file for writing (create if not exists, append if exists)
-		file, err := os.OpenFile(config.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		var err error
+		file, err = os.OpenFile(config.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
 		}
-
-		// Use both stdout and file for logging
-		writer = io.MultiWriter(os.Stdout, file)
 		closer = file
-	} else {
-		// Default to stdout only
-		writer = os.Stdout
-		closer = nil
 	}
 
-	// Create JSON handler with specified log level
 	opts := &slog.HandlerOptions{
 		Level: config.LogLevel,
 	}
-	handler := slog.NewJSONHandler(writer, opts)
-	logger := slog.New(handler)
 
-	return logger, closer, nil
+	if config.ConsoleFormat != LogFormatText {
+		// JSON on both outputs, so a single handler writes both
+		var writer io.Writer = os.Stdout
+		if file != nil {
+			writer = io.MultiWriter(os.Stdout, file)
+		}
+		return slog.New(slog.NewJSONHandler(writer, opts)), closer, nil
+	}
+
+	var console io.Writer = os.Stdout
+	if useColor(config.Color, os.Stdout) {
+		console = &levelColorWriter{w: os.Stdout}
+	}
+	var handler slog.Handler = slog.NewTextHandler(console, opts)
+	if file != nil {
+		handler = fanoutHandler{handler, slog.NewJSONHandler(file, opts)}
+	}
+	return slog.New(handler), closer, nil
+}
+
+// useColor decides whether console output gets ANSI colors. In auto mode colors are used
+// only when stdout is a terminal and NO_COLOR isn't set (https://no-color.org).
+func useColor(mode string, out *os.File) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := out.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// levelColors are the ANSI codes used for each level in text console output
+var levelColors = []struct {
+	level []byte
+	color string
+}{
+	{[]byte("level=DEBUG"), "\x1b[90m"},
+	{[]byte("level=INFO"), "\x1b[32m"},
+	{[]byte("level=WARN"), "\x1b[33m"},
+	{[]byte("level=ERROR"), "\x1b[31m"},
+}
+
+// levelColorWriter colors the level of each record written by a slog.TextHandler. The
+// handler quotes values containing escape characters, so the color is added after
+// formatting rather than through ReplaceAttr.
+type levelColorWriter struct {
+	w io.Writer
+}
+
+func (cw *levelColorWriter) Write(p []byte) (int, error) {
+	for _, lc := range levelColors {
+		i := bytes.Index(p, lc.level)
+		if i < 0 {
+			continue
+		}
+		end := i + len(lc.level)
+		// Skip a prefix match such as level=INFO+2
+		if end < len(p) && p[end] != ' ' && p[end] != '\n' {
+			continue
+		}
+		colored := make([]byte, 0, len(p)+len(lc.color)+4)
+		colored = append(colored, p[:i]...)
+		colored = append(colored, lc.color...)
+		colored = append(colored, p[i:end]...)
+		colored = append(colored, "\x1b[0m"...)
+		colored = append(colored, p[end:]...)
+		if _, err := cw.w.Write(colored); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return cw.w.Write(p)
+}
+
+// fanoutHandler sends every record to each of its handlers
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
 }
 
 // GenerateLogFileName generates a log file name with timestamp in the logs directory
@@ -0,0 +1,142 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// OrgLoginPrefix is prepended to every lab organization login
+const OrgLoginPrefix = "ghas-labs-"
+
+// MaxOrgLoginLength is the maximum length GitHub allows for an organization login
+const MaxOrgLoginLength = 39
+
+// MaxOrgProfileNameLength is the longest organization profile (display) name accepted
+const MaxOrgProfileNameLength = 255
+
+var orgLoginPattern = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// BuildOrgLogin returns the organization login for a user in a given lab
+func BuildOrgLogin(labDate string, user string) string {
+	return OrgLoginPrefix + labDate + "-" + user
+}
+
+// labRepoMarkerPrefix starts the marker added to the description of every lab repository
+const labRepoMarkerPrefix = "[ghas-lab"
+
+// LabRepoMarker returns the marker added to a lab repository's description, identifying
+// the lab date (cohort) it was created for: [ghas-lab:2025-11-07], or [ghas-lab] when the
+// repository wasn't created for a lab date
+func LabRepoMarker(labDate string) string {
+	if labDate == "" {
+		return labRepoMarkerPrefix + "]"
+	}
+	return labRepoMarkerPrefix + ":" + labDate + "]"
+}
+
+// HasLabRepoMarker reports whether a repository description carries the marker for
+// labDate, or any lab marker when labDate is empty
+func HasLabRepoMarker(description string, labDate string) bool {
+	if labDate != "" {
+		return strings.Contains(description, LabRepoMarker(labDate))
+	}
+	return strings.Contains(description, LabRepoMarker("")) || strings.Contains(description, labRepoMarkerPrefix+":")
+}
+
+// ValidateOrgLogin checks that login is a valid GitHub organization name and
+// returns a descriptive error if it is not
+func ValidateOrgLogin(login string) error {
+	if len(login) > MaxOrgLoginLength {
+		return fmt.Errorf("resulting org login invalid: %q is %d characters (max %d)", login, len(login), MaxOrgLoginLength)
+	}
+	if !orgLoginPattern.MatchString(login) {
+		return fmt.Errorf("resulting org login invalid: %q may only contain alphanumeric characters and hyphens", login)
+	}
+	if strings.HasPrefix(login, "-") || strings.HasSuffix(login, "-") {
+		return fmt.Errorf("resulting org login invalid: %q cannot begin or end with a hyphen", login)
+	}
+	if strings.Contains(login, "--") {
+		return fmt.Errorf("resulting org login invalid: %q cannot contain consecutive hyphens", login)
+	}
+	return nil
+}
+
+// OrgLoginsWithoutPrefix returns the logins that don't start with prefix. GitHub logins
+// are case-insensitive, so the comparison is too.
+func OrgLoginsWithoutPrefix(logins []string, prefix string) []string {
+	offending := []string{}
+	for _, login := range logins {
+		if !strings.HasPrefix(strings.ToLower(login), strings.ToLower(prefix)) {
+			offending = append(offending, login)
+		}
+	}
+	return offending
+}
+
+// ValidateOrgAllowlist checks that every --org-allowlist entry is a valid pattern
+func ValidateOrgAllowlist(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("empty pattern")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// OrgAllowed reports whether login matches any of the allowlist patterns. Patterns use
+// path.Match syntax (e.g. ghas-labs-2025-*), so an entry without wildcards names a single
+// organization. Logins are case-insensitive, so matching is too.
+func OrgAllowed(patterns []string, login string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(login)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// OrgProfileVars are the values available to --org-profile-template
+type OrgProfileVars struct {
+	User string
+	Date string
+	Org  string
+}
+
+// ValidateOrgProfileTemplate checks --org-profile-template's syntax and variables up front
+// rather than on the first organization
+func ValidateOrgProfileTemplate(tmpl string) error {
+	_, err := RenderOrgProfileName(tmpl, OrgProfileVars{User: "user", Date: "date", Org: "org"})
+	return err
+}
+
+// RenderOrgProfileName renders the profile name of an organization from
+// --org-profile-template. The result must be non-empty, a single line and at most
+// MaxOrgProfileNameLength characters.
+func RenderOrgProfileName(tmpl string, vars OrgProfileVars) (string, error) {
+	t, err := template.New("profile").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template syntax: %w", err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("failed to expand %q (available variables: {{.User}}, {{.Date}}, {{.Org}}): %w", tmpl, err)
+	}
+
+	name := strings.TrimSpace(sb.String())
+	switch {
+	case name == "":
+		return "", fmt.Errorf("profile name for %s is empty", vars.Org)
+	case strings.ContainsAny(name, "\r\n"):
+		return "", fmt.Errorf("profile name for %s spans multiple lines", vars.Org)
+	case utf8.RuneCountInString(name) > MaxOrgProfileNameLength:
+		return "", fmt.Errorf("profile name for %s is %d characters, more than the %d allowed", vars.Org, utf8.RuneCountInString(name), MaxOrgProfileNameLength)
+	}
+	return name, nil
+}
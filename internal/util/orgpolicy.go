@@ -0,0 +1,161 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OrgPolicy is the security baseline applied to every lab organization with --org-policy
+type OrgPolicy struct {
+	// IPAllowList adds entries to the organization's IP allow list and optionally enables it
+	IPAllowList *IPAllowListPolicy `json:"ip_allow_list,omitempty"`
+	// Settings are organization settings set with the update organization API, e.g.
+	// members_can_create_public_repositories
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	// Seats sets the organization's billing contact and which members get paid seats
+	Seats *SeatPolicy `json:"seats,omitempty"`
+}
+
+// SeatPolicy describes the billing and seat settings applied to every lab organization.
+// Every organization member consumes an enterprise license regardless, and the API has
+// no setting to change that, so only the settings below can be managed.
+type SeatPolicy struct {
+	// BillingEmail replaces the enterprise billing email the organization was created with
+	BillingEmail string `json:"billing_email,omitempty"`
+	// CopilotSeat assigns a Copilot Business seat to the organization's student. When
+	// false no seat is assigned and existing seats are left alone.
+	CopilotSeat bool `json:"copilot_seat,omitempty"`
+}
+
+// IPAllowListPolicy describes the IP allow list entries every lab organization gets
+type IPAllowListPolicy struct {
+	// Enabled turns the allow list on after the entries are added. When false the
+	// allow list's enabled setting is left as it is.
+	Enabled bool               `json:"enabled,omitempty"`
+	Entries []IPAllowListEntry `json:"entries"`
+}
+
+// IPAllowListEntry is a single IP address or CIDR range on the allow list
+type IPAllowListEntry struct {
+	Value string `json:"value"`
+	Name  string `json:"name,omitempty"`
+}
+
+// orgPolicySettings are the update organization API fields --org-policy accepts
+var orgPolicySettings = map[string]reflect.Kind{
+	"default_repository_permission":                                reflect.String,
+	"members_allowed_repository_creation_type":                     reflect.String,
+	"members_can_create_repositories":                              reflect.Bool,
+	"members_can_create_public_repositories":                       reflect.Bool,
+	"members_can_create_private_repositories":                      reflect.Bool,
+	"members_can_create_internal_repositories":                     reflect.Bool,
+	"members_can_create_pages":                                     reflect.Bool,
+	"members_can_create_public_pages":                              reflect.Bool,
+	"members_can_create_private_pages":                             reflect.Bool,
+	"members_can_fork_private_repositories":                        reflect.Bool,
+	"web_commit_signoff_required":                                  reflect.Bool,
+	"deploy_keys_enabled_for_repositories":                         reflect.Bool,
+	"advanced_security_enabled_for_new_repositories":               reflect.Bool,
+	"secret_scanning_enabled_for_new_repositories":                 reflect.Bool,
+	"secret_scanning_push_protection_enabled_for_new_repositories": reflect.Bool,
+	"dependency_graph_enabled_for_new_repositories":                reflect.Bool,
+	"dependabot_alerts_enabled_for_new_repositories":               reflect.Bool,
+	"dependabot_security_updates_enabled_for_new_repositories":     reflect.Bool,
+}
+
+// SettingNames returns the policy's setting names in a stable order
+func (p *OrgPolicy) SettingNames() []string {
+	names := make([]string, 0, len(p.Settings))
+	for name := range p.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that the policy only uses supported settings with values of the right type
+func (p *OrgPolicy) Validate() error {
+	if p.IPAllowList == nil && len(p.Settings) == 0 && p.Seats == nil {
+		return fmt.Errorf("policy must set ip_allow_list, settings or seats")
+	}
+	for _, name := range p.SettingNames() {
+		kind, ok := orgPolicySettings[name]
+		if !ok {
+			supported := make([]string, 0, len(orgPolicySettings))
+			for s := range orgPolicySettings {
+				supported = append(supported, s)
+			}
+			msg := fmt.Sprintf("unsupported setting %q", name)
+			if suggestion := closestField(name, supported); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			return fmt.Errorf("settings: %s", msg)
+		}
+		if value := p.Settings[name]; value == nil || reflect.TypeOf(value).Kind() != kind {
+			return fmt.Errorf("settings: %q must be a %s", name, kind)
+		}
+	}
+	if p.Seats != nil {
+		if p.Seats.BillingEmail == "" && !p.Seats.CopilotSeat {
+			return fmt.Errorf("seats: set billing_email or copilot_seat")
+		}
+		if p.Seats.BillingEmail != "" && !strings.Contains(p.Seats.BillingEmail, "@") {
+			return fmt.Errorf("seats: billing_email must be an email address, got %q", p.Seats.BillingEmail)
+		}
+	}
+	if p.IPAllowList != nil {
+		if len(p.IPAllowList.Entries) == 0 {
+			return fmt.Errorf("ip_allow_list: entries cannot be empty")
+		}
+		for i, entry := range p.IPAllowList.Entries {
+			if net.ParseIP(entry.Value) == nil {
+				if _, _, err := net.ParseCIDR(entry.Value); err != nil {
+					return fmt.Errorf("ip_allow_list: entries[%d]: value must be an IP address or CIDR range, got %q", i, entry.Value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// LoadOrgPolicy reads and validates an organization policy file
+func LoadOrgPolicy(path string) (*OrgPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy OrgPolicy
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		fields := append(jsonFieldNames(reflect.TypeOf(OrgPolicy{})), jsonFieldNames(reflect.TypeOf(IPAllowListPolicy{}))...)
+		fields = append(fields, jsonFieldNames(reflect.TypeOf(SeatPolicy{}))...)
+		err = describeJSONError(data, 0, data, err, fields)
+		return nil, fmt.Errorf("invalid org policy file %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid org policy file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// CheckOrgPolicyFile confirms the org policy file exists, parses and validates
+func CheckOrgPolicyFile(path string) error {
+	_, err := LoadOrgPolicy(path)
+	return err
+}
+
+// String describes the entry for logs and reports, e.g. "203.0.113.0/24 (Office)"
+func (e IPAllowListEntry) String() string {
+	if strings.TrimSpace(e.Name) == "" {
+		return e.Value
+	}
+	return fmt.Sprintf("%s (%s)", e.Value, e.Name)
+}
@@ -0,0 +1,23 @@
+package util
+
+import "fmt"
+
+// CheckUsersFile confirms the users file exists, parses, and lists at least one user.
+// It is used before authentication so path typos are reported immediately.
+func CheckUsersFile(path string) error {
+	users, err := LoadFromFile(path)
+	if err != nil {
+		return fmt.Errorf("users file %s: %w", path, err)
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("users file %s does not contain any usernames", path)
+	}
+	return nil
+}
+
+// CheckTemplateReposFile confirms the template repos file exists and parses. Parse errors
+// from LoadFromJsonFile already name the file.
+func CheckTemplateReposFile(path string) error {
+	_, err := LoadFromJsonFile(path)
+	return err
+}
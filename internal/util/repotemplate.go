@@ -0,0 +1,80 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// RepoTemplateVars are the values available to {{...}} expressions in a repo config
+type RepoTemplateVars struct {
+	User string
+	Date string
+	Org  string
+}
+
+// Expand returns a copy of the repo config with template expressions in Template, Import,
+// Name, Description and the homepage and description settings replaced using vars. Values without template syntax are left as is.
+func (r RepoConfig) Expand(vars RepoTemplateVars) (RepoConfig, error) {
+	expanded := r
+	var err error
+	if expanded.Template, err = expandRepoField("template", r.Template, vars); err != nil {
+		return r, err
+	}
+	if expanded.Import, err = expandRepoField("import", r.Import, vars); err != nil {
+		return r, err
+	}
+	if expanded.Name, err = expandRepoField("name", r.Name, vars); err != nil {
+		return r, err
+	}
+	if expanded.Description, err = expandRepoField("description", r.Description, vars); err != nil {
+		return r, err
+	}
+	if r.Settings != nil {
+		settings := *r.Settings
+		if settings.Homepage, err = expandRepoSetting("settings.homepage", r.Settings.Homepage, vars); err != nil {
+			return r, err
+		}
+		if settings.Description, err = expandRepoSetting("settings.description", r.Settings.Description, vars); err != nil {
+			return r, err
+		}
+		expanded.Settings = &settings
+	}
+	return expanded, nil
+}
+
+// checkTemplateSyntax expands the config with placeholder values so bad syntax or unknown
+// variables are reported when the file is loaded rather than mid-provisioning
+func (r RepoConfig) checkTemplateSyntax() error {
+	_, err := r.Expand(RepoTemplateVars{User: "user", Date: "date", Org: "org"})
+	return err
+}
+
+// expandRepoSetting expands an optional setting, leaving unset settings unset
+func expandRepoSetting(field string, value *string, vars RepoTemplateVars) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	expanded, err := expandRepoField(field, *value, vars)
+	if err != nil {
+		return nil, err
+	}
+	return &expanded, nil
+}
+
+func expandRepoField(field string, value string, vars RepoTemplateVars) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid template syntax in %s %q: %w", field, value, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("failed to expand %s %q (available variables: {{.User}}, {{.Date}}, {{.Org}}): %w", field, value, err)
+	}
+	return sb.String(), nil
+}
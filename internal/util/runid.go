@@ -0,0 +1,42 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// MaxRunIDLength bounds --run-id so the header stays readable in audit logs
+const MaxRunIDLength = 128
+
+var (
+	runIDPattern      = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+	headerNamePattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+// NewRunID returns a random run ID for correlating a run's requests
+func NewRunID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// ValidateRunID checks that a run ID can be sent as a header value as is
+func ValidateRunID(runID string) error {
+	if len(runID) > MaxRunIDLength {
+		return fmt.Errorf("%q is %d characters (max %d)", runID, len(runID), MaxRunIDLength)
+	}
+	if !runIDPattern.MatchString(runID) {
+		return fmt.Errorf("%q may only contain letters, digits, '.', '_', ':' and '-'", runID)
+	}
+	return nil
+}
+
+// ValidateHeaderName checks that name is a plain HTTP header name such as X-Request-Id
+func ValidateHeaderName(name string) error {
+	if !headerNamePattern.MatchString(name) {
+		return fmt.Errorf("%q is not a valid header name (letters, digits and '-' only)", name)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package util
+
+// SampleUsersFile is the example users file written by the init command. Users are
+// separated by commas; whitespace and newlines around each name are ignored.
+const SampleUsersFile = `student1,
+student2,
+student3,
+student4
+`
+
+// SampleTemplateReposFile is the example template repos file written by the init command.
+// It uses every field accepted by RepoConfig.
+const SampleTemplateReposFile = `{
+  "lab-env-setup": {
+    "repos": [
+      "org-name/plain-template",
+      {
+        "template": "org-name/repo-name",
+        "include_all_branches": false
+      },
+      {
+        "template": "org-name/another-repo",
+        "include_all_branches": true,
+        "default_branch": "main",
+        "private": true,
+        "topics": ["ghas-lab", "code-scanning"],
+        "name": "{{.User}}-submission",
+        "description": "Lab submission for {{.User}} ({{.Date}})"
+      },
+      {
+        "import": "https://github.com/org-name/plain-repo.git",
+        "name": "{{.User}}-plain-repo"
+      }
+    ]
+  }
+}
+`
+
+// SampleFilesGuide explains the sample files, since neither format allows comments
+const SampleFilesGuide = `Users file (%s):
+  GitHub usernames separated by commas. Whitespace and newlines around each name are
+  ignored, so one name per line works as long as each line ends with a comma.
+
+Template repositories file (%s):
+  Each entry in "repos" is either a plain "owner/repo" string or an object with:
+    template              owner/repo of the template repository
+    import                clone URL of a plain git repository to import instead of a template
+    include_all_branches  copy every branch (true) or only the default branch (false)
+    default_branch        rename the created repository's default branch
+    private               create the repository as private (default) or public
+    topics                topics set on the created repository
+    name                  name of the created repository (defaults to the template's name)
+    description           description of the created repository
+    settings              has_issues, has_wiki, has_projects, homepage and description,
+                          applied with one update after the repository is created
+  Each object needs exactly one of template or import; include_all_branches only applies
+  to templates. template, import, name and description may use {{.User}}, {{.Date}} and {{.Org}}.
+  Unknown fields are rejected; run 'repo schema' for the full JSON schema.
+`
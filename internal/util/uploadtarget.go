@@ -0,0 +1,70 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Object storage schemes accepted by --report-upload
+const (
+	UploadSchemeS3    = "s3"
+	UploadSchemeGCS   = "gs"
+	UploadSchemeAzure = "az"
+)
+
+// UploadTarget is an object storage location reports are uploaded to, given as
+// scheme://bucket/prefix. For Azure the bucket is account/container.
+type UploadTarget struct {
+	Scheme string
+	Bucket string
+	Prefix string
+}
+
+func (t UploadTarget) String() string {
+	if t.Prefix == "" {
+		return fmt.Sprintf("%s://%s", t.Scheme, t.Bucket)
+	}
+	return fmt.Sprintf("%s://%s/%s", t.Scheme, t.Bucket, t.Prefix)
+}
+
+// ObjectName returns the name of a file uploaded under the target's prefix
+func (t UploadTarget) ObjectName(fileName string) string {
+	if t.Prefix == "" {
+		return fileName
+	}
+	return path.Join(t.Prefix, fileName)
+}
+
+// ParseUploadTarget parses an "s3://bucket/prefix", "gs://bucket/prefix" or
+// "az://account/container/prefix" location. The prefix is optional.
+func ParseUploadTarget(value string) (UploadTarget, error) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok {
+		return UploadTarget{}, fmt.Errorf("invalid upload location %q: expected s3://bucket/prefix, gs://bucket/prefix or az://account/container/prefix", value)
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+
+	bucketParts := 1
+	switch scheme {
+	case UploadSchemeS3, UploadSchemeGCS:
+	case UploadSchemeAzure:
+		bucketParts = 2
+	default:
+		return UploadTarget{}, fmt.Errorf("invalid upload location %q: scheme must be s3, gs or az", value)
+	}
+	if len(parts) < bucketParts {
+		return UploadTarget{}, fmt.Errorf("invalid upload location %q: missing bucket or container", value)
+	}
+	for _, part := range parts[:bucketParts] {
+		if part == "" {
+			return UploadTarget{}, fmt.Errorf("invalid upload location %q: missing bucket or container", value)
+		}
+	}
+
+	return UploadTarget{
+		Scheme: scheme,
+		Bucket: strings.Join(parts[:bucketParts], "/"),
+		Prefix: strings.Join(parts[bucketParts:], "/"),
+	}, nil
+}
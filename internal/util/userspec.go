@@ -37,3 +37,14 @@ func LoadFromFile(path string) ([]string, error) {
 		return nil, fmt.Errorf("unsupported file extension: %s", ext)
 	}
 }
+
+// SplitCommaList splits a comma-separated flag value, trimming whitespace and dropping empty entries
+func SplitCommaList(value string) []string {
+	entries := make([]string, 0)
+	for _, e := range strings.Split(value, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}